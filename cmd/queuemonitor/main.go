@@ -8,16 +8,30 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // the final image has no time zone database
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
 	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
 	"github.com/UladzK/duw-queue-monitor/internal/queuemonitor"
+	"github.com/UladzK/duw-queue-monitor/internal/queuestatus"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/redis/go-redis/v9"
 )
 
 func main() {
-	if err := run(); err != nil {
+	var err error
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "outbox":
+		err = runOutboxCommand(os.Args[2:])
+	case len(os.Args) > 1 && os.Args[1] == "webhook":
+		err = runWebhookCommand(os.Args[2:])
+	default:
+		err = run()
+	}
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
@@ -32,7 +46,7 @@ func run() error {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
-	runner, err := buildRunner(log)
+	runner, workers, closeNotifier, err := buildRunner(log)
 	if err != nil {
 		return fmt.Errorf("failed to initialize runner: %w", err)
 	}
@@ -42,11 +56,20 @@ func run() error {
 	done := make(chan bool, 1)
 	go runner.Run(ctx, done)
 
+	workersDone := make(chan bool, len(workers))
+	for _, w := range workers {
+		go w.Run(ctx, workersDone)
+	}
+
 	log.Info("Queue monitor started. Waiting for shutdown signal...")
 	<-ctx.Done()
 	log.Info("Received shutdown signal, waiting for status collector to stop...")
 	cancel()
 	<-done
+	for range workers {
+		<-workersDone
+	}
+	closeNotifier()
 
 	log.Info("Queue monitor stopped")
 
@@ -62,11 +85,24 @@ func buildLogger() (*logger.Logger, error) {
 	return logger.NewLogger(&cfg), nil
 }
 
-func buildRunner(log *logger.Logger) (*queuemonitor.Runner, error) {
+// worker runs in the background next to the monitor loop until the context is cancelled.
+type worker interface {
+	Run(ctx context.Context, done chan<- bool)
+}
+
+// buildRunner returns the runner and the enabled background workers, e.g. the worker which delivers notifications saved by the monitor.
+func buildRunner(log *logger.Logger) (*queuemonitor.Runner, []worker, func(), error) {
 	var cfg queuemonitor.Config
 	if err := env.Parse(&cfg); err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+	logger.RegisterSecrets(&cfg)
+
+	messageTemplates, err := templates.Load(&cfg.Templates, log)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load message templates: %w", err)
 	}
+	templates.Default = messageTemplates
 
 	httpClient := &http.Client{
 		Timeout: time.Duration(cfg.QueueMonitor.HttpClientTimeoutSeconds) * time.Second,
@@ -74,20 +110,211 @@ func buildRunner(log *logger.Logger) (*queuemonitor.Runner, error) {
 
 	opt, err := redis.ParseURL(cfg.QueueMonitor.RedisConString)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	redisClient := redis.NewClient(opt)
 
 	stateRepo := queuemonitor.NewMonitorStateRepository(redisClient, cfg.QueueMonitor.StateTtlSeconds)
 	collector := queuemonitor.NewStatusCollector(&cfg.QueueMonitor, httpClient, log)
-	notifier := buildNotifier(&cfg, log, httpClient)
-	monitor := queuemonitor.NewQueueMonitor(&cfg, log, collector, notifier)
+	telegram, sentMessages, err := buildTelegramNotifier(&cfg, log, httpClient, redisClient)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	channel, err := buildChannelNotifier(&cfg, log, redisClient, telegram, sentMessages)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var workers []worker
+	// the direct messages to the bot users are sent in the background, sharing the rate limits with the channel messages
+	var directMessages *notifications.DirectMessageQueue
+	if (cfg.NotificationSubscriptions.Enabled && !cfg.NotificationOutbox.Enabled) || cfg.NotificationTicketTracker.Enabled {
+		directMessages = notifications.NewDirectMessageQueue(log)
+		workers = append(workers, directMessages)
+	}
+
+	notifier, targets, closeNotifier, err := buildNotifier(&cfg, log, httpClient, redisClient, telegram, channel, directMessages)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var monitor *queuemonitor.DefaultQueueMonitor
+	if cfg.NotificationOutbox.Enabled {
+		outbox := notifications.NewRedisOutbox(redisClient)
+		monitor = queuemonitor.NewOutboxQueueMonitor(&cfg, log, collector, queuemonitor.NewOutboxTransitionStore(stateRepo, outbox))
+		// the worker tracks the delivery to each target, which also makes the idempotency keys unnecessary
+		workers = append(workers, notifications.NewOutboxWorker(&cfg.NotificationOutbox, log, outbox, targets...))
+	} else {
+		monitor = queuemonitor.NewQueueMonitor(&cfg, log, collector, notifier)
+	}
+	monitor.PublishStatus(queuestatus.NewRedisStore(redisClient))
+	history := queuehistory.NewRedisStore(&cfg.History, redisClient, templates.Default.Location())
+	monitor.RecordHistory(history)
+	if cfg.NotificationDailySummary.Enabled {
+		chatID := fmt.Sprintf("@%s", cfg.BroadcastChannelName)
+		store := notifications.NewRedisDailySummaryStore(redisClient)
+		workers = append(workers, notifications.NewDailySummaryReporter(&cfg.NotificationDailySummary, log, channel, history, store, chatID, cfg.QueueMonitor.StatusMonitoredQueueId))
+	}
+	if cfg.NotificationTicketTracker.Enabled {
+		store := notifications.NewRedisTrackedTicketStore(redisClient)
+		monitor.ObserveTickets(notifications.NewTicketTracker(&cfg.NotificationTicketTracker, log, telegram.WithoutRecording(), store, directMessages))
+	}
 	weekdayMonitor := queuemonitor.NewWeekdayQueueMonitor(monitor, queuemonitor.NewSystemDateTimeProvider(), log)
 
 	runner := queuemonitor.NewRunner(&cfg, log, weekdayMonitor, stateRepo)
-	return runner, nil
+	return runner, workers, closeNotifier, nil
 }
 
-func buildNotifier(cfg *queuemonitor.Config, log *logger.Logger, httpClient *http.Client) queuemonitor.Notifier {
-	return notifications.NewTelegramNotifier(&cfg.NotificationTelegram, log, httpClient)
+// buildTelegramNotifier returns the notifier of the broadcast channel and the store of its sent messages, if they are cleaned up.
+func buildTelegramNotifier(cfg *queuemonitor.Config, log *logger.Logger, httpClient *http.Client, redisClient *redis.Client) (*notifications.TelegramNotifier, notifications.SentMessageStore, error) {
+	var telegram *notifications.TelegramNotifier
+	var sentMessages notifications.SentMessageStore
+	if cfg.NotificationCleanup.Enabled {
+		sentMessages = notifications.NewRedisSentMessageStore(redisClient, cfg.NotificationCleanup.TtlSeconds)
+		telegram = notifications.NewRecordingTelegramNotifier(&cfg.NotificationTelegram, log, httpClient, sentMessages)
+	} else {
+		telegram = notifications.NewTelegramNotifier(&cfg.NotificationTelegram, log, httpClient)
+	}
+	if len(cfg.NotificationTelegram.ForumTopics) > 0 {
+		chatID := fmt.Sprintf("@%s", cfg.BroadcastChannelName)
+		// best-effort: the queues of the topics which can't be resolved are notified outside of the topics
+		if err := telegram.ResolveForumTopics(context.Background(), chatID, notifications.NewRedisForumTopicStore(redisClient)); err != nil {
+			log.Error("Failed to initialize some forum topics, their notifications are sent to the chat", err)
+		}
+	}
+	return telegram, sentMessages, nil
+}
+
+// channelNotifier sends the queue events and the other posts, like the daily summary, to the broadcast channel.
+type channelNotifier interface {
+	notifications.Notifier
+	notifications.EventNotifier
+	notifications.PostNotifier
+}
+
+// buildChannelNotifier returns the Telegram notifier wrapped by the enabled features of the channel, e.g. the live message
+// and the language channels.
+func buildChannelNotifier(cfg *queuemonitor.Config, log *logger.Logger, redisClient *redis.Client, telegram *notifications.TelegramNotifier, sentMessages notifications.SentMessageStore) (channelNotifier, error) {
+	var channel channelNotifier = telegram
+	if cfg.NotificationLiveMessage.Enabled {
+		store := notifications.NewRedisLiveMessageStore(redisClient, cfg.NotificationLiveMessage.TtlSeconds)
+		channel = notifications.NewLiveMessageNotifier(&cfg.NotificationLiveMessage, log, telegram, store)
+	}
+	if cfg.NotificationCleanup.Enabled {
+		cleanupNotifier, err := notifications.NewChannelCleanupNotifier(&cfg.NotificationCleanup, log, telegram, sentMessages, channel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize channel cleanup notifier: %w", err)
+		}
+		channel = cleanupNotifier
+	}
+	if len(cfg.NotificationTelegram.LanguageChannels) > 0 {
+		languageNotifier, err := notifications.NewLanguageChannelsNotifier(log, cfg.NotificationTelegram.LanguageChannels, channel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize language channels notifier: %w", err)
+		}
+		channel = languageNotifier
+	}
+	return channel, nil
+}
+
+// buildNotifier returns the notifier, the target notifiers it fans out to, and a function which must be called on shutdown
+// to flush pending notifications.
+func buildNotifier(cfg *queuemonitor.Config, log *logger.Logger, httpClient *http.Client, redisClient *redis.Client, telegram *notifications.TelegramNotifier, channel channelNotifier, directMessages *notifications.DirectMessageQueue) (queuemonitor.Notifier, []notifications.Notifier, func(), error) {
+	notifiers := []notifications.Notifier{channel}
+	var closers []func()
+	if cfg.NotificationSlack.Enabled {
+		notifiers = append(notifiers, notifications.NewSlackNotifier(&cfg.NotificationSlack, log, httpClient))
+	}
+	if cfg.NotificationWebhook.Enabled {
+		notifiers = append(notifiers, notifications.NewWebhookNotifier(&cfg.NotificationWebhook, log, httpClient, notifications.NewRedisDeliveryLog(redisClient, cfg.NotificationWebhook.DeliveryLogSize)))
+	}
+	if cfg.NotificationNtfy.Enabled {
+		notifiers = append(notifiers, notifications.NewNtfyNotifier(&cfg.NotificationNtfy, log, httpClient))
+	}
+	if cfg.NotificationGotify.Enabled {
+		notifiers = append(notifiers, notifications.NewGotifyNotifier(&cfg.NotificationGotify, log, httpClient))
+	}
+	if cfg.NotificationEmail.Enabled {
+		emailNotifier := notifications.NewEmailNotifier(&cfg.NotificationEmail, log)
+		notifiers = append(notifiers, emailNotifier)
+		closers = append(closers, func() {
+			if err := emailNotifier.Close(context.Background()); err != nil {
+				log.Error("Failed to send pending email digest", err)
+			}
+		})
+	}
+	if cfg.NotificationMqtt.Enabled {
+		mqttPublisher := notifications.NewMqttPublisher(&cfg.NotificationMqtt, log)
+		mqttPublisher.Connect()
+		notifiers = append(notifiers, mqttPublisher)
+		closers = append(closers, mqttPublisher.Close)
+	}
+	if cfg.NotificationSubscriptions.Enabled {
+		// not recording: the messages of the subscribers must not be cleaned up with the channel
+		store := notifications.NewRedisTelegramSubscriptionStore(log, redisClient)
+		// the outbox worker already sends in the background, and it must see the failures to retry them
+		queue := directMessages
+		if cfg.NotificationOutbox.Enabled {
+			queue = nil
+		}
+		notifiers = append(notifiers, notifications.NewTelegramSubscribersNotifier(log, telegram.WithoutRecording(), store, queue))
+	}
+	if cfg.NotificationWebPush.Enabled {
+		webPushNotifier, closeServer, err := buildWebPushNotifier(&cfg.NotificationWebPush, log, httpClient, redisClient)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to initialize web push notifier: %w", err)
+		}
+		notifiers = append(notifiers, webPushNotifier)
+		closers = append(closers, closeServer)
+	}
+
+	closeNotifier := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	var notifier queuemonitor.Notifier = channel
+	if len(notifiers) > 1 {
+		notifier = notifications.NewMultiNotifier(log, notifiers...)
+	}
+	if cfg.NotificationIdempotency.Enabled {
+		notifier = notifications.NewIdempotentNotifier(&cfg.NotificationIdempotency, log, notifications.NewRedisIdempotencyStore(redisClient), notifier)
+	}
+	return notifier, notifiers, closeNotifier, nil
+}
+
+// buildWebPushNotifier returns the web push notifier and starts the subscription endpoints if a listen address is configured.
+func buildWebPushNotifier(cfg *notifications.WebPushConfig, log *logger.Logger, httpClient *http.Client, redisClient *redis.Client) (*notifications.WebPushNotifier, func(), error) {
+	keys, err := notifications.LoadOrCreateVapidKeys(context.Background(), cfg, redisClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.RegisterSecret(keys.PrivateKey()) // the key may come from Redis rather than from the config
+
+	store := notifications.NewRedisWebPushSubscriptionStore(redisClient)
+	notifier := notifications.NewWebPushNotifier(cfg, log, httpClient, store, keys)
+
+	if cfg.ListenAddr == "" {
+		return notifier, func() {}, nil
+	}
+
+	server := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           notifications.NewWebPushSubscriptionHandler(cfg, log, store, keys).Routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		log.Info("Starting web push subscription server", "addr", cfg.ListenAddr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("Web push subscription server failed", err)
+		}
+	}()
+
+	closeServer := func() {
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Error("Failed to stop web push subscription server", err)
+		}
+	}
+	return notifier, closeServer, nil
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/redis/go-redis/v9"
+)
+
+const outboxUsage = `usage:
+  queuemonitor outbox dead-letters    list notifications which could not be delivered
+  queuemonitor outbox replay <id>     move a dead notification back to the delivery queue
+  queuemonitor outbox replay --all    move all dead notifications back to the delivery queue`
+
+type redisCommandConfig struct {
+	RedisConString string `env:"STATE_REDIS_CONNECTION_STRING,required"`
+}
+
+// runOutboxCommand inspects and replays dead letters of the notification outbox. It is meant to be run in the monitor container.
+func runOutboxCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(outboxUsage)
+	}
+
+	var cfg redisCommandConfig
+	if err := env.Parse(&cfg); err != nil {
+		return err
+	}
+
+	opt, err := redis.ParseURL(cfg.RedisConString)
+	if err != nil {
+		return err
+	}
+	redisClient := redis.NewClient(opt)
+	defer redisClient.Close()
+
+	outbox := notifications.NewRedisOutbox(redisClient)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch {
+	case args[0] == "dead-letters":
+		return printDeadLetters(ctx, outbox)
+	case args[0] == "replay" && len(args) == 2 && args[1] == "--all":
+		replayed, err := outbox.ReplayAll(ctx)
+		fmt.Printf("Replayed %d notifications\n", replayed)
+		return err
+	case args[0] == "replay" && len(args) == 2:
+		if err := outbox.Replay(ctx, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Replayed notification %s\n", args[1])
+		return nil
+	default:
+		return errors.New(outboxUsage)
+	}
+}
+
+func printDeadLetters(ctx context.Context, outbox *notifications.RedisOutbox) error {
+	msgs, err := outbox.DeadLetters(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tENQUEUED AT\tCHAT\tATTEMPTS\tLAST ERROR")
+	for _, msg := range msgs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", msg.ID, msg.EnqueuedAt.Format(time.RFC3339), msg.ChatID, msg.Attempts, msg.LastError)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/redis/go-redis/v9"
+)
+
+const webhookUsage = `usage:
+  queuemonitor webhook deliveries    list the latest webhook delivery attempts`
+
+// runWebhookCommand inspects the webhook delivery log. It is meant to be run in the monitor container.
+func runWebhookCommand(args []string) error {
+	if len(args) != 1 || args[0] != "deliveries" {
+		return errors.New(webhookUsage)
+	}
+
+	var cfg redisCommandConfig
+	if err := env.Parse(&cfg); err != nil {
+		return err
+	}
+
+	opt, err := redis.ParseURL(cfg.RedisConString)
+	if err != nil {
+		return err
+	}
+	redisClient := redis.NewClient(opt)
+	defer redisClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// the size only matters when adding attempts
+	attempts, err := notifications.NewRedisDeliveryLog(redisClient, 1).Entries(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DELIVERY ID\tATTEMPT AT\tURL\tATTEMPT\tSTATUS\tDURATION\tERROR")
+	for _, a := range attempts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n", a.DeliveryID, a.AttemptAt.Format(time.RFC3339), a.Url, a.Attempt, a.StatusCode, a.Duration.Round(time.Millisecond), a.Error)
+	}
+	return w.Flush()
+}
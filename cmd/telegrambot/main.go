@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // the final image has no time zone database
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
 	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/queuestatus"
 	"github.com/UladzK/duw-queue-monitor/internal/telegrambot"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/go-telegram/bot"
+	"github.com/redis/go-redis/v9"
 )
 
 var log *logger.Logger
@@ -62,12 +69,31 @@ func buildBotWithHandlers() (*bot.Bot, *telegrambot.HandlerRegistry, error) {
 	if err := env.Parse(&cfg); err != nil {
 		return nil, nil, err
 	}
+	logger.RegisterSecrets(&cfg)
+	if err := i18n.Default.SetFallback(cfg.FallbackLanguage); err != nil {
+		return nil, nil, err
+	}
+	messageTemplates, err := templates.Load(&cfg.Templates, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load message templates: %w", err)
+	}
+	templates.Default = messageTemplates
 
 	telegramNotifier := notifications.NewTelegramNotifier(&cfg.NotificationTelegram, log, &http.Client{})
-	handlerRegistry := telegrambot.NewHandlerRegistry(log, telegramNotifier, cfg.FeedbackChatID)
+	opt, err := redis.ParseURL(cfg.RedisConString)
+	if err != nil {
+		return nil, nil, err
+	}
+	redisClient := redis.NewClient(opt)
+	statusStore := queuestatus.NewRedisStore(redisClient)
+	subscriptionStore := notifications.NewRedisTelegramSubscriptionStore(log, redisClient)
+	ticketStore := notifications.NewRedisTrackedTicketStore(redisClient)
+	history := queuehistory.NewRedisStore(&cfg.History, redisClient, templates.Default.Location())
+	handlerRegistry := telegrambot.NewHandlerRegistry(log, telegramNotifier, cfg.FeedbackChatID, statusStore, subscriptionStore, ticketStore, history, cfg.MonitoredQueueID)
 
 	opts := []bot.Option{
 		bot.WithDefaultHandler(handlerRegistry.GetDefaultHandler()),
+		bot.WithErrorsHandler(telegrambot.NewErrorsHandler(log)),
 	}
 
 	bot, err := bot.New(cfg.NotificationTelegram.BotToken, opts...)
@@ -0,0 +1,225 @@
+// Package charts draws PNG charts of the queue, e.g. how the tickets left changed during a day, with the standard library only.
+package charts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+const (
+	width        = 800
+	height       = 400
+	marginLeft   = 60
+	marginRight  = 30
+	marginTop    = 40
+	marginBottom = 40
+
+	maxTimeTicks  = 8
+	maxValueTicks = 5
+	tickLabelGap  = 8
+)
+
+var (
+	ErrNoData = errors.New("no series has points to draw")
+
+	backgroundColor = color.RGBA{255, 255, 255, 255}
+	gridColor       = color.RGBA{230, 230, 230, 255}
+	axisColor       = color.RGBA{120, 120, 120, 255}
+	labelColor      = color.RGBA{60, 60, 60, 255}
+	mainColor       = color.RGBA{33, 110, 220, 255}
+	// the compared series fade with their age
+	comparedColors = []color.RGBA{{150, 150, 150, 255}, {180, 180, 180, 255}, {205, 205, 205, 255}}
+)
+
+// Point is a value of a series at a time of the day, so that different days can be drawn on the same axis.
+type Point struct {
+	At    time.Duration // since midnight
+	Value int
+}
+
+// Series is a curve of the chart. The value of a point holds until the next point, so the curve is drawn as steps.
+type Series struct {
+	Label  string  // shown in the legend, only digits and ".:-" can be drawn, e.g. the date "02.03"
+	Points []Point // oldest first
+}
+
+// RenderPNG draws the series on a chart with the time of the day on the horizontal axis, and encodes it as PNG.
+// The first series is highlighted, the others are drawn in fading greys behind it, e.g. the same weekday of the
+// previous weeks. Returns ErrNoData if no series has points.
+func RenderPNG(series []Series) ([]byte, error) {
+	img, err := render(series)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func render(series []Series) (*image.RGBA, error) {
+	axes, ok := newAxes(series)
+	if !ok {
+		return nil, ErrNoData
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	axes.draw(img)
+
+	// the highlighted series is drawn last, so it's on top
+	for i := len(series) - 1; i >= 0; i-- {
+		drawSteps(img, axes, series[i].Points, seriesColor(i), seriesThickness(i))
+	}
+	drawLegend(img, series)
+	return img, nil
+}
+
+func seriesColor(i int) color.RGBA {
+	if i == 0 {
+		return mainColor
+	}
+	return comparedColors[min(i-1, len(comparedColors)-1)]
+}
+
+func seriesThickness(i int) int {
+	if i == 0 {
+		return 3
+	}
+	return 2
+}
+
+// axes maps the times and values to the pixels of the plot area.
+type axes struct {
+	plot      image.Rectangle
+	from, to  time.Duration // whole hours
+	timeStep  time.Duration
+	maxValue  int
+	valueStep int
+}
+
+func newAxes(series []Series) (*axes, bool) {
+	var from, to time.Duration
+	maxValue, found := 0, false
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !found || p.At < from {
+				from = p.At
+			}
+			if !found || p.At > to {
+				to = p.At
+			}
+			maxValue = max(maxValue, p.Value)
+			found = true
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	from = from.Truncate(time.Hour)
+	to = (to + time.Hour - 1).Truncate(time.Hour)
+	if to == from {
+		to += time.Hour
+	}
+	hours := int((to - from) / time.Hour)
+	timeStep := time.Duration((hours+maxTimeTicks-1)/maxTimeTicks) * time.Hour
+
+	valueStep := niceStep(maxValue / maxValueTicks)
+	maxValue = max((maxValue+valueStep-1)/valueStep*valueStep, valueStep)
+
+	return &axes{
+		plot:      image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom),
+		from:      from,
+		to:        to,
+		timeStep:  timeStep,
+		maxValue:  maxValue,
+		valueStep: valueStep,
+	}, true
+}
+
+// niceStep returns the smallest of 1, 2 or 5 times a power of ten which is at least n.
+func niceStep(n int) int {
+	for magnitude := 1; ; magnitude *= 10 {
+		for _, step := range []int{1, 2, 5} {
+			if step*magnitude >= n {
+				return step * magnitude
+			}
+		}
+	}
+}
+
+func (a *axes) x(at time.Duration) int {
+	return a.plot.Min.X + int(int64(a.plot.Dx())*int64(at-a.from)/int64(a.to-a.from))
+}
+
+func (a *axes) y(value int) int {
+	return a.plot.Max.Y - a.plot.Dy()*value/a.maxValue
+}
+
+func (a *axes) draw(img *image.RGBA) {
+	for value := 0; value <= a.maxValue; value += a.valueStep {
+		y := a.y(value)
+		fillRect(img, image.Rect(a.plot.Min.X, y, a.plot.Max.X, y+1), gridColor)
+		label := fmt.Sprint(value)
+		drawText(img, a.plot.Min.X-tickLabelGap-textWidth(label), y-glyphHeight*glyphScale/2, label, labelColor)
+	}
+	for at := a.from; at <= a.to; at += a.timeStep {
+		x := a.x(at)
+		fillRect(img, image.Rect(x, a.plot.Min.Y, x+1, a.plot.Max.Y), gridColor)
+		label := fmt.Sprintf("%02d:%02d", int(at.Hours()), int(at.Minutes())%60)
+		drawText(img, x-textWidth(label)/2, a.plot.Max.Y+tickLabelGap, label, labelColor)
+	}
+
+	fillRect(img, image.Rect(a.plot.Min.X, a.plot.Min.Y, a.plot.Min.X+1, a.plot.Max.Y+1), axisColor)
+	fillRect(img, image.Rect(a.plot.Min.X, a.plot.Max.Y, a.plot.Max.X+1, a.plot.Max.Y+1), axisColor)
+}
+
+// drawSteps draws the points as a step line: the value of a point holds until the next one, and the last value until
+// the end of the time axis.
+func drawSteps(img *image.RGBA, a *axes, points []Point, c color.RGBA, thickness int) {
+	for i := 1; i < len(points); i++ {
+		x1, y1 := a.x(points[i-1].At), a.y(points[i-1].Value)
+		x2, y2 := a.x(points[i].At), a.y(points[i].Value)
+		drawLine(img, x1, y1, x2, y1, c, thickness)
+		drawLine(img, x2, y1, x2, y2, c, thickness)
+	}
+	if len(points) > 0 {
+		last := points[len(points)-1]
+		drawLine(img, a.x(last.At), a.y(last.Value), a.x(a.to), a.y(last.Value), c, thickness)
+	}
+}
+
+// drawLine draws a horizontal or vertical line.
+func drawLine(img *image.RGBA, x1, y1, x2, y2 int, c color.RGBA, thickness int) {
+	offset := thickness / 2
+	r := image.Rect(x1, y1, x2, y2).Canon()
+	fillRect(img, image.Rect(r.Min.X-offset, r.Min.Y-offset, r.Max.X-offset+thickness, r.Max.Y-offset+thickness), c)
+}
+
+func drawLegend(img *image.RGBA, series []Series) {
+	const swatchWidth, swatchGap, entryGap = 16, 6, 20
+	x, y := marginLeft, (marginTop-glyphHeight*glyphScale)/2
+	for i, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		middle := y + glyphHeight*glyphScale/2
+		fillRect(img, image.Rect(x, middle-seriesThickness(i)/2, x+swatchWidth, middle-seriesThickness(i)/2+seriesThickness(i)), seriesColor(i))
+		x += swatchWidth + swatchGap
+		drawText(img, x, y, s.Label, labelColor)
+		x += textWidth(s.Label) + entryGap
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
@@ -0,0 +1,144 @@
+package charts
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func at(hour, minute int) time.Duration {
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+}
+
+func countPixels(img image.Image, c color.RGBA) int {
+	count := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == c {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestRenderPNG_WhenSeriesHavePoints_DrawsHighlightedAndComparedCurves(t *testing.T) {
+	// Arrange
+	series := []Series{
+		{Label: "09.03", Points: []Point{{at(8, 10), 120}, {at(8, 40), 30}, {at(9, 10), 0}, {at(15, 0), 0}}},
+		{Label: "02.03", Points: []Point{{at(8, 30), 100}, {at(10, 0), 0}}},
+	}
+
+	// Act
+	data, err := RenderPNG(series)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a valid PNG, but got: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, width, height) {
+		t.Errorf("Expected a %dx%d chart, got %v", width, height, img.Bounds())
+	}
+	if countPixels(img, mainColor) == 0 {
+		t.Error("Expected the first series to be drawn in the highlight color")
+	}
+	if countPixels(img, comparedColors[0]) == 0 {
+		t.Error("Expected the compared series to be drawn in grey")
+	}
+}
+
+func TestRenderPNG_WhenNoSeriesHasPoints_ReturnsErrNoData(t *testing.T) {
+	// Act
+	_, err := RenderPNG([]Series{{Label: "09.03"}})
+
+	// Assert
+	if !errors.Is(err, ErrNoData) {
+		t.Errorf("Expected ErrNoData, got %v", err)
+	}
+}
+
+func TestNewAxes_Always_RoundsRangesToWholeHoursAndNiceValues(t *testing.T) {
+	testCases := []struct {
+		name              string
+		points            []Point
+		expectedFrom      time.Duration
+		expectedTo        time.Duration
+		expectedTimeStep  time.Duration
+		expectedMaxValue  int
+		expectedValueStep int
+	}{
+		{"Morning opening", []Point{{at(8, 10), 120}, {at(9, 40), 0}}, at(8, 0), at(10, 0), time.Hour, 150, 50},
+		{"Whole day", []Point{{at(6, 0), 7}, {at(20, 30), 0}}, at(6, 0), at(21, 0), 2 * time.Hour, 7, 1},
+		{"Single point on the hour", []Point{{at(8, 0), 0}}, at(8, 0), at(9, 0), time.Hour, 1, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual, ok := newAxes([]Series{{Points: tc.points}})
+
+			// Assert
+			if !ok {
+				t.Fatal("Expected the axes to be computed")
+			}
+			if actual.from != tc.expectedFrom || actual.to != tc.expectedTo || actual.timeStep != tc.expectedTimeStep {
+				t.Errorf("Expected time axis %v-%v every %v, got %v-%v every %v", tc.expectedFrom, tc.expectedTo, tc.expectedTimeStep, actual.from, actual.to, actual.timeStep)
+			}
+			if actual.maxValue != tc.expectedMaxValue || actual.valueStep != tc.expectedValueStep {
+				t.Errorf("Expected value axis up to %d every %d, got %d every %d", tc.expectedMaxValue, tc.expectedValueStep, actual.maxValue, actual.valueStep)
+			}
+		})
+	}
+}
+
+func TestTextWidth_Always_CountsGlyphsAndSpacing(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"8", 6},
+		{"08:00", 38},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			// Act
+			actual := textWidth(tc.text)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected width %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRender_WhenSeriesHasOnePoint_DrawsItsValueUntilEndOfTimeAxis(t *testing.T) {
+	// Arrange
+	series := []Series{{Label: "09.03", Points: []Point{{at(8, 0), 40}}}}
+	axes, _ := newAxes(series)
+
+	// Act
+	img, err := render(series)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	y := axes.y(40)
+	for _, x := range []int{axes.x(at(8, 0)), axes.x(at(8, 30)), axes.x(axes.to) - 1} {
+		if img.RGBAAt(x, y) != mainColor {
+			t.Errorf("Expected the value to be drawn at (%d, %d), got %v", x, y, img.RGBAAt(x, y))
+		}
+	}
+}
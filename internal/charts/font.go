@@ -0,0 +1,56 @@
+package charts
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphScale   = 2
+	glyphSpacing = 1 // between glyphs, before scaling
+)
+
+// glyphs is a tiny bitmap font for the labels of the axes and the legend: times, numbers and dates.
+// Other characters are drawn as spaces.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'.': {"...", "...", "...", "...", ".#."},
+	'-': {"...", "...", "###", "...", "..."},
+}
+
+// textWidth returns the width of the text in pixels.
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+glyphSpacing) - glyphSpacing) * glyphScale
+}
+
+// drawText draws the text with its top left corner at x, y.
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	for _, r := range text {
+		for row, line := range glyphs[r] {
+			for col, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+				px, py := x+col*glyphScale, y+row*glyphScale
+				fillRect(img, image.Rect(px, py, px+glyphScale, py+glyphScale), c)
+			}
+		}
+		x += (glyphWidth + glyphSpacing) * glyphScale
+	}
+}
@@ -0,0 +1,44 @@
+package charts
+
+import (
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+)
+
+// TicketsSeries returns the tickets left during the day, from when the queue became active until it became inactive
+// for the last time. The observations are of a single day, oldest first, see queuehistory.Store.Day.
+// The series has no points if the queue was never active.
+func TicketsSeries(label string, observations []*queuehistory.Observation) Series {
+	first, last := -1, -1
+	for i, obs := range observations {
+		if !obs.Active {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+
+	series := Series{Label: label}
+	if first < 0 {
+		return series
+	}
+	// the curve ends when the queue closes
+	end := min(last+1, len(observations)-1)
+	for _, obs := range observations[first : end+1] {
+		series.Points = append(series.Points, Point{At: queuehistory.Clock(obs.At), Value: obs.TicketsLeft})
+	}
+	return series
+}
+
+// ComparedSeries returns the tickets series of the day, followed by those of the compared days, labelled with their
+// dates in the given layout. The compared days the queue was never active on are skipped, as there is nothing to compare.
+func ComparedSeries(layout string, day *queuehistory.Day, compared []*queuehistory.Day) []Series {
+	series := []Series{TicketsSeries(day.Date.Format(layout), day.Observations)}
+	for _, d := range compared {
+		if s := TicketsSeries(d.Date.Format(layout), d.Observations); len(s.Points) > 0 {
+			series = append(series, s)
+		}
+	}
+	return series
+}
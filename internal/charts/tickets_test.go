@@ -0,0 +1,89 @@
+package charts
+
+import (
+	"testing"
+	"time"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func observedAt(clock string, active, enabled bool, ticketsLeft int) *queuehistory.Observation {
+	at, _ := time.Parse("2006-01-02 15:04", "2026-03-02 "+clock)
+	return &queuehistory.Observation{At: at, QueueName: "Odbiór karty", Active: active, Enabled: enabled, TicketsLeft: ticketsLeft}
+}
+
+func TestTicketsSeries_Always_CoversTimeTheQueueWasActive(t *testing.T) {
+	testCases := []struct {
+		name         string
+		observations []*queuehistory.Observation
+		expected     []Point
+	}{
+		{
+			"Nothing observed",
+			nil,
+			nil,
+		},
+		{
+			"Queue was never active",
+			[]*queuehistory.Observation{observedAt("07:00", false, false, 0)},
+			nil,
+		},
+		{
+			"Queue opened and closed",
+			[]*queuehistory.Observation{
+				observedAt("06:00", false, false, 0),
+				observedAt("08:00", true, false, 0),
+				observedAt("08:10", true, true, 120),
+				observedAt("08:40", true, true, 30),
+				observedAt("09:10", true, false, 0),
+				observedAt("15:00", false, false, 0),
+				observedAt("23:00", false, false, 0),
+			},
+			[]Point{{at(8, 0), 0}, {at(8, 10), 120}, {at(8, 40), 30}, {at(9, 10), 0}, {at(15, 0), 0}},
+		},
+		{
+			"Queue is still active",
+			[]*queuehistory.Observation{
+				observedAt("08:10", true, true, 120),
+				observedAt("08:40", true, true, 30),
+			},
+			[]Point{{at(8, 10), 120}, {at(8, 40), 30}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := TicketsSeries("02.03", tc.observations)
+
+			// Assert
+			if diff := cmp.Diff(Series{Label: "02.03", Points: tc.expected}, actual); diff != "" {
+				t.Errorf("Unexpected series (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComparedSeries_Always_SkipsComparedDaysWithoutPoints(t *testing.T) {
+	// Arrange
+	date := func(day int) time.Time { return time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC) }
+	opened := []*queuehistory.Observation{observedAt("08:10", true, true, 120), observedAt("08:40", true, false, 0)}
+	day := &queuehistory.Day{Date: date(16), Observations: opened}
+	compared := []*queuehistory.Day{
+		{Date: date(9), Observations: []*queuehistory.Observation{observedAt("07:00", false, false, 0)}},
+		{Date: date(2), Observations: opened},
+	}
+
+	// Act
+	actual := ComparedSeries("02.01", day, compared)
+
+	// Assert
+	labels := make([]string, 0, len(actual))
+	for _, s := range actual {
+		labels = append(labels, s.Label)
+	}
+	if diff := cmp.Diff([]string{"16.03", "02.03"}, labels); diff != "" {
+		t.Errorf("Unexpected series (-expected +actual):\n%s", diff)
+	}
+}
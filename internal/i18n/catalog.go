@@ -0,0 +1,213 @@
+// Package i18n provides the translated user-facing texts of the bot and the notifications.
+// Translations are kept in JSON files, one per language, with plural forms selected by the rules of the language.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language of the channel and the last resort for missing translations: every text is defined in it.
+const DefaultLanguage = "pl"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Default is the catalog of the built-in translations.
+var Default = mustLoad(locales)
+
+// message is a single text, or a set of plural forms.
+type message struct {
+	text    string
+	plurals map[string]string
+}
+
+// Catalog holds the messages of all languages. Keys missing in a language fall back to the fallback language,
+// and then to DefaultLanguage.
+type Catalog struct {
+	messages map[string]map[string]*message
+	fallback string
+}
+
+// Load reads the "locales/<language>.json" files. A file maps message keys to texts or to objects with plural forms.
+func Load(fsys fs.FS) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list translation files: %w", err)
+	}
+
+	c := &Catalog{messages: make(map[string]map[string]*message), fallback: DefaultLanguage}
+	for _, file := range files {
+		lang := strings.TrimSuffix(path.Base(file), ".json")
+		if _, ok := pluralRules[lang]; !ok {
+			return nil, fmt.Errorf("unsupported language %s: no plural rules", lang)
+		}
+
+		messages, err := loadMessages(fsys, file, lang)
+		if err != nil {
+			return nil, err
+		}
+		c.messages[lang] = messages
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func mustLoad(fsys fs.FS) *Catalog {
+	c, err := Load(fsys)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in translations: %v", err))
+	}
+	return c
+}
+
+func loadMessages(fsys fs.FS, file, lang string) (map[string]*message, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read translation file %s: %w", file, err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse translation file %s: %w", file, err)
+	}
+
+	messages := make(map[string]*message, len(raw))
+	for key, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			messages[key] = &message{text: text}
+			continue
+		}
+
+		var plurals map[string]string
+		if err := json.Unmarshal(value, &plurals); err != nil {
+			return nil, fmt.Errorf("message %s in %s must be a text or an object with plural forms", key, file)
+		}
+		for _, category := range pluralCategories[lang] {
+			if _, ok := plurals[category]; !ok {
+				return nil, fmt.Errorf("message %s in %s has no %q plural form", key, file, category)
+			}
+		}
+		messages[key] = &message{plurals: plurals}
+	}
+	return messages, nil
+}
+
+// validate checks that every text can fall back to the default language.
+func (c *Catalog) validate() error {
+	defaults, ok := c.messages[DefaultLanguage]
+	if !ok {
+		return fmt.Errorf("no translations for the default language %s", DefaultLanguage)
+	}
+
+	for lang, messages := range c.messages {
+		for key, msg := range messages {
+			defaultMsg, ok := defaults[key]
+			if !ok {
+				return fmt.Errorf("message %s of %s is missing in the default language", key, lang)
+			}
+			if (msg.plurals == nil) != (defaultMsg.plurals == nil) {
+				return fmt.Errorf("message %s of %s must have plural forms like in the default language, or none", key, lang)
+			}
+		}
+	}
+	return nil
+}
+
+// SetFallback sets the language used for keys missing in the requested language, and for unsupported languages.
+func (c *Catalog) SetFallback(lang string) error {
+	if _, ok := c.messages[lang]; !ok {
+		return fmt.Errorf("unsupported fallback language %s", lang)
+	}
+	c.fallback = lang
+	return nil
+}
+
+// Languages returns the supported languages, sorted.
+func (c *Catalog) Languages() []string {
+	languages := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Language maps an IETF language tag, e.g. the LanguageCode of a Telegram user ("en-GB"), to a supported language.
+// Unsupported languages are mapped to the fallback language.
+func (c *Catalog) Language(code string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(code), "-")
+	if _, ok := c.messages[lang]; ok {
+		return lang
+	}
+	return c.fallback
+}
+
+// Message returns the text of the key in the language. It's a fmt format, which the caller must render escaping the arguments.
+// If the key is not defined at all, the key itself is returned. Messages with plural forms should be read with PluralMessage.
+func (c *Catalog) Message(lang, key string) string {
+	msg := c.lookup(lang, key)
+	switch {
+	case msg == nil:
+		return key
+	case msg.plurals != nil:
+		return msg.generalForm()
+	default:
+		return msg.text
+	}
+}
+
+// PluralMessage returns the plural form of the key for the count, selected by the plural rules of the language.
+func (c *Catalog) PluralMessage(lang, key string, n int) string {
+	msg := c.lookup(lang, key)
+	if msg == nil {
+		return key
+	}
+	if msg.plurals == nil {
+		return msg.text
+	}
+
+	// the message may come from a fallback language, which has its own plural rules
+	msgLang := c.languageOf(lang, key)
+	if n < 0 {
+		n = -n
+	}
+	if form, ok := msg.plurals[pluralRules[msgLang](n)]; ok {
+		return form
+	}
+	return msg.generalForm()
+}
+
+// generalForm returns the plural form used for counts without a specific form.
+func (m *message) generalForm() string {
+	if form, ok := m.plurals[PluralOther]; ok {
+		return form
+	}
+	return m.plurals[PluralMany]
+}
+
+func (c *Catalog) lookup(lang, key string) *message {
+	if messages, ok := c.messages[c.languageOf(lang, key)]; ok {
+		return messages[key]
+	}
+	return nil
+}
+
+// languageOf returns the first language of the fallback chain which defines the key.
+func (c *Catalog) languageOf(lang, key string) string {
+	for _, l := range []string{lang, c.fallback, DefaultLanguage} {
+		if _, ok := c.messages[l][key]; ok {
+			return l
+		}
+	}
+	return DefaultLanguage
+}
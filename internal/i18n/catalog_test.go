@@ -0,0 +1,154 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPluralMessage_Always_SelectsPluralFormOfLanguage(t *testing.T) {
+	testCases := []struct {
+		lang     string
+		n        int
+		expected string
+	}{
+		{"pl", 0, "🧾 Pozostało <b>%d</b> biletów"},
+		{"pl", 1, "🧾 Pozostał <b>%d</b> bilet"},
+		{"pl", 2, "🧾 Pozostały <b>%d</b> bilety"},
+		{"pl", 5, "🧾 Pozostało <b>%d</b> biletów"},
+		{"pl", 12, "🧾 Pozostało <b>%d</b> biletów"},
+		{"pl", 21, "🧾 Pozostało <b>%d</b> biletów"},
+		{"pl", 24, "🧾 Pozostały <b>%d</b> bilety"},
+		{"uk", 1, "🧾 Залишився <b>%d</b> квиток"},
+		{"uk", 21, "🧾 Залишився <b>%d</b> квиток"},
+		{"uk", 11, "🧾 Залишилося <b>%d</b> квитків"},
+		{"be", 3, "🧾 Засталося <b>%d</b> білеты"},
+		{"en", 1, "🧾 <b>%d</b> ticket left"},
+		{"en", 0, "🧾 <b>%d</b> tickets left"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.lang, func(t *testing.T) {
+			// Act
+			actual := Default.PluralMessage(tc.lang, "queue.tickets_left", tc.n)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected %q for %d, got %q", tc.expected, tc.n, actual)
+			}
+		})
+	}
+}
+
+func TestLanguage_Always_MapsLanguageCodeToSupportedLanguage(t *testing.T) {
+	testCases := []struct {
+		code     string
+		expected string
+	}{
+		{"en", "en"},
+		{"en-GB", "en"},
+		{"UK", "uk"},
+		{"de", DefaultLanguage},
+		{"", DefaultLanguage},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			// Act
+			actual := Default.Language(tc.code)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMessage_WhenKeyIsMissingInLanguage_FallsBackToFallbackThenDefaultLanguage(t *testing.T) {
+	// Arrange
+	sut, err := Load(fstest.MapFS{
+		"locales/pl.json": {Data: []byte(`{"greeting": "Cześć", "farewell": "Do widzenia"}`)},
+		"locales/en.json": {Data: []byte(`{"greeting": "Hello"}`)},
+		"locales/uk.json": {Data: []byte(`{}`)},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := sut.SetFallback("en"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Act & Assert
+	if actual := sut.Message("uk", "greeting"); actual != "Hello" {
+		t.Errorf("Expected the fallback language text, got %q", actual)
+	}
+	if actual := sut.Message("uk", "farewell"); actual != "Do widzenia" {
+		t.Errorf("Expected the default language text, got %q", actual)
+	}
+	if actual := sut.Message("de", "greeting"); actual != "Hello" {
+		t.Errorf("Expected the fallback language text for an unsupported language, got %q", actual)
+	}
+	if actual := sut.Message("en", "unknown"); actual != "unknown" {
+		t.Errorf("Expected the key of an unknown message, got %q", actual)
+	}
+}
+
+func TestLoad_WhenTranslationsAreInvalid_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         fstest.MapFS
+		expectedError string
+	}{
+		{
+			"missing plural form",
+			fstest.MapFS{"locales/pl.json": {Data: []byte(`{"tickets": {"one": "bilet", "many": "biletów"}}`)}},
+			`has no "few" plural form`,
+		},
+		{
+			"key missing in default language",
+			fstest.MapFS{
+				"locales/pl.json": {Data: []byte(`{}`)},
+				"locales/en.json": {Data: []byte(`{"greeting": "Hello"}`)},
+			},
+			"missing in the default language",
+		},
+		{
+			"no default language",
+			fstest.MapFS{"locales/en.json": {Data: []byte(`{}`)}},
+			"no translations for the default language",
+		},
+		{
+			"language without plural rules",
+			fstest.MapFS{"locales/de.json": {Data: []byte(`{}`)}},
+			"unsupported language de",
+		},
+		{
+			"invalid message",
+			fstest.MapFS{"locales/pl.json": {Data: []byte(`{"greeting": 1}`)}},
+			"must be a text or an object with plural forms",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := Load(tc.files)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDefault_Always_TranslatesEveryMessageToEveryLanguage(t *testing.T) {
+	for _, lang := range Default.Languages() {
+		for key := range Default.messages[DefaultLanguage] {
+			if _, ok := Default.messages[lang][key]; !ok {
+				t.Errorf("Message %s is not translated to %s", key, lang)
+			}
+		}
+	}
+}
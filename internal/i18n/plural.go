@@ -0,0 +1,62 @@
+package i18n
+
+// Plural categories, named after the CLDR plural rules. See https://cldr.unicode.org/index/cldr-spec/plural-rules
+const (
+	PluralOne   = "one"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// pluralRule returns the plural category of a non-negative integer count.
+type pluralRule func(n int) string
+
+// Polish: 1 bilet, 2-4 bilety, 5 biletów, 22 bilety, 25 biletów
+func polishPlural(n int) string {
+	switch {
+	case n == 1:
+		return PluralOne
+	case isFew(n):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+// East Slavic languages: 1 квиток, 21 квиток, 2 квитки, 5 квитків, 11 квитків
+func eastSlavicPlural(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return PluralOne
+	case isFew(n):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func englishPlural(n int) string {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func isFew(n int) bool {
+	return n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14)
+}
+
+var pluralRules = map[string]pluralRule{
+	"pl": polishPlural,
+	"uk": eastSlavicPlural,
+	"be": eastSlavicPlural,
+	"en": englishPlural,
+}
+
+// pluralCategories are the categories which every plural message of the language must define.
+var pluralCategories = map[string][]string{
+	"pl": {PluralOne, PluralFew, PluralMany},
+	"uk": {PluralOne, PluralFew, PluralMany},
+	"be": {PluralOne, PluralFew, PluralMany},
+	"en": {PluralOne, PluralOther},
+}
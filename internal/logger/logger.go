@@ -17,7 +17,8 @@ func NewLogger(cfg *Config) *Logger {
 		Level: cfg.GetLogLevel(),
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, opts)
+	// secrets are redacted from every record, see RegisterSecret
+	handler := newRedactingHandler(slog.NewTextHandler(os.Stdout, opts))
 
 	return &Logger{slog.New(handler)}
 }
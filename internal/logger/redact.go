@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	redactedText = "[REDACTED]"
+
+	// shorter secrets are not registered: redacting them would mangle unrelated text
+	minSecretLength = 4
+)
+
+// urlTokenPatterns match credentials which are part of URLs, even if they were not registered.
+var urlTokenPatterns = []struct {
+	regex       *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`bot\d+:[A-Za-z0-9_-]+`), "bot" + redactedText}, // Telegram Bot API paths
+	{regexp.MustCompile(`(?i)([?&](?:token|access_token|api_key|apikey|key|secret|password|auth)=)[^&\s"']+`), "${1}" + redactedText},
+	{regexp.MustCompile(`(://[^:/@\s]*:)[^@/\s]+@`), "${1}" + redactedText + "@"}, // passwords in user info, e.g. redis://:password@host
+}
+
+// secretRegistry holds the secrets which must never be logged.
+type secretRegistry struct {
+	mu       sync.RWMutex
+	secrets  map[string]struct{}
+	replacer *strings.Replacer
+}
+
+var secrets = &secretRegistry{secrets: make(map[string]struct{})}
+
+func (r *secretRegistry) register(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.secrets[secret]; ok {
+		return
+	}
+	r.secrets[secret] = struct{}{}
+
+	// longer secrets first, so a secret containing another one is redacted as a whole
+	sorted := make([]string, 0, len(r.secrets))
+	for s := range r.secrets {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, s := range sorted {
+		pairs = append(pairs, s, redactedText)
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+func (r *secretRegistry) redact(s string) string {
+	r.mu.RLock()
+	replacer := r.replacer
+	r.mu.RUnlock()
+
+	if replacer != nil {
+		s = replacer.Replace(s)
+	}
+	for _, p := range urlTokenPatterns {
+		s = p.regex.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// RegisterSecret makes all loggers redact the secret from now on.
+func RegisterSecret(secret string) {
+	secrets.register(secret)
+}
+
+// RegisterSecrets registers the fields of the configuration tagged with `secret`, including nested structs.
+// `secret:"true"` registers a string, or the elements of a []string or the values of a map[string]string.
+// `secret:"url"` registers the password of a URL, e.g. a Redis connection string.
+func RegisterSecrets(cfg any) {
+	registerSecrets(reflect.Indirect(reflect.ValueOf(cfg)))
+}
+
+func registerSecrets(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		switch tag := field.Tag.Get("secret"); {
+		case tag == "url" && value.Kind() == reflect.String:
+			if u, err := url.Parse(value.String()); err == nil && u.User != nil {
+				password, _ := u.User.Password()
+				RegisterSecret(password)
+			}
+		case tag == "true":
+			registerSecretValue(value)
+		case value.Kind() == reflect.Struct:
+			registerSecrets(value)
+		}
+	}
+}
+
+func registerSecretValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		RegisterSecret(v.String())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			registerSecretValue(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			registerSecretValue(v.MapIndex(key))
+		}
+	}
+}
+
+// Redact removes the registered secrets and URL credentials from the text.
+// It's meant for error messages which leave the process by other means than logs, e.g. stored in Redis.
+func Redact(s string) string {
+	return secrets.redact(s)
+}
+
+// redactingHandler scrubs secrets from the message and the attributes of every record, including errors, before passing it on.
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func newRedactingHandler(next slog.Handler) *redactingHandler {
+	return &redactingHandler{next: next}
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return newRedactingHandler(h.next.WithAttrs(redacted))
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return newRedactingHandler(h.next.WithGroup(name))
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(Redact(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		// errors and stringers are logged as text, which may contain secrets, e.g. URLs of failed requests
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(Redact(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(Redact(v.String()))
+		}
+	}
+	return a
+}
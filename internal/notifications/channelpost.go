@@ -0,0 +1,28 @@
+package notifications
+
+import "context"
+
+// ChannelPost is a message to the channel about a queue which is not a queue event, e.g. the daily summary.
+// The notifiers which route the queue events to the forum topics and to the language channels route the posts the same way,
+// so the text is rendered by each of them in the language of its chat.
+type ChannelPost struct {
+	ChatID   string
+	QueueID  int
+	Language string
+	Render   func(language string) string // the text in the language, pre-formatted for Telegram (HTML)
+	Photo    []byte                       // optional, the text is its caption if it's short enough
+	Silent   bool
+}
+
+// PostNotifier is implemented by the notifiers of the channel which can send the posts, see ChannelPost.
+type PostNotifier interface {
+	Post(ctx context.Context, post *ChannelPost) error
+}
+
+// sendPost sends the post with the notifier, or only its text in the language of the post if the notifier doesn't support posts.
+func sendPost(ctx context.Context, notifier Notifier, post *ChannelPost) error {
+	if postNotifier, ok := notifier.(PostNotifier); ok {
+		return postNotifier.Post(ctx, post)
+	}
+	return notifier.SendMessage(ctx, post.ChatID, post.Render(post.Language))
+}
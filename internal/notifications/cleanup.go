@@ -0,0 +1,230 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	CleanupPolicyDelete  = "delete"  // delete the day's messages
+	CleanupPolicyUnpin   = "unpin"   // keep the day's messages, but unpin them
+	CleanupPolicySummary = "summary" // replace the day's messages with a single summary message
+
+	sentMessagesRedisKeyPrefix = "telegram:sent_messages:"
+
+	// deleteMessages accepts at most 100 IDs
+	maxDeleteMessagesBatch = 100
+	// leave room for the header and the "and N more" line below the 4096 characters limit of a message
+	maxSummaryLength = 3800
+
+	msgKeyCleanupSummaryHeader = "cleanup.summary_header"
+	msgKeyCleanupSummaryMore   = "cleanup.summary_more"
+	msgCleanupSummaryLine      = "\n<i>%s</i> %s"
+	cleanupTimeLayout          = "15:04"
+)
+
+// SentMessage is a message posted to a chat, recorded so it can be cleaned up at the end of the day.
+// EventType is empty for free-form messages.
+type SentMessage struct {
+	MessageID int64     `json:"message_id"`
+	Text      string    `json:"text"`
+	SentAt    time.Time `json:"sent_at"`
+	EventType EventType `json:"event_type,omitempty"`
+}
+
+// SentMessageStore records the messages posted to each chat.
+type SentMessageStore interface {
+	Add(ctx context.Context, chatID string, msg *SentMessage) error
+	List(ctx context.Context, chatID string) ([]*SentMessage, error) // oldest first
+	Clear(ctx context.Context, chatID string) error
+}
+
+type RedisSentMessageStore struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+func NewRedisSentMessageStore(redisClient *redis.Client, ttlSeconds uint) *RedisSentMessageStore {
+	return &RedisSentMessageStore{
+		redisClient: redisClient,
+		ttl:         time.Duration(ttlSeconds) * time.Second,
+	}
+}
+
+func (s *RedisSentMessageStore) Add(ctx context.Context, chatID string, msg *SentMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sent message: %w", err)
+	}
+
+	key := sentMessagesRedisKeyPrefix + chatID
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, data)
+		pipe.Expire(ctx, key, s.ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save sent message to Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisSentMessageStore) List(ctx context.Context, chatID string) ([]*SentMessage, error) {
+	values, err := s.redisClient.LRange(ctx, sentMessagesRedisKeyPrefix+chatID, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sent messages from Redis: %w", err)
+	}
+
+	msgs := make([]*SentMessage, 0, len(values))
+	for _, v := range values {
+		var msg SentMessage
+		if err := json.Unmarshal([]byte(v), &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal sent message: %w", err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, nil
+}
+
+func (s *RedisSentMessageStore) Clear(ctx context.Context, chatID string) error {
+	if err := s.redisClient.Del(ctx, sentMessagesRedisKeyPrefix+chatID).Err(); err != nil {
+		return fmt.Errorf("failed to delete sent messages from Redis: %w", err)
+	}
+	return nil
+}
+
+// ChannelCleanupNotifier tidies up the channel when the queue becomes inactive: the ticket updates posted during the day
+// are deleted, unpinned or collapsed into a summary, depending on the configured policy. The other messages, e.g. the
+// opening of the queue, stay in the channel.
+// The messages must be recorded by the Telegram notifier, see NewRecordingTelegramNotifier.
+type ChannelCleanupNotifier struct {
+	cfg      *TelegramCleanupConfig
+	log      *logger.Logger
+	telegram *TelegramNotifier
+	store    SentMessageStore
+	notifier Notifier
+}
+
+func NewChannelCleanupNotifier(cfg *TelegramCleanupConfig, log *logger.Logger, telegram *TelegramNotifier, store SentMessageStore, notifier Notifier) (*ChannelCleanupNotifier, error) {
+	switch cfg.Policy {
+	case CleanupPolicyDelete, CleanupPolicyUnpin, CleanupPolicySummary:
+	default:
+		return nil, fmt.Errorf("unknown cleanup policy: %s", cfg.Policy)
+	}
+
+	return &ChannelCleanupNotifier{
+		cfg:      cfg,
+		log:      log,
+		telegram: telegram,
+		store:    store,
+		notifier: notifier,
+	}, nil
+}
+
+func (n *ChannelCleanupNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return n.notifier.SendMessage(ctx, chatID, text)
+}
+
+// Post forwards the post, which is not cleaned up: only the ticket updates are.
+func (n *ChannelCleanupNotifier) Post(ctx context.Context, post *ChannelPost) error {
+	return sendPost(ctx, n.notifier, post)
+}
+
+func (n *ChannelCleanupNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	if event.Type != EventQueueInactive {
+		return n.send(ctx, event)
+	}
+
+	msgs, err := n.store.List(ctx, event.ChatID)
+	if err != nil {
+		n.log.Error("Failed to get the day's messages, skipping cleanup", err, "chatId", event.ChatID)
+	}
+
+	if err := n.send(ctx, event); err != nil {
+		return err
+	}
+
+	msgs = slices.DeleteFunc(msgs, func(msg *SentMessage) bool {
+		return msg.EventType != EventTicketsChanged && msg.EventType != EventTicketsLow
+	})
+	// the cleanup is cosmetic, so its failures are only logged and never fail the notification
+	if len(msgs) > 0 {
+		n.cleanup(ctx, event, msgs)
+	}
+	// forget the inactive message and the summary as well: they should stay in the channel
+	if err := n.store.Clear(ctx, event.ChatID); err != nil {
+		n.log.Error("Failed to clear the day's messages", err, "chatId", event.ChatID)
+	}
+	return nil
+}
+
+func (n *ChannelCleanupNotifier) send(ctx context.Context, event *QueueEvent) error {
+	if eventNotifier, ok := n.notifier.(EventNotifier); ok {
+		return eventNotifier.Notify(ctx, event)
+	}
+	return n.notifier.SendMessage(ctx, event.ChatID, event.Text)
+}
+
+func (n *ChannelCleanupNotifier) cleanup(ctx context.Context, event *QueueEvent, msgs []*SentMessage) {
+	chatID := event.ChatID
+	n.log.Info("Cleaning up the day's messages", "chatId", chatID, "policy", n.cfg.Policy, "count", len(msgs))
+
+	switch n.cfg.Policy {
+	case CleanupPolicyDelete:
+		n.deleteMessages(ctx, chatID, msgs)
+	case CleanupPolicyUnpin:
+		for _, msg := range msgs {
+			if err := n.telegram.UnpinChatMessage(ctx, chatID, msg.MessageID); err != nil {
+				n.log.Error("Failed to unpin message", err, "chatId", chatID, "messageId", msg.MessageID)
+			}
+		}
+	case CleanupPolicySummary:
+		// post the summary first, so nothing is lost if it fails. It's not worth a sound at the end of the day
+		if _, err := n.telegram.PostMessage(ctx, chatID, n.buildSummary(event.Language, msgs), MessageOptions{DisableNotification: true}); err != nil {
+			n.log.Error("Failed to post the day's summary, keeping the messages", err, "chatId", chatID)
+			return
+		}
+		n.deleteMessages(ctx, chatID, msgs)
+	}
+}
+
+func (n *ChannelCleanupNotifier) deleteMessages(ctx context.Context, chatID string, msgs []*SentMessage) {
+	for start := 0; start < len(msgs); start += maxDeleteMessagesBatch {
+		batch := msgs[start:min(start+maxDeleteMessagesBatch, len(msgs))]
+		ids := make([]int64, 0, len(batch))
+		for _, msg := range batch {
+			ids = append(ids, msg.MessageID)
+		}
+
+		if err := n.telegram.DeleteMessages(ctx, chatID, ids); err != nil {
+			n.log.Error("Failed to delete messages", err, "chatId", chatID, "count", len(ids))
+		}
+	}
+}
+
+// buildSummary lists the day's messages, one per line, in the language of the chat.
+// The latest messages are dropped if the summary would be too long.
+func (n *ChannelCleanupNotifier) buildSummary(lang string, msgs []*SentMessage) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(i18n.Default.Message(lang, msgKeyCleanupSummaryHeader), len(msgs)))
+
+	for i, msg := range msgs {
+		line := fmt.Sprintf(msgCleanupSummaryLine, templates.Default.LocalTime(msg.SentAt).Format(cleanupTimeLayout), strings.ReplaceAll(msg.Text, "\n", " · "))
+		if sb.Len()+len(line) > maxSummaryLength {
+			sb.WriteString("\n" + fmt.Sprintf(i18n.Default.Message(lang, msgKeyCleanupSummaryMore), len(msgs)-i))
+			break
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
@@ -0,0 +1,216 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+type inMemorySentMessageStore struct {
+	messages map[string][]*SentMessage
+}
+
+func (s *inMemorySentMessageStore) Add(ctx context.Context, chatID string, msg *SentMessage) error {
+	s.messages[chatID] = append(s.messages[chatID], msg)
+	return nil
+}
+
+func (s *inMemorySentMessageStore) List(ctx context.Context, chatID string) ([]*SentMessage, error) {
+	return s.messages[chatID], nil
+}
+
+func (s *inMemorySentMessageStore) Clear(ctx context.Context, chatID string) error {
+	delete(s.messages, chatID)
+	return nil
+}
+
+func newTestRecordingTelegramNotifier(t *testing.T, api *mockTelegramBotApi, store SentMessageStore) *TelegramNotifier {
+	server := api.start(t)
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+	}
+	return NewRecordingTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{}, store)
+}
+
+func TestRecordingTelegramNotifierSendMessage_WhenMessageIsSent_RecordsItsID(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{nextMessageID: 10}
+	store := &inMemorySentMessageStore{messages: map[string][]*SentMessage{}}
+	sut := newTestRecordingTelegramNotifier(t, api, store)
+
+	// Act
+	err := sut.SendMessage(context.Background(), "@channel", "first")
+	err2 := sut.SendMessage(context.Background(), "@channel", "second")
+
+	// Assert
+	if err != nil || err2 != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", err, err2)
+	}
+
+	recorded := store.messages["@channel"]
+	if len(recorded) != 2 || recorded[0].MessageID != 11 || recorded[1].MessageID != 12 || recorded[1].Text != "second" {
+		t.Errorf("Expected both messages to be recorded, but got %+v", recorded)
+	}
+}
+
+func TestChannelCleanupNotifierNotify_WhenQueueBecomesInactive_AppliesPolicyToDaysMessages(t *testing.T) {
+	testCases := []struct {
+		policy          string
+		expectedMethods []string
+	}{
+		{CleanupPolicyDelete, []string{"sendMessage", "deleteMessages"}},
+		{CleanupPolicyUnpin, []string{"sendMessage", "unpinChatMessage", "unpinChatMessage"}},
+		{CleanupPolicySummary, []string{"sendMessage", "sendMessage", "deleteMessages"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.policy, func(t *testing.T) {
+			// Arrange
+			api := &mockTelegramBotApi{nextMessageID: 100}
+			sentAt := time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC) // 08:30 in Warsaw
+			store := &inMemorySentMessageStore{messages: map[string][]*SentMessage{"@channel": {
+				{MessageID: 1, Text: "10 left", SentAt: sentAt, EventType: EventTicketsChanged},
+				{MessageID: 2, Text: "9 left\nhurry up", SentAt: sentAt.Add(5 * time.Minute), EventType: EventTicketsLow},
+			}}}
+			telegram := newTestRecordingTelegramNotifier(t, api, store)
+
+			cfg := &TelegramCleanupConfig{Enabled: true, Policy: tc.policy}
+			sut, err := NewChannelCleanupNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), telegram, store, telegram)
+			if err != nil {
+				t.Fatalf("Failed to create cleanup notifier: %v", err)
+			}
+
+			// Act
+			err = sut.Notify(context.Background(), &QueueEvent{Type: EventQueueInactive, ChatID: "@channel", Text: "inactive"})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if fmt.Sprint(api.methods()) != fmt.Sprint(tc.expectedMethods) {
+				t.Fatalf("Expected calls %v, but got %v", tc.expectedMethods, api.methods())
+			}
+			if api.calls[0].body["text"] != "inactive" {
+				t.Errorf("Expected the inactive message to be sent first, but got %v", api.calls[0].body["text"])
+			}
+
+			last := api.calls[len(api.calls)-1].body
+			switch tc.policy {
+			case CleanupPolicyUnpin:
+				if last["message_id"] != float64(2) {
+					t.Errorf("Expected the day's messages to be unpinned, but got %v", last)
+				}
+			default:
+				if fmt.Sprint(last["message_ids"]) != "[1 2]" {
+					t.Errorf("Expected the day's messages to be deleted, but got %v", last["message_ids"])
+				}
+			}
+
+			if tc.policy == CleanupPolicySummary {
+				expectedSummary := "📋 Podsumowanie dnia — aktualizacji: <b>2</b>\n<i>08:30</i> 10 left\n<i>08:35</i> 9 left · hurry up"
+				if summary := api.calls[1].body["text"]; summary != expectedSummary {
+					t.Errorf("Expected summary %q, but got %q", expectedSummary, summary)
+				}
+			}
+
+			if len(store.messages["@channel"]) != 0 {
+				t.Errorf("Expected recorded messages to be cleared, but got %+v", store.messages["@channel"])
+			}
+		})
+	}
+}
+
+func TestChannelCleanupNotifierNotify_WhenQueueIsStillActive_OnlyForwardsEvent(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{}
+	store := &inMemorySentMessageStore{messages: map[string][]*SentMessage{}}
+	telegram := newTestRecordingTelegramNotifier(t, api, store)
+
+	cfg := &TelegramCleanupConfig{Enabled: true, Policy: CleanupPolicyDelete}
+	sut, _ := NewChannelCleanupNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), telegram, store, telegram)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged, ChatID: "@channel", Text: "9 left"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if fmt.Sprint(api.methods()) != "[sendMessage]" {
+		t.Errorf("Expected only the update to be sent, but got %v", api.methods())
+	}
+	if len(store.messages["@channel"]) != 1 {
+		t.Errorf("Expected the update to be recorded, but got %+v", store.messages["@channel"])
+	}
+}
+
+func TestChannelCleanupNotifierNotify_WhenQueueBecomesInactive_KeepsMessagesOtherThanTicketUpdates(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{nextMessageID: 100}
+	store := &inMemorySentMessageStore{messages: map[string][]*SentMessage{}}
+	telegram := newTestRecordingTelegramNotifier(t, api, store)
+
+	cfg := &TelegramCleanupConfig{Enabled: true, Policy: CleanupPolicyDelete}
+	sut, _ := NewChannelCleanupNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), telegram, store, telegram)
+	ctx := context.Background()
+
+	// Act
+	openedErr := sut.Notify(ctx, &QueueEvent{Type: EventQueueOpened, ChatID: "@channel", Text: "opened"})
+	announcementErr := sut.SendMessage(ctx, "@channel", "new version")
+	changedErr := sut.Notify(ctx, &QueueEvent{Type: EventTicketsChanged, ChatID: "@channel", Text: "9 left"})
+	inactiveErr := sut.Notify(ctx, &QueueEvent{Type: EventQueueInactive, ChatID: "@channel", Text: "inactive"})
+
+	// Assert
+	if openedErr != nil || announcementErr != nil || changedErr != nil || inactiveErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v", openedErr, announcementErr, changedErr, inactiveErr)
+	}
+	last := api.calls[len(api.calls)-1]
+	if last.method != "deleteMessages" || fmt.Sprint(last.body["message_ids"]) != "[103]" {
+		t.Errorf("Expected only the ticket update to be deleted, but got %s %v", last.method, last.body["message_ids"])
+	}
+}
+
+func TestChannelCleanupNotifierBuildSummary_WhenTooManyMessages_TruncatesSummary(t *testing.T) {
+	// Arrange
+	cfg := &TelegramCleanupConfig{Policy: CleanupPolicySummary}
+	sut, _ := NewChannelCleanupNotifier(cfg, nil, nil, nil, nil)
+
+	var msgs []*SentMessage
+	for i := range 200 {
+		msgs = append(msgs, &SentMessage{MessageID: int64(i), Text: strings.Repeat("x", 50)})
+	}
+
+	// Act
+	summary := sut.buildSummary(i18n.DefaultLanguage, msgs)
+
+	// Assert
+	if len(summary) > 4096 {
+		t.Errorf("Expected summary to fit in a Telegram message, but it has %d characters", len(summary))
+	}
+	if !strings.Contains(summary, "… i jeszcze") {
+		t.Errorf("Expected summary to mention the omitted messages, but got %q", summary[len(summary)-50:])
+	}
+}
+
+func TestNewChannelCleanupNotifier_WhenPolicyIsUnknown_ReturnsError(t *testing.T) {
+	// Arrange
+	cfg := &TelegramCleanupConfig{Policy: "archive"}
+
+	// Act
+	_, err := NewChannelCleanupNotifier(cfg, nil, nil, nil, nil)
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for unknown policy")
+	}
+}
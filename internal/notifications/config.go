@@ -2,8 +2,159 @@ package notifications
 
 type TelegramConfig struct {
 	BaseApiUrl            string `env:"NOTIFICATION_TELEGRAM_API_BASE_URL" envDefault:"https://api.telegram.org"`
-	BotToken              string `env:"NOTIFICATION_TELEGRAM_BOT_TOKEN,required"`
+	BotToken              string `env:"NOTIFICATION_TELEGRAM_BOT_TOKEN,required" secret:"true"`
 	MaxRetryAttempts      uint   `env:"NOTIFICATION_TELEGRAM_MAX_RETRY_ATTEMPTS" envDefault:"5"`
 	RetryDelayMs          uint   `env:"NOTIFICATION_TELEGRAM_RETRY_DELAY_MS" envDefault:"500"`
-	RequestTimeoutSeconds uint   `env:"NOTIFICATION_TELEGRAM_REQUEST_TIMEOUT_SECONDS" envDefault:"5"`
+	RequestTimeoutSeconds uint   `env:"NOTIFICATION_TELEGRAM_REQUEST_TIMEOUT_SECONDS" envDefault:"5"`  // timeout of a single attempt
+	MaxRetryAfterSeconds  uint   `env:"NOTIFICATION_TELEGRAM_MAX_RETRY_AFTER_SECONDS" envDefault:"60"` // give up if Telegram asks to wait longer
+	MaxCallSeconds        uint   `env:"NOTIFICATION_TELEGRAM_MAX_CALL_SECONDS" envDefault:"120"`       // give up if a call takes longer with all its retries and waits
+
+	GlobalRateLimitPerSecond uint `env:"NOTIFICATION_TELEGRAM_GLOBAL_RATE_LIMIT_PER_SECOND" envDefault:"30"`
+	ChatRateLimitPerMinute   uint `env:"NOTIFICATION_TELEGRAM_CHAT_RATE_LIMIT_PER_MINUTE" envDefault:"20"`
+	ChatRateLimitBurst       uint `env:"NOTIFICATION_TELEGRAM_CHAT_RATE_LIMIT_BURST" envDefault:"3"`
+
+	// comma-separated "event:priority" pairs, where priority is "loud" or "silent". tickets_low is sent instead of tickets_changed
+	// when the number of tickets left drops to a low tickets threshold of the monitor. Events which are not listed are loud
+	EventPriorities map[string]string `env:"NOTIFICATION_TELEGRAM_EVENT_PRIORITIES" envDefault:"queue_opened:loud,tickets_low:loud,tickets_changed:silent,queue_inactive:silent"`
+
+	// semicolon-separated "queue|text|url" links shown as buttons below the notifications while tickets can be taken,
+	// where queue is the queue ID or "*" for all queues
+	QueueButtons []QueueButton `env:"NOTIFICATION_TELEGRAM_QUEUE_BUTTONS" envSeparator:";" envDefault:"*|📅 Zarezerwuj wizytę|https://rezerwacje.duw.pl/"`
+
+	// semicolon-separated "queue|name" or "queue|name|thread ID" forum topics of the supergroup, one per queue
+	ForumTopics       []ForumTopic `env:"NOTIFICATION_TELEGRAM_FORUM_TOPICS" envSeparator:";"`
+	CreateForumTopics bool         `env:"NOTIFICATION_TELEGRAM_CREATE_FORUM_TOPICS" envDefault:"true"` // create topics without thread ID at startup
+
+	// comma-separated "language:chat" pairs: the queue notifications are also sent, translated, to the chat of each language
+	LanguageChannels map[string]string `env:"NOTIFICATION_TELEGRAM_LANGUAGE_CHANNELS"`
+}
+
+type SlackConfig struct {
+	Enabled               bool   `env:"NOTIFICATION_SLACK_ENABLED" envDefault:"false"`
+	WebhookUrl            string `env:"NOTIFICATION_SLACK_WEBHOOK_URL" secret:"true"` // the URL contains the credentials
+	MaxRetryAttempts      uint   `env:"NOTIFICATION_SLACK_MAX_RETRY_ATTEMPTS" envDefault:"5"`
+	RetryDelayMs          uint   `env:"NOTIFICATION_SLACK_RETRY_DELAY_MS" envDefault:"500"`
+	RequestTimeoutSeconds uint   `env:"NOTIFICATION_SLACK_REQUEST_TIMEOUT_SECONDS" envDefault:"5"`
+}
+
+type WebhookConfig struct {
+	Enabled               bool              `env:"NOTIFICATION_WEBHOOK_ENABLED" envDefault:"false"`
+	Targets               map[string]string `env:"NOTIFICATION_WEBHOOK_TARGETS" envKeyValSeparator:"|" secret:"true"` // comma-separated list of "url|secret" pairs
+	MaxRetryAttempts      uint              `env:"NOTIFICATION_WEBHOOK_MAX_RETRY_ATTEMPTS" envDefault:"5"`
+	RetryDelayMs          uint              `env:"NOTIFICATION_WEBHOOK_RETRY_DELAY_MS" envDefault:"500"`        // initial delay, doubled after every attempt
+	MaxRetryDelayMs       uint              `env:"NOTIFICATION_WEBHOOK_MAX_RETRY_DELAY_MS" envDefault:"10000"`  // upper bound for the exponential backoff
+	RequestTimeoutSeconds uint              `env:"NOTIFICATION_WEBHOOK_REQUEST_TIMEOUT_SECONDS" envDefault:"5"` // timeout of a single delivery attempt
+	MaxDeliverySeconds    uint              `env:"NOTIFICATION_WEBHOOK_MAX_DELIVERY_SECONDS" envDefault:"60"`   // give up if a delivery takes longer with all its retries
+	DeliveryLogSize       int               `env:"NOTIFICATION_WEBHOOK_DELIVERY_LOG_SIZE" envDefault:"100"`     // latest delivery attempts kept for the "webhook deliveries" command
+}
+
+type EmailConfig struct {
+	Enabled               bool     `env:"NOTIFICATION_EMAIL_ENABLED" envDefault:"false"`
+	SmtpHost              string   `env:"NOTIFICATION_EMAIL_SMTP_HOST"`
+	SmtpPort              int      `env:"NOTIFICATION_EMAIL_SMTP_PORT" envDefault:"587"`
+	TlsMode               string   `env:"NOTIFICATION_EMAIL_TLS_MODE" envDefault:"starttls"`    // "starttls", "implicit" or "none"
+	AuthMechanism         string   `env:"NOTIFICATION_EMAIL_AUTH_MECHANISM" envDefault:"plain"` // "plain", "login" or "none"
+	Username              string   `env:"NOTIFICATION_EMAIL_USERNAME"`
+	Password              string   `env:"NOTIFICATION_EMAIL_PASSWORD" secret:"true"`
+	From                  string   `env:"NOTIFICATION_EMAIL_FROM"`
+	To                    []string `env:"NOTIFICATION_EMAIL_TO"`
+	Mode                  string   `env:"NOTIFICATION_EMAIL_MODE" envDefault:"immediate"` // "immediate" or "digest"
+	DigestWindowSeconds   uint     `env:"NOTIFICATION_EMAIL_DIGEST_WINDOW_SECONDS" envDefault:"900"`
+	MaxDigestAttempts     uint     `env:"NOTIFICATION_EMAIL_MAX_DIGEST_ATTEMPTS" envDefault:"4"` // consecutive failed digests before their events are dropped, 0 for no limit
+	MaxRetryAttempts      uint     `env:"NOTIFICATION_EMAIL_MAX_RETRY_ATTEMPTS" envDefault:"3"`
+	RetryDelayMs          uint     `env:"NOTIFICATION_EMAIL_RETRY_DELAY_MS" envDefault:"1000"`
+	RequestTimeoutSeconds uint     `env:"NOTIFICATION_EMAIL_REQUEST_TIMEOUT_SECONDS" envDefault:"15"`
+}
+
+type MqttConfig struct {
+	Enabled               bool   `env:"NOTIFICATION_MQTT_ENABLED" envDefault:"false"`
+	BrokerUrl             string `env:"NOTIFICATION_MQTT_BROKER_URL"` // e.g. "tcp://mosquitto:1883" or "ssl://broker:8883"
+	ClientID              string `env:"NOTIFICATION_MQTT_CLIENT_ID" envDefault:"duw-queue-monitor"`
+	Username              string `env:"NOTIFICATION_MQTT_USERNAME"`
+	Password              string `env:"NOTIFICATION_MQTT_PASSWORD" secret:"true"`
+	TopicPrefix           string `env:"NOTIFICATION_MQTT_TOPIC_PREFIX" envDefault:"duw"`
+	DiscoveryEnabled      bool   `env:"NOTIFICATION_MQTT_DISCOVERY_ENABLED" envDefault:"true"`
+	DiscoveryPrefix       string `env:"NOTIFICATION_MQTT_DISCOVERY_PREFIX" envDefault:"homeassistant"`
+	Qos                   byte   `env:"NOTIFICATION_MQTT_QOS" envDefault:"1"`
+	PublishTimeoutSeconds uint   `env:"NOTIFICATION_MQTT_PUBLISH_TIMEOUT_SECONDS" envDefault:"5"`
+}
+
+type NtfyConfig struct {
+	Enabled               bool     `env:"NOTIFICATION_NTFY_ENABLED" envDefault:"false"`
+	BaseUrl               string   `env:"NOTIFICATION_NTFY_BASE_URL" envDefault:"https://ntfy.sh"`
+	Topics                []string `env:"NOTIFICATION_NTFY_TOPICS"`
+	Token                 string   `env:"NOTIFICATION_NTFY_TOKEN" secret:"true"` // access token, sent as a bearer token
+	ClickUrl              string   `env:"NOTIFICATION_PUSH_CLICK_URL" envDefault:"https://rezerwacje.duw.pl/"`
+	MaxRetryAttempts      uint     `env:"NOTIFICATION_NTFY_MAX_RETRY_ATTEMPTS" envDefault:"5"`
+	RetryDelayMs          uint     `env:"NOTIFICATION_NTFY_RETRY_DELAY_MS" envDefault:"500"`
+	RequestTimeoutSeconds uint     `env:"NOTIFICATION_NTFY_REQUEST_TIMEOUT_SECONDS" envDefault:"5"`
+}
+
+type GotifyConfig struct {
+	Enabled               bool   `env:"NOTIFICATION_GOTIFY_ENABLED" envDefault:"false"`
+	BaseUrl               string `env:"NOTIFICATION_GOTIFY_BASE_URL"`
+	Token                 string `env:"NOTIFICATION_GOTIFY_TOKEN" secret:"true"` // application token
+	ClickUrl              string `env:"NOTIFICATION_PUSH_CLICK_URL" envDefault:"https://rezerwacje.duw.pl/"`
+	MaxRetryAttempts      uint   `env:"NOTIFICATION_GOTIFY_MAX_RETRY_ATTEMPTS" envDefault:"5"`
+	RetryDelayMs          uint   `env:"NOTIFICATION_GOTIFY_RETRY_DELAY_MS" envDefault:"500"`
+	RequestTimeoutSeconds uint   `env:"NOTIFICATION_GOTIFY_REQUEST_TIMEOUT_SECONDS" envDefault:"5"`
+}
+
+type WebPushConfig struct {
+	Enabled               bool   `env:"NOTIFICATION_WEBPUSH_ENABLED" envDefault:"false"`
+	VapidPrivateKey       string `env:"NOTIFICATION_WEBPUSH_VAPID_PRIVATE_KEY" secret:"true"` // base64url-encoded raw P-256 key. If empty, a key is generated once and stored in Redis
+	VapidSubject          string `env:"NOTIFICATION_WEBPUSH_VAPID_SUBJECT"`                   // contact of the sender, "mailto:" or "https:" URL
+	ListenAddr            string `env:"NOTIFICATION_WEBPUSH_LISTEN_ADDR"`                     // address of the subscription API, e.g. ":8080". The API is disabled if empty
+	ClickUrl              string `env:"NOTIFICATION_PUSH_CLICK_URL" envDefault:"https://rezerwacje.duw.pl/"`
+	MessageTtlSeconds     int    `env:"NOTIFICATION_WEBPUSH_MESSAGE_TTL_SECONDS" envDefault:"3600"` // how long the push service keeps undelivered messages
+	MaxRetryAttempts      uint   `env:"NOTIFICATION_WEBPUSH_MAX_RETRY_ATTEMPTS" envDefault:"3"`
+	RetryDelayMs          uint   `env:"NOTIFICATION_WEBPUSH_RETRY_DELAY_MS" envDefault:"500"`
+	RequestTimeoutSeconds uint   `env:"NOTIFICATION_WEBPUSH_REQUEST_TIMEOUT_SECONDS" envDefault:"5"`
+	// hosts of the push services the subscriptions may point to, "*." matches any subdomain
+	AllowedPushHosts []string `env:"NOTIFICATION_WEBPUSH_ALLOWED_PUSH_HOSTS" envDefault:"fcm.googleapis.com,updates.push.services.mozilla.com,web.push.apple.com,*.notify.windows.com"`
+	AllowedOrigins   []string `env:"NOTIFICATION_WEBPUSH_ALLOWED_ORIGINS"`                      // origins of the web pages allowed to call the subscription API, e.g. "https://example.com"
+	MaxSubscriptions int      `env:"NOTIFICATION_WEBPUSH_MAX_SUBSCRIPTIONS" envDefault:"10000"` // new subscriptions are rejected above it
+}
+
+type OutboxConfig struct {
+	Enabled         bool `env:"NOTIFICATION_OUTBOX_ENABLED" envDefault:"false"`
+	PollIntervalMs  uint `env:"NOTIFICATION_OUTBOX_POLL_INTERVAL_MS" envDefault:"1000"`
+	BatchSize       uint `env:"NOTIFICATION_OUTBOX_BATCH_SIZE" envDefault:"20"`
+	MaxAttempts     uint `env:"NOTIFICATION_OUTBOX_MAX_ATTEMPTS" envDefault:"20"` // then the message is moved to dead letters
+	RetryDelayMs    uint `env:"NOTIFICATION_OUTBOX_RETRY_DELAY_MS" envDefault:"1000"`
+	MaxRetryDelayMs uint `env:"NOTIFICATION_OUTBOX_MAX_RETRY_DELAY_MS" envDefault:"300000"`
+}
+
+type IdempotencyConfig struct {
+	Enabled       bool `env:"NOTIFICATION_IDEMPOTENCY_ENABLED" envDefault:"true"`
+	KeyTtlSeconds uint `env:"NOTIFICATION_IDEMPOTENCY_KEY_TTL_SECONDS" envDefault:"3600"`
+}
+
+type TelegramLiveMessageConfig struct {
+	Enabled    bool `env:"NOTIFICATION_TELEGRAM_LIVE_MESSAGE_ENABLED" envDefault:"false"`
+	Pin        bool `env:"NOTIFICATION_TELEGRAM_LIVE_MESSAGE_PIN" envDefault:"false"`
+	TtlSeconds uint `env:"NOTIFICATION_TELEGRAM_LIVE_MESSAGE_TTL_SECONDS" envDefault:"86400"` // the message is not edited after this time
+}
+
+type TelegramCleanupConfig struct {
+	Enabled    bool   `env:"NOTIFICATION_TELEGRAM_CLEANUP_ENABLED" envDefault:"false"`
+	Policy     string `env:"NOTIFICATION_TELEGRAM_CLEANUP_POLICY" envDefault:"delete"`      // "delete", "unpin" or "summary"
+	TtlSeconds uint   `env:"NOTIFICATION_TELEGRAM_CLEANUP_TTL_SECONDS" envDefault:"172800"` // Telegram doesn't let bots delete messages older than 48 hours
+}
+
+type TelegramSubscriptionsConfig struct {
+	Enabled bool `env:"NOTIFICATION_TELEGRAM_SUBSCRIPTIONS_ENABLED" envDefault:"false"` // send direct messages to the users subscribed with /subscribe
+}
+
+type TicketTrackerConfig struct {
+	Enabled              bool `env:"NOTIFICATION_TICKET_TRACKER_ENABLED" envDefault:"false"`            // notify the users who track their tickets with /myticket
+	ApproachingPositions int  `env:"NOTIFICATION_TICKET_TRACKER_APPROACHING_POSITIONS" envDefault:"10"` // how many tickets before theirs the users are notified
+}
+
+type DailySummaryConfig struct {
+	Enabled              bool `env:"NOTIFICATION_DAILY_SUMMARY_ENABLED" envDefault:"false"`
+	CheckIntervalSeconds uint `env:"NOTIFICATION_DAILY_SUMMARY_CHECK_INTERVAL_SECONDS" envDefault:"60"`
+	DelayMinutes         uint `env:"NOTIFICATION_DAILY_SUMMARY_DELAY_MINUTES" envDefault:"15"` // how long the queue must stay inactive, so that a short outage of the API doesn't end the day
+	ComparedWeeks        int  `env:"NOTIFICATION_DAILY_SUMMARY_COMPARED_WEEKS" envDefault:"4"` // the day is compared with the same weekday of this many previous weeks
+	Chart                bool `env:"NOTIFICATION_DAILY_SUMMARY_CHART" envDefault:"false"`      // the summary is the caption of a chart of the tickets left during the compared days
 }
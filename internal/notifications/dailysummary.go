@@ -0,0 +1,189 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/charts"
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	dailySummaryRedisKeyPrefix = "telegram:daily_summary:"
+	dailySummaryPostedTtl      = 7 * 24 * time.Hour
+
+	dailySummaryTemplate    = "daily_summary"
+	dailySummaryDayLayout   = "2006-01-02"
+	dailySummaryChartLayout = "02.01"
+)
+
+// DailySummaryStore remembers the days whose summary was posted.
+type DailySummaryStore interface {
+	Posted(ctx context.Context, key string) (bool, error)
+	MarkPosted(ctx context.Context, key string) error
+}
+
+type RedisDailySummaryStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisDailySummaryStore(redisClient *redis.Client) *RedisDailySummaryStore {
+	return &RedisDailySummaryStore{redisClient: redisClient}
+}
+
+func (s *RedisDailySummaryStore) Posted(ctx context.Context, key string) (bool, error) {
+	exists, err := s.redisClient.Exists(ctx, dailySummaryRedisKeyPrefix+key).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to get daily summary from Redis: %w", err)
+	}
+	return exists > 0, nil
+}
+
+func (s *RedisDailySummaryStore) MarkPosted(ctx context.Context, key string) error {
+	if err := s.redisClient.Set(ctx, dailySummaryRedisKeyPrefix+key, time.Now().UTC().Format(time.RFC3339), dailySummaryPostedTtl).Err(); err != nil {
+		return fmt.Errorf("failed to save daily summary to Redis: %w", err)
+	}
+	return nil
+}
+
+// DailySummaryReporter posts the summary of the day to the channel once the queue became inactive for the day: when it opened,
+// how many tickets there were, when they ran out, compared with the same weekday of the previous weeks. If enabled, the
+// summary is the caption of a chart of the tickets left during these days.
+// The summary is built from the queue history, on its own schedule, so it doesn't depend on the checks of the monitor
+// and is still posted after a restart. It's posted through the notifiers of the channel, so it goes to the forum topic
+// of the queue and, translated, to the language channels, like the queue events.
+type DailySummaryReporter struct {
+	cfg      *DailySummaryConfig
+	log      *logger.Logger
+	notifier PostNotifier
+	history  queuehistory.Store
+	store    DailySummaryStore
+	chatID   string
+	queueID  int
+}
+
+func NewDailySummaryReporter(cfg *DailySummaryConfig, log *logger.Logger, notifier PostNotifier, history queuehistory.Store, store DailySummaryStore, chatID string, queueID int) *DailySummaryReporter {
+	return &DailySummaryReporter{
+		cfg:      cfg,
+		log:      log,
+		notifier: notifier,
+		history:  history,
+		store:    store,
+		chatID:   chatID,
+		queueID:  queueID,
+	}
+}
+
+func (r *DailySummaryReporter) Run(ctx context.Context, done chan<- bool) {
+	r.log.Info("Started daily summary reporter")
+	ticker := time.NewTicker(time.Duration(r.cfg.CheckIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopped daily summary reporter")
+			done <- true
+			return
+		case <-ticker.C:
+			if err := r.PostDue(ctx, time.Now()); err != nil {
+				r.log.Error("Error during posting the daily summary", err)
+			}
+		}
+	}
+}
+
+// PostDue posts the summary of the day of now, if the queue has been inactive for DailySummaryConfig.DelayMinutes after
+// it was active, and the summary was not posted yet.
+func (r *DailySummaryReporter) PostDue(ctx context.Context, now time.Time) error {
+	day := now.In(r.history.Location())
+	observations, err := r.history.Day(ctx, r.queueID, day)
+	if err != nil {
+		return err
+	}
+
+	summary := queuehistory.Summarize(observations)
+	if !summary.Finished || now.Sub(observations[len(observations)-1].At) < time.Duration(r.cfg.DelayMinutes)*time.Minute {
+		return nil
+	}
+
+	key := fmt.Sprintf("%d:%s", r.queueID, day.Format(dailySummaryDayLayout))
+	posted, err := r.store.Posted(ctx, key)
+	if err != nil || posted {
+		return err
+	}
+
+	// the comparison is a nice-to-have, so the days which can't be read are skipped
+	previous, err := queuehistory.PreviousWeeks(ctx, r.history, r.queueID, day, r.cfg.ComparedWeeks)
+	if err != nil {
+		r.log.Error("Failed to get the queue history of the compared weeks", err)
+	}
+	data := r.buildData(day, observations, summary, previous)
+	post := &ChannelPost{
+		ChatID:   r.chatID,
+		QueueID:  r.queueID,
+		Language: i18n.DefaultLanguage,
+		Render: func(language string) string {
+			return templates.Default.Execute(dailySummaryTemplate, language, data)
+		},
+		Silent: true, // it's not worth a sound at the end of the day
+	}
+	if r.cfg.Chart {
+		post.Photo = r.drawChart(day, observations, previous)
+	}
+	if err := r.notifier.Post(ctx, post); err != nil {
+		return err
+	}
+
+	r.log.Info("Daily summary posted", "chatId", r.chatID, "day", key)
+	return r.store.MarkPosted(ctx, key)
+}
+
+// drawChart returns the chart of the tickets left during the day and the compared days. The chart is a nice-to-have,
+// so nil is returned if it can't be drawn, and the summary is posted as text.
+func (r *DailySummaryReporter) drawChart(day time.Time, observations []*queuehistory.Observation, previous []*queuehistory.Day) []byte {
+	series := charts.ComparedSeries(dailySummaryChartLayout, &queuehistory.Day{Date: day, Observations: observations}, previous)
+	chart, err := charts.RenderPNG(series)
+	if err != nil {
+		r.log.Error("Failed to draw the chart of the daily summary", err)
+		return nil
+	}
+	return chart
+}
+
+func (r *DailySummaryReporter) buildData(day time.Time, observations []*queuehistory.Observation, summary *queuehistory.DaySummary, previous []*queuehistory.Day) *templates.DailySummaryData {
+	data := &templates.DailySummaryData{
+		QueueName:        EscapeHTML(observations[len(observations)-1].QueueName),
+		Date:             day,
+		Opened:           summary.Opened,
+		OpenedAt:         summary.OpenedAt.In(day.Location()),
+		TicketsAtOpening: summary.TicketsAtOpening,
+		EnabledMinutes:   int(summary.EnabledDuration.Round(time.Minute).Minutes()),
+		Reopenings:       summary.Reopenings,
+	}
+	if !summary.SoldOutAt.IsZero() {
+		data.SoldOutAt = summary.SoldOutAt.In(day.Location())
+	}
+
+	summaries := make([]*queuehistory.DaySummary, 0, len(previous))
+	for _, d := range previous {
+		summaries = append(summaries, queuehistory.Summarize(d.Observations))
+	}
+
+	if avg := queuehistory.AverageOf(summaries); avg != nil {
+		midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		data.Average = &templates.SummaryAverageData{
+			Days:             avg.Days,
+			OpenedAt:         midnight.Add(avg.OpenedAt),
+			TicketsAtOpening: avg.TicketsAtOpening,
+			EnabledMinutes:   int(avg.EnabledDuration.Minutes()),
+		}
+	}
+	return data
+}
@@ -0,0 +1,205 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+)
+
+type inMemoryQueueHistory struct {
+	days map[string][]*queuehistory.Observation // by local date
+}
+
+func newInMemoryQueueHistory(observations ...*queuehistory.Observation) *inMemoryQueueHistory {
+	history := &inMemoryQueueHistory{days: make(map[string][]*queuehistory.Observation)}
+	for _, obs := range observations {
+		history.Append(context.Background(), 24, obs)
+	}
+	return history
+}
+
+func (h *inMemoryQueueHistory) Append(ctx context.Context, queueID int, obs *queuehistory.Observation) error {
+	day := templates.Default.LocalTime(obs.At).Format(dailySummaryDayLayout)
+	h.days[day] = append(h.days[day], obs)
+	return nil
+}
+
+// Day returns the observations in the local time, like queuehistory.RedisStore.
+func (h *inMemoryQueueHistory) Day(ctx context.Context, queueID int, day time.Time) ([]*queuehistory.Observation, error) {
+	var observations []*queuehistory.Observation
+	for _, obs := range h.days[templates.Default.LocalTime(day).Format(dailySummaryDayLayout)] {
+		local := *obs
+		local.At = templates.Default.LocalTime(obs.At)
+		observations = append(observations, &local)
+	}
+	return observations, nil
+}
+
+func (h *inMemoryQueueHistory) Location() *time.Location {
+	return templates.Default.Location()
+}
+
+type inMemoryDailySummaryStore struct {
+	posted map[string]bool
+}
+
+func (s *inMemoryDailySummaryStore) Posted(ctx context.Context, key string) (bool, error) {
+	return s.posted[key], nil
+}
+
+func (s *inMemoryDailySummaryStore) MarkPosted(ctx context.Context, key string) error {
+	s.posted[key] = true
+	return nil
+}
+
+func observed(at time.Time, active, enabled bool, ticketsLeft int) *queuehistory.Observation {
+	return &queuehistory.Observation{At: at, QueueName: "Odbiór karty", Active: active, Enabled: enabled, TicketsLeft: ticketsLeft}
+}
+
+func newTestDailySummaryReporter(t *testing.T, api *mockTelegramBotApi, history queuehistory.Store, store DailySummaryStore) *DailySummaryReporter {
+	server := api.start(t)
+	telegramCfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+	}
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+
+	cfg := &DailySummaryConfig{Enabled: true, CheckIntervalSeconds: 60, DelayMinutes: 15, ComparedWeeks: 4}
+	return NewDailySummaryReporter(cfg, log, NewTelegramNotifier(telegramCfg, log, &http.Client{}), history, store, "@channel", 24)
+}
+
+func TestDailySummaryReporterPostDue_WhenQueueFinishedTheDay_PostsSummaryComparedWithSameWeekdayOnce(t *testing.T) {
+	// Arrange
+	today := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC) // a Monday, Warsaw is UTC+1
+	lastWeek := today.AddDate(0, 0, -7)
+	history := newInMemoryQueueHistory(
+		observed(lastWeek.Add(7*time.Hour), true, true, 100),
+		observed(lastWeek.Add(8*time.Hour), true, false, 0),
+		observed(lastWeek.Add(14*time.Hour), false, false, 0),
+		observed(today.Add(6*time.Hour), true, false, 0),
+		observed(today.Add(7*time.Hour+10*time.Minute), true, true, 120),
+		observed(today.Add(8*time.Hour+10*time.Minute), true, false, 0),
+		observed(today.Add(9*time.Hour), true, true, 4),
+		observed(today.Add(9*time.Hour+5*time.Minute), true, false, 0),
+		observed(today.Add(14*time.Hour), false, false, 0),
+	)
+	store := &inMemoryDailySummaryStore{posted: map[string]bool{}}
+	api := &mockTelegramBotApi{}
+	sut := newTestDailySummaryReporter(t, api, history, store)
+
+	// Act
+	firstErr := sut.PostDue(context.Background(), today.Add(14*time.Hour+30*time.Minute))
+	secondErr := sut.PostDue(context.Background(), today.Add(14*time.Hour+31*time.Minute))
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", firstErr, secondErr)
+	}
+	if len(api.calls) != 1 {
+		t.Fatalf("Expected the summary to be posted once, but got %d calls", len(api.calls))
+	}
+
+	body := api.calls[0].body
+	if body["chat_id"] != "@channel" || body["disable_notification"] != true {
+		t.Errorf("Expected a silent message to the channel, but got %v", body)
+	}
+	text, _ := body["text"].(string)
+	expectedLines := []string{
+		"📋 Podsumowanie dnia — <b>Odbiór karty</b>, 09.03.2026",
+		"🔔 Otwarcie o <b>08:10</b> (zwykle 08:00)",
+		"🎟️ <b>120</b> biletów na otwarciu (zwykle 100)",
+		"🏁 Bilety skończyły się o <b>09:10</b>",
+		"⏱ Bilety dostępne przez: 65 minut (zwykle 60 minut)",
+		"🔁 Ponowne otwarcia: <b>1</b>",
+		"ℹ️ Porównanie z tym samym dniem tygodnia z 1 poprzedniego tygodnia",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected the summary to contain %q, but got:\n%s", line, text)
+		}
+	}
+	if !store.posted["24:2026-03-09"] {
+		t.Errorf("Expected the day to be marked as posted, got %v", store.posted)
+	}
+}
+
+func TestDailySummaryReporterPostDue_WhenChartIsEnabled_PostsSummaryAsCaptionOfChart(t *testing.T) {
+	// Arrange
+	today := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	history := newInMemoryQueueHistory(
+		observed(today.AddDate(0, 0, -7).Add(7*time.Hour), true, true, 100),
+		observed(today.AddDate(0, 0, -7).Add(8*time.Hour), false, false, 0),
+		observed(today.Add(7*time.Hour), true, true, 120),
+		observed(today.Add(8*time.Hour), true, false, 0),
+		observed(today.Add(14*time.Hour), false, false, 0),
+	)
+	api := &mockTelegramBotApi{}
+	sut := newTestDailySummaryReporter(t, api, history, &inMemoryDailySummaryStore{posted: map[string]bool{}})
+	sut.cfg.Chart = true
+
+	// Act
+	err := sut.PostDue(context.Background(), today.Add(14*time.Hour+30*time.Minute))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if fmt.Sprint(api.methods()) != "[sendPhoto]" {
+		t.Fatalf("Expected the summary to be posted with a chart, but got calls %v", api.methods())
+	}
+	body := api.calls[0].body
+	if body["disable_notification"] != "true" {
+		t.Errorf("Expected a silent photo, but got %v", body["disable_notification"])
+	}
+	if caption, _ := body["caption"].(string); !strings.HasPrefix(caption, "📋 Podsumowanie dnia — <b>Odbiór karty</b>, 09.03.2026") {
+		t.Errorf("Expected the summary as the caption, but got %q", caption)
+	}
+	if photo, _ := body["photo"].(string); !strings.HasPrefix(photo, "\x89PNG") {
+		t.Errorf("Expected a PNG chart, but got %d bytes", len(photo))
+	}
+}
+
+func TestDailySummaryReporterPostDue_WhenDayIsNotFinished_DoesNotPost(t *testing.T) {
+	today := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name         string
+		observations []*queuehistory.Observation
+	}{
+		{"Nothing observed", nil},
+		{"Queue is still active", []*queuehistory.Observation{observed(today.Add(7*time.Hour), true, true, 100)}},
+		{"Queue became inactive moments ago", []*queuehistory.Observation{
+			observed(today.Add(7*time.Hour), true, true, 100),
+			observed(today.Add(14*time.Hour+20*time.Minute), false, false, 0),
+		}},
+		{"Queue was not active today", []*queuehistory.Observation{observed(today.Add(6*time.Hour), false, false, 0)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			api := &mockTelegramBotApi{}
+			sut := newTestDailySummaryReporter(t, api, newInMemoryQueueHistory(tc.observations...), &inMemoryDailySummaryStore{posted: map[string]bool{}})
+
+			// Act
+			err := sut.PostDue(context.Background(), today.Add(14*time.Hour+30*time.Minute))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if len(api.calls) != 0 {
+				t.Errorf("Expected no summary, but got calls %v", api.methods())
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const deliveryLogRedisKey = "webhook:deliveries" // list of delivery attempt JSONs, oldest first
+
+// DeliveryAttempt is a single attempt to deliver a webhook event.
+type DeliveryAttempt struct {
+	DeliveryID string        `json:"delivery_id"`
+	Url        string        `json:"url"`
+	Attempt    uint          `json:"attempt"`
+	StatusCode int           `json:"status_code"`     // 0 if no response was received
+	Error      string        `json:"error,omitempty"` // empty if the attempt succeeded
+	Duration   time.Duration `json:"duration"`
+	AttemptAt  time.Time     `json:"attempt_at"`
+}
+
+// DeliveryLog keeps the latest webhook delivery attempts, so they can be inspected with the "webhook deliveries" command.
+type DeliveryLog interface {
+	Add(ctx context.Context, attempt *DeliveryAttempt) error
+	Entries(ctx context.Context) ([]*DeliveryAttempt, error)
+}
+
+// RedisDeliveryLog keeps the latest attempts in a capped Redis list. When the log is full, the oldest attempts are dropped.
+type RedisDeliveryLog struct {
+	redisClient *redis.Client
+	size        int64
+}
+
+func NewRedisDeliveryLog(redisClient *redis.Client, size int) *RedisDeliveryLog {
+	return &RedisDeliveryLog{redisClient: redisClient, size: int64(max(size, 1))}
+}
+
+func (l *RedisDeliveryLog) Add(ctx context.Context, attempt *DeliveryAttempt) error {
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery attempt: %w", err)
+	}
+
+	_, err = l.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, deliveryLogRedisKey, data)
+		pipe.LTrim(ctx, deliveryLogRedisKey, -l.size, -1)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add delivery attempt: %w", err)
+	}
+	return nil
+}
+
+// Entries returns the logged attempts from the oldest to the newest.
+func (l *RedisDeliveryLog) Entries(ctx context.Context) ([]*DeliveryAttempt, error) {
+	values, err := l.redisClient.LRange(ctx, deliveryLogRedisKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery attempts: %w", err)
+	}
+
+	attempts := make([]*DeliveryAttempt, 0, len(values))
+	for _, value := range values {
+		var attempt DeliveryAttempt
+		if err := json.Unmarshal([]byte(value), &attempt); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal delivery attempt: %w", err)
+		}
+		attempts = append(attempts, &attempt)
+	}
+	return attempts, nil
+}
@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRedisDeliveryLog_WhenFull_KeepsLatestAttemptsInOrder(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisDeliveryLog(initRedisContainer(ctx, t), 3)
+
+	// Act
+	for i := 1; i <= 5; i++ {
+		if err := sut.Add(ctx, &DeliveryAttempt{DeliveryID: "d1", Attempt: uint(i), StatusCode: 503}); err != nil {
+			t.Fatalf("Failed to add delivery attempt: %v", err)
+		}
+	}
+	entries, err := sut.Entries(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Attempt != 3 || entries[1].Attempt != 4 || entries[2].Attempt != 5 {
+		t.Errorf("Expected attempts [3 4 5], but got %+v", entries)
+	}
+	if entries[0].DeliveryID != "d1" || entries[0].StatusCode != 503 {
+		t.Errorf("Expected the attempt details to be kept, but got %+v", entries[0])
+	}
+}
@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+// directMessageQueueSize is the number of jobs waiting for the worker, above it new jobs are dropped.
+const directMessageQueueSize = 100
+
+// DirectMessageQueue sends the direct messages to the bot users in the background, one job at a time, so messaging many
+// users doesn't delay the checks of the queue. The jobs share the Telegram notifier, and so the rate limits of the bot.
+type DirectMessageQueue struct {
+	log  *logger.Logger
+	jobs chan func(ctx context.Context)
+}
+
+func NewDirectMessageQueue(log *logger.Logger) *DirectMessageQueue {
+	return &DirectMessageQueue{
+		log:  log,
+		jobs: make(chan func(ctx context.Context), directMessageQueueSize),
+	}
+}
+
+// Enqueue schedules the job and reports whether it was accepted: it's dropped if the worker is too far behind.
+func (q *DirectMessageQueue) Enqueue(name string, job func(ctx context.Context)) bool {
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		q.log.Warn("Direct message queue is full, dropping job", "job", name)
+		return false
+	}
+}
+
+// Run runs the jobs in order until the context is cancelled. The jobs waiting at that moment are dropped.
+func (q *DirectMessageQueue) Run(ctx context.Context, done chan<- bool) {
+	q.log.Info("Started direct message worker")
+	for {
+		select {
+		case <-ctx.Done():
+			q.log.Info("Stopped direct message worker", "dropped", len(q.jobs))
+			done <- true
+			return
+		case job := <-q.jobs:
+			job(ctx)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/avast/retry-go/v4"
+)
+
+const (
+	EmailModeImmediate = "immediate"
+	EmailModeDigest    = "digest"
+
+	EmailTlsStartTls = "starttls"
+	EmailTlsImplicit = "implicit"
+	EmailTlsNone     = "none"
+
+	EmailAuthPlain = "plain"
+	EmailAuthLogin = "login"
+	EmailAuthNone  = "none"
+
+	emailDigestSubjectTemplate = "Kolejka DUW: podsumowanie zmian (%d)"
+	emailHtmlTemplate          = "<!DOCTYPE html>\n<html><body style=\"font-family:sans-serif\">\n%s</body></html>\n"
+	emailEventTimeLayout       = "15:04:05"
+)
+
+// EmailNotifier sends queue notifications as multipart (text and HTML) emails over SMTP.
+// In immediate mode every notification is sent as a separate email. In digest mode notifications are batched
+// and sent as a single email once the digest window passes since the first notification in the batch.
+type EmailNotifier struct {
+	cfg       *EmailConfig
+	log       *logger.Logger
+	tlsConfig *tls.Config
+
+	mu            sync.Mutex
+	pending       []*QueueEvent
+	flushTimer    *time.Timer
+	failedDigests uint // consecutive failed digests, whose events are still pending
+}
+
+func NewEmailNotifier(cfg *EmailConfig, log *logger.Logger) *EmailNotifier {
+	return &EmailNotifier{
+		cfg:       cfg,
+		log:       log,
+		tlsConfig: &tls.Config{ServerName: cfg.SmtpHost},
+	}
+}
+
+func (e *EmailNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return e.Notify(ctx, &QueueEvent{Text: text, OccurredAt: time.Now().UTC()})
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	if e.cfg.Mode != EmailModeDigest {
+		subject, _, _ := strings.Cut(stripTelegramHTML(event.Text), "\n")
+		return e.sendWithRetries(ctx, subject, []*QueueEvent{event})
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pending = append(e.pending, event)
+	if e.flushTimer == nil {
+		e.flushTimer = time.AfterFunc(time.Duration(e.cfg.DigestWindowSeconds)*time.Second, e.flushDigest)
+	}
+
+	return nil
+}
+
+// Close sends the pending digest, if any, so that notifications are not lost on shutdown.
+func (e *EmailNotifier) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if e.flushTimer != nil {
+		e.flushTimer.Stop()
+		e.flushTimer = nil
+	}
+	events := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	return e.sendWithRetries(ctx, fmt.Sprintf(emailDigestSubjectTemplate, len(events)), events)
+}
+
+func (e *EmailNotifier) flushDigest() {
+	e.mu.Lock()
+	events := e.pending
+	e.pending = nil
+	e.flushTimer = nil
+	e.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.cfg.RequestTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	err := e.sendWithRetries(ctx, fmt.Sprintf(emailDigestSubjectTemplate, len(events)), events)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil {
+		e.failedDigests = 0
+		return
+	}
+
+	// the events are dropped after a few attempts, so a broken SMTP server doesn't grow the digest forever, unless
+	// there is no limit
+	e.failedDigests++
+	if e.cfg.MaxDigestAttempts > 0 && e.failedDigests >= e.cfg.MaxDigestAttempts {
+		e.log.Error("Failed to send email digest, dropping its events", err, "attempts", e.failedDigests, "events", len(events))
+		e.failedDigests = 0
+		return
+	}
+
+	e.log.Error("Failed to send email digest, events will be included in the next digest", err, "events", len(events))
+	e.pending = append(events, e.pending...)
+	if e.flushTimer == nil {
+		e.flushTimer = time.AfterFunc(time.Duration(e.cfg.DigestWindowSeconds)*time.Second, e.flushDigest)
+	}
+}
+
+func (e *EmailNotifier) sendWithRetries(ctx context.Context, subject string, events []*QueueEvent) error {
+	msg, err := buildEmailMessage(e.cfg.From, e.cfg.To, subject, events)
+	if err != nil {
+		return fmt.Errorf("failed to build email message: %w", err)
+	}
+
+	requestTimeout := time.Duration(e.cfg.RequestTimeoutSeconds) * time.Second
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	return retry.Do(
+		func() error {
+			if err := e.send(timeoutCtx, msg); err != nil {
+				return fmt.Errorf("failed to send email via SMTP: %w", err)
+			}
+
+			e.log.Info("Email sent successfully.", "recipients", len(e.cfg.To), "events", len(events))
+			return nil
+		},
+		retry.Attempts(e.cfg.MaxRetryAttempts),
+		retry.Delay(time.Duration(e.cfg.RetryDelayMs)*time.Millisecond),
+		retry.DelayType(retry.FixedDelay),
+		retry.Context(timeoutCtx),
+	)
+}
+
+func (e *EmailNotifier) send(ctx context.Context, msg []byte) error {
+	addr := net.JoinHostPort(e.cfg.SmtpHost, strconv.Itoa(e.cfg.SmtpPort))
+
+	var conn net.Conn
+	var err error
+	if e.cfg.TlsMode == EmailTlsImplicit {
+		dialer := &tls.Dialer{Config: e.tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, e.cfg.SmtpHost)
+	if err != nil {
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if e.cfg.TlsMode == EmailTlsStartTls {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := c.StartTLS(e.tlsConfig); err != nil {
+			return fmt.Errorf("failed to upgrade SMTP connection using STARTTLS: %w", err)
+		}
+	}
+
+	if auth := e.auth(); auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.cfg.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, to := range e.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to add recipient: %w", err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish message data: %w", err)
+	}
+
+	return c.Quit()
+}
+
+func (e *EmailNotifier) auth() smtp.Auth {
+	switch e.cfg.AuthMechanism {
+	case EmailAuthPlain:
+		return smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.SmtpHost)
+	case EmailAuthLogin:
+		return &loginAuth{username: e.cfg.Username, password: e.cfg.Password, host: e.cfg.SmtpHost}
+	default:
+		return nil
+	}
+}
+
+// buildEmailMessage creates a multipart/alternative message with plain text and HTML versions of the events.
+func buildEmailMessage(from string, to []string, subject string, events []*QueueEvent) ([]byte, error) {
+	var textBody, htmlBody strings.Builder
+	for i, event := range events {
+		if len(events) > 1 {
+			if i > 0 {
+				textBody.WriteString("\n")
+			}
+			fmt.Fprintf(&textBody, "[%s]\n", event.OccurredAt.Format(emailEventTimeLayout))
+			fmt.Fprintf(&htmlBody, "<p style=\"color:#888\">%s</p>\n", event.OccurredAt.Format(emailEventTimeLayout))
+		}
+		textBody.WriteString(stripTelegramHTML(event.Text) + "\n")
+		htmlBody.WriteString("<p>" + strings.ReplaceAll(event.Text, "\n", "<br>\n") + "</p>\n")
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary)
+
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", textBody.String()},
+		{"text/html; charset=utf-8", fmt.Sprintf(emailHtmlTemplate, htmlBody.String())},
+	}
+	for _, part := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "duw-" + hex.EncodeToString(b), nil
+}
+
+// loginAuth implements the non-standard, but widely used AUTH LOGIN mechanism which is not supported by net/smtp.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// same protection as in smtp.PlainAuth: never send credentials over an unencrypted connection to a remote host
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
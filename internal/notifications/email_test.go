@@ -0,0 +1,440 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+// smtpStandIn is a minimal in-process SMTP server supporting STARTTLS, implicit TLS and PLAIN/LOGIN authentication.
+type smtpStandIn struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	implicit  bool
+
+	mu       sync.Mutex
+	messages []receivedEmail
+}
+
+type receivedEmail struct {
+	from       string
+	to         []string
+	data       string
+	authMethod string
+	username   string
+	password   string
+	tls        bool
+}
+
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// newSmtpStandIn starts the stand-in. If cert is nil, the server supports plain connections only.
+func newSmtpStandIn(t *testing.T, cert *tls.Certificate, implicit bool) *smtpStandIn {
+	var tlsConfig *tls.Config
+	if cert != nil {
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{*cert}}
+	}
+
+	var listener net.Listener
+	var err error
+	if implicit {
+		listener, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatalf("Failed to start SMTP stand-in: %v", err)
+	}
+
+	s := &smtpStandIn{listener: listener, tlsConfig: tlsConfig, implicit: implicit}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) received() []receivedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedEmail(nil), s.messages...)
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+
+	email := receivedEmail{tls: s.implicit}
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			rw.WriteString(line + "\r\n")
+		}
+		rw.Flush()
+	}
+	readLine := func() string {
+		line, _ := rw.ReadString('\n')
+		return strings.TrimRight(line, "\r\n")
+	}
+	decode := func(s string) string {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line := readLine()
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			if email.tls || s.tlsConfig == nil {
+				reply("250-localhost", "250 AUTH PLAIN LOGIN")
+			} else {
+				reply("250-localhost", "250-STARTTLS", "250 AUTH PLAIN LOGIN")
+			}
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+			email.tls = true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			email.authMethod = mechanism
+			switch mechanism {
+			case "PLAIN":
+				parts := strings.Split(decode(initial), "\x00")
+				if len(parts) == 3 {
+					email.username, email.password = parts[1], parts[2]
+				}
+			case "LOGIN":
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				email.username = decode(readLine())
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				email.password = decode(readLine())
+			}
+			reply("235 Authentication successful")
+		case "MAIL":
+			email.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			email.to = append(email.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l := readLine()
+				if l == "." {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, ".") + "\r\n")
+			}
+			email.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, email)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		case "":
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func newTestEmailNotifier(port int, tlsMode, authMechanism, mode string, pool *x509.CertPool) *EmailNotifier {
+	cfg := &EmailConfig{
+		SmtpHost:              "127.0.0.1",
+		SmtpPort:              port,
+		TlsMode:               tlsMode,
+		AuthMechanism:         authMechanism,
+		Username:              "monitor@example.com",
+		Password:              "secret",
+		From:                  "monitor@example.com",
+		To:                    []string{"first@example.com", "second@example.com"},
+		Mode:                  mode,
+		DigestWindowSeconds:   3600,
+		MaxDigestAttempts:     2,
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          100,
+		RequestTimeoutSeconds: 2,
+	}
+
+	sut := NewEmailNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}))
+	sut.tlsConfig.RootCAs = pool
+	return sut
+}
+
+// parseEmailParts returns the decoded subject and the bodies of multipart/alternative parts keyed by content type.
+func parseEmailParts(t *testing.T, data string) (string, map[string]string) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse email: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("Failed to decode subject: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative content type, got '%s' (%v)", mediaType, err)
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read email part: %v", err)
+		}
+		body, _ := io.ReadAll(part) // quoted-printable is decoded by multipart reader
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+
+	return subject, parts
+}
+
+func TestEmailNotify_WhenImmediateMode_SendsMultipartEmailForEveryEvent(t *testing.T) {
+	testCases := []struct {
+		name          string
+		implicitTls   bool
+		tlsMode       string
+		authMechanism string
+		expectedAuth  string
+	}{
+		{"STARTTLS with PLAIN auth", false, EmailTlsStartTls, EmailAuthPlain, "PLAIN"},
+		{"STARTTLS with LOGIN auth", false, EmailTlsStartTls, EmailAuthLogin, "LOGIN"},
+		{"Implicit TLS with LOGIN auth", true, EmailTlsImplicit, EmailAuthLogin, "LOGIN"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			cert, pool := newTestCertificate(t)
+			server := newSmtpStandIn(t, &cert, tc.implicitTls)
+			sut := newTestEmailNotifier(server.port(), tc.tlsMode, tc.authMechanism, EmailModeImmediate, pool)
+
+			event := &QueueEvent{
+				Type:       EventQueueOpened,
+				Text:       "🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!\n🧾 Pozostało biletów: <b>10</b>",
+				OccurredAt: time.Date(2025, 3, 3, 9, 15, 0, 0, time.UTC),
+			}
+
+			// Act
+			err := sut.Notify(context.Background(), event)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected email to be sent, but got error: %v", err)
+			}
+
+			received := server.received()
+			if len(received) != 1 {
+				t.Fatalf("Expected 1 email, but got %d", len(received))
+			}
+
+			email := received[0]
+			if !email.tls {
+				t.Error("Expected email to be sent over TLS")
+			}
+			if email.authMethod != tc.expectedAuth || email.username != "monitor@example.com" || email.password != "secret" {
+				t.Errorf("Expected %s auth with configured credentials, but got %s %s/%s", tc.expectedAuth, email.authMethod, email.username, email.password)
+			}
+			if email.from != "monitor@example.com" || strings.Join(email.to, ",") != "first@example.com,second@example.com" {
+				t.Errorf("Unexpected envelope: from %s to %v", email.from, email.to)
+			}
+
+			subject, parts := parseEmailParts(t, email.data)
+			if subject != "🔔 Kolejka Odbiór karty jest teraz dostępna!" {
+				t.Errorf("Unexpected subject: '%s'", subject)
+			}
+			if parts["text/plain"] != "🔔 Kolejka Odbiór karty jest teraz dostępna!\n🧾 Pozostało biletów: 10\n" {
+				t.Errorf("Unexpected text part: '%s'", parts["text/plain"])
+			}
+			if !strings.Contains(parts["text/html"], "🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!<br>\n🧾 Pozostało biletów: <b>10</b>") {
+				t.Errorf("Unexpected HTML part: '%s'", parts["text/html"])
+			}
+		})
+	}
+}
+
+func TestEmailNotify_WhenDigestMode_BatchesEventsIntoSingleEmail(t *testing.T) {
+	// Arrange
+	cert, pool := newTestCertificate(t)
+	server := newSmtpStandIn(t, &cert, false)
+	sut := newTestEmailNotifier(server.port(), EmailTlsStartTls, EmailAuthPlain, EmailModeDigest, pool)
+
+	events := []*QueueEvent{
+		{Type: EventQueueOpened, Text: "🔔 Kolejka <b>K</b> jest teraz dostępna!", OccurredAt: time.Date(2025, 3, 3, 9, 15, 0, 0, time.UTC)},
+		{Type: EventTicketsChanged, Text: "🧾 Pozostało biletów: <b>9</b>", OccurredAt: time.Date(2025, 3, 3, 9, 16, 0, 0, time.UTC)},
+	}
+
+	// Act
+	for _, event := range events {
+		if err := sut.Notify(context.Background(), event); err != nil {
+			t.Fatalf("Expected event to be queued, but got error: %v", err)
+		}
+	}
+	receivedBeforeFlush := len(server.received())
+	err := sut.Close(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected digest to be sent, but got error: %v", err)
+	}
+
+	if receivedBeforeFlush != 0 {
+		t.Errorf("Expected no email before digest window passes, but got %d", receivedBeforeFlush)
+	}
+
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 digest email, but got %d", len(received))
+	}
+
+	subject, parts := parseEmailParts(t, received[0].data)
+	if subject != "Kolejka DUW: podsumowanie zmian (2)" {
+		t.Errorf("Unexpected digest subject: '%s'", subject)
+	}
+
+	expectedText := "[09:15:00]\n🔔 Kolejka K jest teraz dostępna!\n\n[09:16:00]\n🧾 Pozostało biletów: 9\n"
+	if parts["text/plain"] != expectedText {
+		t.Errorf("Expected digest text:\n%s\nGot:\n%s", expectedText, parts["text/plain"])
+	}
+}
+
+func TestEmailNotify_WhenStartTlsIsNotSupported_ReturnsError(t *testing.T) {
+	// Arrange
+	server := newSmtpStandIn(t, nil, false)
+	sut := newTestEmailNotifier(server.port(), EmailTlsStartTls, EmailAuthPlain, EmailModeImmediate, nil)
+
+	// Act
+	err := sut.SendMessage(context.Background(), "", "Test message")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when server cannot be used with STARTTLS, but got nil")
+	}
+
+	if len(server.received()) != 0 {
+		t.Error("Expected no email to be delivered")
+	}
+}
+
+func TestEmailFlushDigest_WhenDigestKeepsFailing_DropsEventsAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	server := newSmtpStandIn(t, nil, false) // fails: STARTTLS is not supported
+	sut := newTestEmailNotifier(server.port(), EmailTlsStartTls, EmailAuthPlain, EmailModeDigest, nil)
+
+	// Act
+	_ = sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, Text: "first", OccurredAt: time.Now()})
+	sut.flushDigest()
+	pendingAfterFirstFailure := len(sut.pending)
+
+	_ = sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged, Text: "second", OccurredAt: time.Now()})
+	sut.flushDigest()
+	pendingAfterLastFailure := len(sut.pending)
+
+	// Assert
+	if pendingAfterFirstFailure != 1 {
+		t.Errorf("Expected the failed event to be kept for the next digest, but got %d pending", pendingAfterFirstFailure)
+	}
+	if pendingAfterLastFailure != 0 {
+		t.Errorf("Expected the events to be dropped after the last attempt, but got %d pending", pendingAfterLastFailure)
+	}
+	if err := sut.Close(context.Background()); err != nil {
+		t.Errorf("Expected nothing to be sent on close, but got: %v", err)
+	}
+}
+
+func TestEmailFlushDigest_WhenThereIsNoAttemptsLimit_KeepsEvents(t *testing.T) {
+	// Arrange
+	server := newSmtpStandIn(t, nil, false) // fails: STARTTLS is not supported
+	sut := newTestEmailNotifier(server.port(), EmailTlsStartTls, EmailAuthPlain, EmailModeDigest, nil)
+	sut.cfg.MaxDigestAttempts = 0
+
+	// Act
+	_ = sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, Text: "first", OccurredAt: time.Now()})
+	sut.flushDigest()
+	_ = sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged, Text: "second", OccurredAt: time.Now()})
+	sut.flushDigest()
+	_ = sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged, Text: "third", OccurredAt: time.Now()})
+	sut.flushDigest()
+
+	// Assert
+	sut.mu.Lock()
+	pending := len(sut.pending)
+	sut.mu.Unlock()
+	if pending != 3 {
+		t.Errorf("Expected the failed events to be kept for the next digest, but got %d pending", pending)
+	}
+}
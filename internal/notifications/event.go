@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"context"
+	"time"
+)
+
+// EventType describes what happened to the monitored queue.
+type EventType string
+
+const (
+	EventQueueOpened      EventType = "queue_opened"      // queue became active and tickets can be taken
+	EventQueueUnavailable EventType = "queue_unavailable" // queue is active, but no tickets can be taken
+	EventTicketsChanged   EventType = "tickets_changed"   // queue is still enabled, but the number of tickets left changed
+	EventTicketsLow       EventType = "tickets_low"       // the number of tickets left dropped to a low tickets threshold, once per threshold and opening
+	EventQueueInactive    EventType = "queue_inactive"    // queue is not active anymore (DUW off hours)
+)
+
+// QueueEvent is a structured queue status update.
+// Text contains the message pre-formatted for Telegram (HTML), so notifiers which don't need structured data can simply forward it.
+// Language is the language of Text, see RenderQueueMessage.
+type QueueEvent struct {
+	Type        EventType
+	ChatID      string
+	Text        string
+	Language    string
+	QueueID     int
+	QueueName   string
+	Active      bool
+	Enabled     bool
+	TicketValue string
+	TicketsLeft int
+	OccurredAt  time.Time
+
+	State              string    // state of the monitor after the event, e.g. "ActiveEnabled"
+	PreviousState      string    // state of the monitor before the event
+	PreviousStateSince time.Time // when the monitor entered the previous state, zero if unknown
+	OpenedAt           time.Time // when the queue started to accept tickets, zero if unknown
+	TicketsAtOpening   int       // tickets left when the queue opened
+
+	IdempotencyKey string // identifies the event across restarts and replicas, see NewIdempotencyKey
+}
+
+// BurnRate returns the number of tickets taken per minute since the queue opened, or zero if it's unknown.
+func (e *QueueEvent) BurnRate() float64 {
+	minutes := e.OccurredAt.Sub(e.OpenedAt).Minutes()
+	if e.OpenedAt.IsZero() || minutes <= 0 || e.TicketsAtOpening <= e.TicketsLeft {
+		return 0
+	}
+	return float64(e.TicketsAtOpening-e.TicketsLeft) / minutes
+}
+
+// Notifier sends pre-formatted text messages.
+type Notifier interface {
+	SendMessage(ctx context.Context, chatID, text string) error
+}
+
+// EventNotifier is implemented by notifiers which render queue status updates by themselves instead of relying on the pre-formatted text.
+type EventNotifier interface {
+	Notify(ctx context.Context, event *QueueEvent) error
+}
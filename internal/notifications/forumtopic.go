@@ -0,0 +1,151 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const forumTopicsRedisKeyPrefix = "telegram:forum_topics:"
+
+// ForumTopic routes the notifications of a queue to a topic of a forum supergroup.
+// It's configured as "queue|name" or "queue|name|thread ID". Without a thread ID, the topic created earlier is used,
+// or a new topic with the name is created at startup.
+type ForumTopic struct {
+	QueueID  int
+	Name     string
+	ThreadID int64 // 0 if not configured
+}
+
+func (t *ForumTopic) UnmarshalText(data []byte) error {
+	parts := strings.Split(strings.TrimSpace(string(data)), "|")
+	if len(parts) != 2 && len(parts) != 3 {
+		return fmt.Errorf("invalid forum topic %q, expected \"queue|name\" or \"queue|name|thread ID\"", data)
+	}
+
+	queueID, err := strconv.Atoi(parts[0])
+	if err != nil || queueID <= 0 {
+		return fmt.Errorf("invalid queue ID in forum topic %q", data)
+	}
+	if parts[1] == "" || len([]rune(parts[1])) > 128 {
+		return fmt.Errorf("forum topic name must have 1-128 characters in %q", data)
+	}
+
+	t.QueueID = queueID
+	t.Name = parts[1]
+	if len(parts) == 3 {
+		threadID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || threadID <= 0 {
+			return fmt.Errorf("invalid thread ID in forum topic %q", data)
+		}
+		t.ThreadID = threadID
+	}
+	return nil
+}
+
+// ForumTopicStore remembers the topics created by the bot, so they are not created again after a restart.
+type ForumTopicStore interface {
+	Get(ctx context.Context, chatID string, queueID int) (int64, error) // returns 0 if the topic was not created
+	Save(ctx context.Context, chatID string, queueID int, threadID int64) error
+}
+
+type RedisForumTopicStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisForumTopicStore(redisClient *redis.Client) *RedisForumTopicStore {
+	return &RedisForumTopicStore{redisClient: redisClient}
+}
+
+func (s *RedisForumTopicStore) Get(ctx context.Context, chatID string, queueID int) (int64, error) {
+	threadID, err := s.redisClient.HGet(ctx, forumTopicsRedisKeyPrefix+chatID, strconv.Itoa(queueID)).Int64()
+	switch {
+	case err == redis.Nil:
+		return 0, nil
+	case err != nil:
+		return 0, fmt.Errorf("failed to get forum topic from Redis: %w", err)
+	}
+	return threadID, nil
+}
+
+func (s *RedisForumTopicStore) Save(ctx context.Context, chatID string, queueID int, threadID int64) error {
+	if err := s.redisClient.HSet(ctx, forumTopicsRedisKeyPrefix+chatID, strconv.Itoa(queueID), threadID).Err(); err != nil {
+		return fmt.Errorf("failed to save forum topic to Redis: %w", err)
+	}
+	return nil
+}
+
+type CreateForumTopicRequest struct {
+	ChatID string `json:"chat_id"`
+	Name   string `json:"name"`
+}
+
+type telegramForumTopic struct {
+	MessageThreadID int64 `json:"message_thread_id"`
+}
+
+// CreateForumTopic creates a topic in the forum supergroup and returns its thread ID. The bot needs the can_manage_topics right.
+func (s *TelegramNotifier) CreateForumTopic(ctx context.Context, chatID, name string) (int64, error) {
+	reqBody := CreateForumTopicRequest{
+		ChatID: chatID,
+		Name:   name,
+	}
+
+	var topic telegramForumTopic
+	if err := s.callWithRetries(ctx, "createForumTopic", chatID, reqBody, &topic); err != nil {
+		return 0, err
+	}
+	return topic.MessageThreadID, nil
+}
+
+// ResolveForumTopics finds the thread of every configured forum topic of the chat, creating the missing topics if enabled,
+// so that the notifications of each queue are sent to its topic. It must be called before sending any notifications.
+// A topic which can't be resolved doesn't prevent the others from being used: the notifications of its queue are sent
+// to the chat outside of the topics, and the error is returned.
+func (s *TelegramNotifier) ResolveForumTopics(ctx context.Context, chatID string, store ForumTopicStore) error {
+	var errs []error
+	threads := make(map[int]int64, len(s.cfg.ForumTopics))
+	for _, topic := range s.cfg.ForumTopics {
+		threadID, err := s.resolveForumTopic(ctx, chatID, topic, store)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve forum topic %q of queue %d: %w", topic.Name, topic.QueueID, err))
+			continue
+		}
+		threads[topic.QueueID] = threadID
+	}
+
+	s.topicChatID = chatID
+	s.topicThreads = threads
+	return errors.Join(errs...)
+}
+
+func (s *TelegramNotifier) resolveForumTopic(ctx context.Context, chatID string, topic ForumTopic, store ForumTopicStore) (int64, error) {
+	if topic.ThreadID != 0 {
+		return topic.ThreadID, nil
+	}
+
+	threadID, err := store.Get(ctx, chatID, topic.QueueID)
+	if err != nil || threadID != 0 {
+		return threadID, err
+	}
+
+	if !s.cfg.CreateForumTopics {
+		return 0, fmt.Errorf("topic has no thread ID and creating topics is disabled")
+	}
+
+	threadID, err = s.CreateForumTopic(ctx, chatID, topic.Name)
+	if err != nil {
+		return 0, err
+	}
+	s.log.Info("Created forum topic", "chatId", chatID, "queueId", topic.QueueID, "name", topic.Name, "threadId", threadID)
+
+	// the topic exists anyway, so it's used; it's only created again after a restart
+	if err := store.Save(ctx, chatID, topic.QueueID, threadID); err != nil {
+		s.log.Error("Failed to save forum topic, it will be created again after a restart", err, "chatId", chatID, "queueId", topic.QueueID, "threadId", threadID)
+	}
+	return threadID, nil
+}
@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRedisForumTopicStore_WhenTopicIsSaved_ReturnsItsThreadForTheQueueOnly(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisClient := initRedisContainer(ctx, t)
+	sut := NewRedisForumTopicStore(redisClient)
+
+	// Act
+	saveErr := sut.Save(ctx, "@group", 24, 15)
+	saved, getErr := sut.Get(ctx, "@group", 24)
+	other, getOtherErr := sut.Get(ctx, "@group", 25)
+
+	// Assert
+	if saveErr != nil || getErr != nil || getOtherErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v", saveErr, getErr, getOtherErr)
+	}
+	if saved != 15 {
+		t.Errorf("Expected thread 15, but got %d", saved)
+	}
+	if other != 0 {
+		t.Errorf("Expected no thread for another queue, but got %d", other)
+	}
+}
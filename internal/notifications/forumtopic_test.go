@@ -0,0 +1,196 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/google/go-cmp/cmp"
+)
+
+type inMemoryForumTopicStore struct {
+	threads map[string]int64
+}
+
+func (s *inMemoryForumTopicStore) Get(ctx context.Context, chatID string, queueID int) (int64, error) {
+	return s.threads[fmt.Sprintf("%s:%d", chatID, queueID)], nil
+}
+
+func (s *inMemoryForumTopicStore) Save(ctx context.Context, chatID string, queueID int, threadID int64) error {
+	s.threads[fmt.Sprintf("%s:%d", chatID, queueID)] = threadID
+	return nil
+}
+
+func TestForumTopicUnmarshalText_ParsesTopicOrReturnsError(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    ForumTopic
+		expectedErr bool
+	}{
+		{value: "24|Karty pobytu", expected: ForumTopic{QueueID: 24, Name: "Karty pobytu"}},
+		{value: "24|Karty pobytu|15", expected: ForumTopic{QueueID: 24, Name: "Karty pobytu", ThreadID: 15}},
+		{value: "*|Karty pobytu", expectedErr: true},
+		{value: "24|", expectedErr: true},
+		{value: "24|Karty pobytu|abc", expectedErr: true},
+		{value: "24", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			// Arrange
+			var sut ForumTopic
+
+			// Act
+			err := sut.UnmarshalText([]byte(tc.value))
+
+			// Assert
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error for %q", tc.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, sut); diff != "" {
+				t.Errorf("Unexpected topic (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type failingForumTopicStore struct {
+	inMemoryForumTopicStore
+}
+
+func (s *failingForumTopicStore) Save(ctx context.Context, chatID string, queueID int, threadID int64) error {
+	return fmt.Errorf("redis is down")
+}
+
+func TestResolveForumTopics_WhenCreatedTopicCannotBeSaved_UsesIt(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{results: map[string]string{"createForumTopic": `{"message_thread_id":77,"name":"Odbiór"}`}}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RequestTimeoutSeconds: 2,
+		CreateForumTopics:     true,
+		ForumTopics:           []ForumTopic{{QueueID: 26, Name: "Odbiór"}},
+	}
+	store := &failingForumTopicStore{inMemoryForumTopicStore{threads: map[string]int64{}}}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.ResolveForumTopics(context.Background(), "@group", store)
+	notifyErr := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, ChatID: "@group", QueueID: 26, Text: "opened"})
+
+	// Assert
+	if err != nil || notifyErr != nil {
+		t.Fatalf("Expected no error, but got: %v, %v", err, notifyErr)
+	}
+	if thread := api.calls[len(api.calls)-1].body["message_thread_id"]; thread != float64(77) {
+		t.Errorf("Expected the event to be sent to the created topic, but got thread %v", thread)
+	}
+}
+
+func TestResolveForumTopics_WhenTopicsAreResolved_SendsEventsToTopicOfTheirQueue(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{results: map[string]string{"createForumTopic": `{"message_thread_id":77,"name":"Odbiór"}`}}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+		CreateForumTopics:     true,
+		ForumTopics: []ForumTopic{
+			{QueueID: 24, Name: "Karty pobytu", ThreadID: 15}, // configured
+			{QueueID: 25, Name: "Obywatelstwo"},               // created before
+			{QueueID: 26, Name: "Odbiór"},                     // missing
+		},
+	}
+	store := &inMemoryForumTopicStore{threads: map[string]int64{"@group:25": 42}}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.ResolveForumTopics(context.Background(), "@group", store)
+	for _, queueID := range []int{24, 25, 26, 27} {
+		if err := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, ChatID: "@group", QueueID: queueID, Text: "opened"}); err != nil {
+			t.Fatalf("Failed to send notification: %v", err)
+		}
+	}
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if api.calls[0].method != "createForumTopic" || api.calls[0].body["name"] != "Odbiór" {
+		t.Errorf("Expected the missing topic to be created, but got %+v", api.calls[0])
+	}
+	if store.threads["@group:26"] != 77 {
+		t.Errorf("Expected the created topic to be saved, but got %v", store.threads)
+	}
+
+	var threads []any
+	for _, c := range api.calls[1:] {
+		threads = append(threads, c.body["message_thread_id"])
+	}
+	expectedThreads := []any{float64(15), float64(42), float64(77), nil}
+	if diff := cmp.Diff(expectedThreads, threads); diff != "" {
+		t.Errorf("Unexpected message threads (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolveForumTopics_WhenTopicIsMissingAndCreatingIsDisabled_ReturnsErrorAndUsesOtherTopics(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RequestTimeoutSeconds: 2,
+		CreateForumTopics:     false,
+		ForumTopics: []ForumTopic{
+			{QueueID: 24, Name: "Karty pobytu"},
+			{QueueID: 25, Name: "Obywatelstwo", ThreadID: 15},
+		},
+	}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.ResolveForumTopics(context.Background(), "@group", &inMemoryForumTopicStore{threads: map[string]int64{}})
+	callsWhileResolving := len(api.calls)
+	for _, queueID := range []int{24, 25} {
+		if err := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, ChatID: "@group", QueueID: queueID, Text: "opened"}); err != nil {
+			t.Fatalf("Failed to send notification: %v", err)
+		}
+	}
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for the missing topic")
+	}
+	if callsWhileResolving != 0 {
+		t.Errorf("Expected no Telegram calls while resolving, but got %v", api.methods())
+	}
+
+	var threads []any
+	for _, c := range api.calls {
+		threads = append(threads, c.body["message_thread_id"])
+	}
+	expectedThreads := []any{nil, float64(15)}
+	if diff := cmp.Diff(expectedThreads, threads); diff != "" {
+		t.Errorf("Unexpected message threads (-want +got):\n%s", diff)
+	}
+}
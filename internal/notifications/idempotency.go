@@ -0,0 +1,113 @@
+package notifications
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const idempotencyRedisKeyPrefix = "notification:sent:"
+
+// NewIdempotencyKey derives the key of a queue event from the queue, the transition and the observed queue status.
+// The transition is identified by the previous state and when the monitor entered it, so the same change observed again
+// after a restart maps to the same key, while the same change in a later opening of the queue doesn't.
+func NewIdempotencyKey(event *QueueEvent) string {
+	transition := fmt.Sprintf("%s|%d|%s", event.PreviousState, event.PreviousStateSince.UnixMilli(), event.Type)
+	observation := fmt.Sprintf("%d|%s|%t|%t|%s|%d", event.QueueID, transition, event.Active, event.Enabled, event.TicketValue, event.TicketsLeft)
+	hash := sha256.Sum256([]byte(observation))
+	return hex.EncodeToString(hash[:16])
+}
+
+// IdempotencyStore records which notifications were already sent.
+type IdempotencyStore interface {
+	// Claim records the key and reports whether it was recorded before.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release forgets the key, so the notification can be sent again.
+	Release(ctx context.Context, key string) error
+}
+
+// RedisIdempotencyStore shares sent keys between restarts and replicas.
+type RedisIdempotencyStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisIdempotencyStore(redisClient *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{redisClient: redisClient}
+}
+
+func (s *RedisIdempotencyStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	claimed, err := s.redisClient.SetNX(ctx, idempotencyRedisKeyPrefix+key, time.Now().UTC().Format(time.RFC3339), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record idempotency key in Redis: %w", err)
+	}
+	return !claimed, nil
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := s.redisClient.Del(ctx, idempotencyRedisKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to remove idempotency key from Redis: %w", err)
+	}
+	return nil
+}
+
+// IdempotentNotifier skips queue events whose idempotency key was already sent.
+// The key is claimed before sending, so concurrent replicas don't send the same event, and released if sending fails, so it can be retried.
+// If the store is unavailable, the event is sent anyway: a duplicate is better than a lost notification.
+type IdempotentNotifier struct {
+	cfg      *IdempotencyConfig
+	log      *logger.Logger
+	store    IdempotencyStore
+	notifier Notifier
+}
+
+func NewIdempotentNotifier(cfg *IdempotencyConfig, log *logger.Logger, store IdempotencyStore, notifier Notifier) *IdempotentNotifier {
+	return &IdempotentNotifier{
+		cfg:      cfg,
+		log:      log,
+		store:    store,
+		notifier: notifier,
+	}
+}
+
+// SendMessage forwards free-form messages as is: they don't carry an idempotency key.
+func (n *IdempotentNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return n.notifier.SendMessage(ctx, chatID, text)
+}
+
+func (n *IdempotentNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	if event.IdempotencyKey == "" {
+		return n.send(ctx, event)
+	}
+
+	ttl := time.Duration(n.cfg.KeyTtlSeconds) * time.Second
+	sent, err := n.store.Claim(ctx, event.IdempotencyKey, ttl)
+	if err != nil {
+		n.log.Warn("Failed to check idempotency key, sending notification anyway", "key", event.IdempotencyKey, "error", err)
+		return n.send(ctx, event)
+	}
+	if sent {
+		n.log.Info("Skipping duplicate notification", "key", event.IdempotencyKey, "type", event.Type, "queueId", event.QueueID)
+		return nil
+	}
+
+	if err := n.send(ctx, event); err != nil {
+		if releaseErr := n.store.Release(ctx, event.IdempotencyKey); releaseErr != nil {
+			n.log.Error("Failed to release idempotency key", releaseErr, "key", event.IdempotencyKey)
+		}
+		return err
+	}
+	return nil
+}
+
+func (n *IdempotentNotifier) send(ctx context.Context, event *QueueEvent) error {
+	if eventNotifier, ok := n.notifier.(EventNotifier); ok {
+		return eventNotifier.Notify(ctx, event)
+	}
+	return n.notifier.SendMessage(ctx, event.ChatID, event.Text)
+}
@@ -0,0 +1,30 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedisIdempotencyStoreClaim_WhenKeyIsClaimedTwice_ReportsItAsSent(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisClient := initRedisContainer(ctx, t)
+	sut := NewRedisIdempotencyStore(redisClient)
+
+	// Act
+	firstSent, firstErr := sut.Claim(ctx, "key", time.Minute)
+	secondSent, secondErr := sut.Claim(ctx, "key", time.Minute)
+	ttl := redisClient.TTL(ctx, idempotencyRedisKeyPrefix+"key").Val()
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", firstErr, secondErr)
+	}
+	if firstSent || !secondSent {
+		t.Errorf("Expected only the second claim to report the key as sent, but got %v and %v", firstSent, secondSent)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Expected key to expire within a minute, but TTL is %v", ttl)
+	}
+}
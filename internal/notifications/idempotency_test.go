@@ -0,0 +1,125 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+type inMemoryIdempotencyStore struct {
+	shouldFail bool
+	keys       map[string]bool
+}
+
+func (s *inMemoryIdempotencyStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if s.shouldFail {
+		return false, fmt.Errorf("connection refused")
+	}
+	sent := s.keys[key]
+	s.keys[key] = true
+	return sent, nil
+}
+
+func (s *inMemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	delete(s.keys, key)
+	return nil
+}
+
+func newTestIdempotentNotifier(store IdempotencyStore, notifier Notifier) *IdempotentNotifier {
+	cfg := &IdempotencyConfig{Enabled: true, KeyTtlSeconds: 60}
+	return NewIdempotentNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), store, notifier)
+}
+
+func newTestQueueEvent(eventType EventType, ticketsLeft int) *QueueEvent {
+	event := &QueueEvent{Type: eventType, ChatID: "@channel", QueueID: 24, Active: true, Enabled: true, TicketValue: "K80", TicketsLeft: ticketsLeft, OccurredAt: time.Now(),
+		PreviousState: "ActiveEnabled", PreviousStateSince: time.Date(2026, 3, 2, 8, 10, 0, 0, time.UTC)}
+	event.IdempotencyKey = NewIdempotencyKey(event)
+	return event
+}
+
+func TestNewIdempotencyKey_Always_DependsOnQueueTransitionAndObservationOnly(t *testing.T) {
+	// Arrange
+	event := newTestQueueEvent(EventQueueOpened, 10)
+	sameObservedLater := newTestQueueEvent(EventQueueOpened, 10)
+	sameObservedLater.OccurredAt = event.OccurredAt.Add(time.Minute)
+	sameObservedLater.Text = "different rendering"
+	otherTransition := newTestQueueEvent(EventTicketsChanged, 10)
+	otherObservation := newTestQueueEvent(EventQueueOpened, 9)
+	otherPreviousState := newTestQueueEvent(EventQueueOpened, 10)
+	otherPreviousState.PreviousState = "ActiveDisabled"
+	laterOpening := newTestQueueEvent(EventQueueOpened, 10)
+	laterOpening.PreviousStateSince = event.PreviousStateSince.Add(30 * time.Minute)
+
+	// Act
+	key := NewIdempotencyKey(event)
+
+	// Assert
+	if key != NewIdempotencyKey(sameObservedLater) {
+		t.Error("Expected the same transition and observation to produce the same key")
+	}
+	if key == NewIdempotencyKey(otherTransition) || key == NewIdempotencyKey(otherObservation) {
+		t.Error("Expected different transition or observation to produce a different key")
+	}
+	if key == NewIdempotencyKey(otherPreviousState) || key == NewIdempotencyKey(laterOpening) {
+		t.Error("Expected the same change from another state or in another cycle to produce a different key")
+	}
+}
+
+func TestIdempotentNotifierNotify_WhenEventWasAlreadySent_SkipsIt(t *testing.T) {
+	// Arrange
+	notifier := &scriptedNotifier{}
+	sut := newTestIdempotentNotifier(&inMemoryIdempotencyStore{keys: map[string]bool{}}, notifier)
+
+	// Act
+	firstErr := sut.Notify(context.Background(), newTestQueueEvent(EventQueueOpened, 10))
+	duplicateErr := sut.Notify(context.Background(), newTestQueueEvent(EventQueueOpened, 10))
+	nextErr := sut.Notify(context.Background(), newTestQueueEvent(EventTicketsChanged, 9))
+
+	// Assert
+	if firstErr != nil || duplicateErr != nil || nextErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v", firstErr, duplicateErr, nextErr)
+	}
+	if len(notifier.events) != 2 {
+		t.Errorf("Expected the duplicate to be skipped and 2 events to be sent, but got %d", len(notifier.events))
+	}
+}
+
+func TestIdempotentNotifierNotify_WhenSendingFails_AllowsRetry(t *testing.T) {
+	// Arrange
+	store := &inMemoryIdempotencyStore{keys: map[string]bool{}}
+	notifier := &scriptedNotifier{errs: map[string]error{"@channel": fmt.Errorf("connection refused")}}
+	sut := newTestIdempotentNotifier(store, notifier)
+
+	// Act
+	failedErr := sut.Notify(context.Background(), newTestQueueEvent(EventQueueOpened, 10))
+	notifier.errs = nil
+	retryErr := sut.Notify(context.Background(), newTestQueueEvent(EventQueueOpened, 10))
+
+	// Assert
+	if failedErr == nil {
+		t.Error("Expected the first error to be returned, but got nil")
+	}
+	if retryErr != nil || len(notifier.events) != 1 {
+		t.Errorf("Expected the retry to be sent, but got error %v and %d events", retryErr, len(notifier.events))
+	}
+}
+
+func TestIdempotentNotifierNotify_WhenStoreIsUnavailable_SendsAnyway(t *testing.T) {
+	// Arrange
+	notifier := &scriptedNotifier{}
+	sut := newTestIdempotentNotifier(&inMemoryIdempotencyStore{shouldFail: true}, notifier)
+
+	// Act
+	err := sut.Notify(context.Background(), newTestQueueEvent(EventQueueOpened, 10))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Errorf("Expected the event to be sent, but got %d events", len(notifier.events))
+	}
+}
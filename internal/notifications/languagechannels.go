@@ -0,0 +1,101 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+// LanguageChannelsNotifier sends every queue event to the main chat and, translated, to the chat of each configured language.
+// Like MultiNotifier, an error is returned only when every chat failed, so the chats which succeeded don't get duplicates.
+type LanguageChannelsNotifier struct {
+	log       *logger.Logger
+	channels  map[string]string // chat of each language
+	languages []string          // sorted, so the chats are notified in a stable order
+	notifier  Notifier
+}
+
+func NewLanguageChannelsNotifier(log *logger.Logger, channels map[string]string, notifier Notifier) (*LanguageChannelsNotifier, error) {
+	languages := make([]string, 0, len(channels))
+	for lang, chatID := range channels {
+		if i18n.Default.Language(lang) != lang {
+			return nil, fmt.Errorf("unsupported language %s of chat %s, supported languages: %v", lang, chatID, i18n.Default.Languages())
+		}
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	return &LanguageChannelsNotifier{
+		log:       log,
+		channels:  channels,
+		languages: languages,
+		notifier:  notifier,
+	}, nil
+}
+
+// SendMessage forwards free-form messages to the main chat only: they can't be translated.
+func (n *LanguageChannelsNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return n.notifier.SendMessage(ctx, chatID, text)
+}
+
+func (n *LanguageChannelsNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	var errs []error
+	if err := n.send(ctx, event); err != nil {
+		n.log.Error("Failed to send notification", err, "chatId", event.ChatID)
+		errs = append(errs, err)
+	}
+
+	for _, lang := range n.languages {
+		translated := *event
+		translated.ChatID = n.channels[lang]
+		translated.Language = lang
+		translated.Text = RenderQueueMessage(&translated)
+
+		if err := n.send(ctx, &translated); err != nil {
+			n.log.Error("Failed to send translated notification", err, "chatId", translated.ChatID, "language", lang)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(n.languages)+1 {
+		return fmt.Errorf("all chats failed: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Post sends the post to the main chat and, rendered in the language of each chat, to the language chats.
+// Like Notify, an error is returned only when every chat failed.
+func (n *LanguageChannelsNotifier) Post(ctx context.Context, post *ChannelPost) error {
+	var errs []error
+	if err := sendPost(ctx, n.notifier, post); err != nil {
+		n.log.Error("Failed to send post", err, "chatId", post.ChatID)
+		errs = append(errs, err)
+	}
+
+	for _, lang := range n.languages {
+		translated := *post
+		translated.ChatID = n.channels[lang]
+		translated.Language = lang
+
+		if err := sendPost(ctx, n.notifier, &translated); err != nil {
+			n.log.Error("Failed to send translated post", err, "chatId", translated.ChatID, "language", lang)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(n.languages)+1 {
+		return fmt.Errorf("all chats failed: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (n *LanguageChannelsNotifier) send(ctx context.Context, event *QueueEvent) error {
+	if eventNotifier, ok := n.notifier.(EventNotifier); ok {
+		return eventNotifier.Notify(ctx, event)
+	}
+	return n.notifier.SendMessage(ctx, event.ChatID, event.Text)
+}
@@ -0,0 +1,173 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+type recordingEventNotifier struct {
+	events    []*QueueEvent
+	failChats map[string]bool
+}
+
+func (m *recordingEventNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return nil
+}
+
+func (m *recordingEventNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	m.events = append(m.events, event)
+	if m.failChats[event.ChatID] {
+		return fmt.Errorf("failed to notify %s", event.ChatID)
+	}
+	return nil
+}
+
+func TestLanguageChannelsNotifierNotify_Always_SendsTranslatedEventToEveryLanguageChat(t *testing.T) {
+	// Arrange
+	notifier := &recordingEventNotifier{}
+	sut, err := NewLanguageChannelsNotifier(logger.NewLogger(&logger.Config{Level: "error"}), map[string]string{"uk": "@duw_uk", "en": "@duw_en"}, notifier)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	event := &QueueEvent{Type: EventQueueOpened, ChatID: "@duw", Language: "pl", Text: "polski", QueueName: "Odbiór karty", Enabled: true, TicketsLeft: 5}
+
+	// Act
+	err = sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(notifier.events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(notifier.events))
+	}
+	if notifier.events[0] != event {
+		t.Errorf("Expected the original event to be sent first, got %+v", notifier.events[0])
+	}
+
+	expected := []struct{ chatID, text string }{
+		{"@duw_en", "🔔 Queue <b>Odbiór karty</b> is now available!\n🧾 <b>5</b> tickets left"},
+		{"@duw_uk", "🔔 Черга <b>Odbiór karty</b> тепер доступна!\n🧾 Залишилося <b>5</b> квитків"},
+	}
+	for i, e := range expected {
+		actual := notifier.events[i+1]
+		if actual.ChatID != e.chatID || actual.Text != e.text {
+			t.Errorf("Expected event to %s with text %q, got %s with %q", e.chatID, e.text, actual.ChatID, actual.Text)
+		}
+	}
+	if event.Text != "polski" || event.ChatID != "@duw" {
+		t.Errorf("Expected the original event not to be modified, got %+v", event)
+	}
+}
+
+func TestLanguageChannelsNotifierNotify_WhenSomeChatsFail_ReturnsErrorOnlyIfAllFailed(t *testing.T) {
+	testCases := []struct {
+		name        string
+		failChats   map[string]bool
+		expectError bool
+	}{
+		{"translated chat fails", map[string]bool{"@duw_en": true}, false},
+		{"main chat fails", map[string]bool{"@duw": true}, false},
+		{"all chats fail", map[string]bool{"@duw": true, "@duw_en": true}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			notifier := &recordingEventNotifier{failChats: tc.failChats}
+			sut, err := NewLanguageChannelsNotifier(logger.NewLogger(&logger.Config{Level: "error"}), map[string]string{"en": "@duw_en"}, notifier)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			// Act
+			err = sut.Notify(context.Background(), &QueueEvent{Type: EventQueueInactive, ChatID: "@duw", QueueName: "K"})
+
+			// Assert
+			if (err != nil) != tc.expectError {
+				t.Errorf("Expected error: %t, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestNewLanguageChannelsNotifier_WhenLanguageIsNotSupported_ReturnsError(t *testing.T) {
+	// Act
+	_, err := NewLanguageChannelsNotifier(logger.NewLogger(&logger.Config{Level: "error"}), map[string]string{"de": "@duw_de"}, &recordingEventNotifier{})
+
+	// Assert
+	if err == nil {
+		t.Error("Expected error for an unsupported language")
+	}
+}
+
+type recordingPostNotifier struct {
+	recordingEventNotifier
+	posts []ChannelPost
+}
+
+func (m *recordingPostNotifier) Post(ctx context.Context, post *ChannelPost) error {
+	m.posts = append(m.posts, *post)
+	if m.failChats[post.ChatID] {
+		return fmt.Errorf("failed to post to %s", post.ChatID)
+	}
+	return nil
+}
+
+func TestLanguageChannelsNotifierPost_Always_SendsPostInLanguageOfEveryChat(t *testing.T) {
+	// Arrange
+	notifier := &recordingPostNotifier{}
+	sut, err := NewLanguageChannelsNotifier(logger.NewLogger(&logger.Config{Level: "error"}), map[string]string{"uk": "@duw_uk", "en": "@duw_en"}, notifier)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	post := &ChannelPost{ChatID: "@duw", QueueID: 24, Language: "pl", Render: func(language string) string { return "summary " + language }}
+
+	// Act
+	err = sut.Post(context.Background(), post)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []struct{ chatID, text string }{
+		{"@duw", "summary pl"},
+		{"@duw_en", "summary en"},
+		{"@duw_uk", "summary uk"},
+	}
+	if len(notifier.posts) != len(expected) {
+		t.Fatalf("Expected %d posts, got %d", len(expected), len(notifier.posts))
+	}
+	for i, e := range expected {
+		actual := notifier.posts[i]
+		if actual.ChatID != e.chatID || actual.Render(actual.Language) != e.text || actual.QueueID != 24 {
+			t.Errorf("Expected post to %s with text %q, got %s with %q", e.chatID, e.text, actual.ChatID, actual.Render(actual.Language))
+		}
+	}
+	if post.ChatID != "@duw" || post.Language != "pl" {
+		t.Errorf("Expected the original post not to be modified, got %+v", post)
+	}
+}
+
+func TestLanguageChannelsNotifierPost_WhenAllChatsFail_ReturnsError(t *testing.T) {
+	// Arrange
+	notifier := &recordingPostNotifier{recordingEventNotifier: recordingEventNotifier{failChats: map[string]bool{"@duw": true, "@duw_en": true}}}
+	sut, err := NewLanguageChannelsNotifier(logger.NewLogger(&logger.Config{Level: "error"}), map[string]string{"en": "@duw_en"}, notifier)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Act
+	err = sut.Post(context.Background(), &ChannelPost{ChatID: "@duw", Language: "pl", Render: func(language string) string { return "summary" }})
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error when all chats failed")
+	}
+}
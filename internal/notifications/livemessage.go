@@ -0,0 +1,228 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	liveMessageRedisKeyPrefix = "telegram:live_message:"
+
+	msgKeyLiveMessageUpdatedAt = "live.updated_at"
+	liveMessageTimeLayout      = "15:04:05"
+)
+
+// LiveMessage is the channel message which is edited while the queue is open.
+type LiveMessage struct {
+	MessageID int64     `json:"message_id"`
+	Pinned    bool      `json:"pinned"`
+	PostedAt  time.Time `json:"posted_at"`
+}
+
+// LiveMessageStore keeps the current live message of each chat and queue, so it can still be edited after a restart.
+// The key is built by liveMessageKey: a forum supergroup can hold the live messages of several queues.
+type LiveMessageStore interface {
+	Get(ctx context.Context, key string) (*LiveMessage, error) // returns nil if there is no live message
+	Save(ctx context.Context, key string, msg *LiveMessage) error
+	Delete(ctx context.Context, key string) error
+}
+
+type RedisLiveMessageStore struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+func NewRedisLiveMessageStore(redisClient *redis.Client, ttlSeconds uint) *RedisLiveMessageStore {
+	return &RedisLiveMessageStore{
+		redisClient: redisClient,
+		ttl:         time.Duration(ttlSeconds) * time.Second,
+	}
+}
+
+func (s *RedisLiveMessageStore) Get(ctx context.Context, key string) (*LiveMessage, error) {
+	data, err := s.redisClient.Get(ctx, liveMessageRedisKeyPrefix+key).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get live message from Redis: %w", err)
+	}
+
+	var msg LiveMessage
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal live message: %w", err)
+	}
+	return &msg, nil
+}
+
+func (s *RedisLiveMessageStore) Save(ctx context.Context, key string, msg *LiveMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal live message: %w", err)
+	}
+
+	if err := s.redisClient.Set(ctx, liveMessageRedisKeyPrefix+key, data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save live message to Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisLiveMessageStore) Delete(ctx context.Context, key string) error {
+	if err := s.redisClient.Del(ctx, liveMessageRedisKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete live message from Redis: %w", err)
+	}
+	return nil
+}
+
+// LiveMessageNotifier keeps a single, continuously edited message in the channel while the queue is open,
+// instead of posting a new message on every change of the tickets count.
+// The message is posted when the queue opens (and optionally pinned); a new message is posted only for major events, like closing.
+type LiveMessageNotifier struct {
+	cfg      *TelegramLiveMessageConfig
+	log      *logger.Logger
+	telegram *TelegramNotifier
+	store    LiveMessageStore
+}
+
+func NewLiveMessageNotifier(cfg *TelegramLiveMessageConfig, log *logger.Logger, telegram *TelegramNotifier, store LiveMessageStore) *LiveMessageNotifier {
+	return &LiveMessageNotifier{
+		cfg:      cfg,
+		log:      log,
+		telegram: telegram,
+		store:    store,
+	}
+}
+
+func (n *LiveMessageNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return n.telegram.SendMessage(ctx, chatID, text)
+}
+
+// Post sends the post as a new message: it's not a part of the live message.
+func (n *LiveMessageNotifier) Post(ctx context.Context, post *ChannelPost) error {
+	return n.telegram.Post(ctx, post)
+}
+
+func (n *LiveMessageNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	switch event.Type {
+	case EventQueueOpened:
+		return n.startLiveMessage(ctx, event)
+	case EventTicketsChanged:
+		return n.updateLiveMessage(ctx, event)
+	case EventTicketsLow:
+		return n.alertLowTickets(ctx, event)
+	default:
+		return n.finishLiveMessage(ctx, event)
+	}
+}
+
+func (n *LiveMessageNotifier) startLiveMessage(ctx context.Context, event *QueueEvent) error {
+	n.unpinPrevious(ctx, event)
+
+	messageID, err := n.telegram.PostMessage(ctx, event.ChatID, n.liveText(event), n.telegram.EventMessageOptions(event))
+	if err != nil {
+		return err
+	}
+
+	live := &LiveMessage{MessageID: messageID, PostedAt: event.OccurredAt}
+	if n.cfg.Pin {
+		if err := n.telegram.PinChatMessage(ctx, event.ChatID, messageID); err != nil {
+			n.log.Error("Failed to pin live message", err, "chatId", event.ChatID)
+		} else {
+			live.Pinned = true
+		}
+	}
+
+	n.save(ctx, event, live)
+	return nil
+}
+
+func (n *LiveMessageNotifier) updateLiveMessage(ctx context.Context, event *QueueEvent) error {
+	live, err := n.store.Get(ctx, liveMessageKey(event))
+	if err != nil {
+		n.log.Error("Failed to get live message, posting a new one", err, "chatId", event.ChatID)
+	}
+	if live == nil {
+		return n.startLiveMessage(ctx, event)
+	}
+
+	err = n.telegram.EditMessageText(ctx, event.ChatID, live.MessageID, n.liveText(event), n.telegram.EventMessageOptions(event))
+	var apiErr *TelegramApiError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &apiErr) && strings.Contains(apiErr.Description, "message is not modified"):
+		return nil
+	case errors.As(err, &apiErr) && strings.Contains(apiErr.Description, "message to edit not found"):
+		n.log.Warn("Live message was deleted, posting a new one", "chatId", event.ChatID, "messageId", live.MessageID)
+		return n.startLiveMessage(ctx, event)
+	default:
+		return err
+	}
+}
+
+// alertLowTickets posts the alert as a new message, so that members are notified, and keeps editing the live message.
+// The alert is delivered at this point, so a failed edit is only logged: the next change updates the live message again.
+func (n *LiveMessageNotifier) alertLowTickets(ctx context.Context, event *QueueEvent) error {
+	if err := n.telegram.Notify(ctx, event); err != nil {
+		return err
+	}
+
+	if err := n.updateLiveMessage(ctx, event); err != nil {
+		n.log.Error("Failed to update live message", err, "chatId", event.ChatID)
+	}
+	return nil
+}
+
+// finishLiveMessage posts the major event as a new message, so that members are notified, and stops editing the live message.
+func (n *LiveMessageNotifier) finishLiveMessage(ctx context.Context, event *QueueEvent) error {
+	if err := n.telegram.Notify(ctx, event); err != nil {
+		return err
+	}
+
+	n.unpinPrevious(ctx, event)
+	if err := n.store.Delete(ctx, liveMessageKey(event)); err != nil {
+		n.log.Error("Failed to delete live message", err, "chatId", event.ChatID)
+	}
+	return nil
+}
+
+// unpinPrevious unpins the live message of the previous opening, if there is one. Failures are only logged: it's cosmetic.
+func (n *LiveMessageNotifier) unpinPrevious(ctx context.Context, event *QueueEvent) {
+	live, err := n.store.Get(ctx, liveMessageKey(event))
+	if err != nil || live == nil || !live.Pinned {
+		return
+	}
+
+	if err := n.telegram.UnpinChatMessage(ctx, event.ChatID, live.MessageID); err != nil {
+		n.log.Error("Failed to unpin live message", err, "chatId", event.ChatID)
+		return
+	}
+
+	live.Pinned = false
+	n.save(ctx, event, live)
+}
+
+// save stores the live message. If it fails, the next change is posted as a new message, so the error is only logged.
+func (n *LiveMessageNotifier) save(ctx context.Context, event *QueueEvent, live *LiveMessage) {
+	if err := n.store.Save(ctx, liveMessageKey(event), live); err != nil {
+		n.log.Error("Failed to save live message", err, "chatId", event.ChatID)
+	}
+}
+
+func (n *LiveMessageNotifier) liveText(event *QueueEvent) string {
+	return event.Text + fmt.Sprintf(i18n.Default.Message(event.Language, msgKeyLiveMessageUpdatedAt), templates.Default.LocalTime(event.OccurredAt).Format(liveMessageTimeLayout))
+}
+
+func liveMessageKey(event *QueueEvent) string {
+	return fmt.Sprintf("%s:%d", event.ChatID, event.QueueID)
+}
@@ -0,0 +1,32 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedisLiveMessageStore_WhenMessageIsSavedAndDeleted_ReturnsItOnlyUntilDeleted(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisClient := initRedisContainer(ctx, t)
+	sut := NewRedisLiveMessageStore(redisClient, 60)
+	live := &LiveMessage{MessageID: 42, Pinned: true, PostedAt: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)}
+
+	// Act
+	saveErr := sut.Save(ctx, "@channel", live)
+	saved, getErr := sut.Get(ctx, "@channel")
+	deleteErr := sut.Delete(ctx, "@channel")
+	deleted, getDeletedErr := sut.Get(ctx, "@channel")
+
+	// Assert
+	if saveErr != nil || getErr != nil || deleteErr != nil || getDeletedErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v", saveErr, getErr, deleteErr, getDeletedErr)
+	}
+	if saved == nil || *saved != *live {
+		t.Errorf("Expected saved live message %+v, but got %+v", live, saved)
+	}
+	if deleted != nil {
+		t.Errorf("Expected no live message after deleting, but got %+v", deleted)
+	}
+}
@@ -0,0 +1,249 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+type inMemoryLiveMessageStore struct {
+	messages map[string]*LiveMessage
+}
+
+func (s *inMemoryLiveMessageStore) Get(ctx context.Context, chatID string) (*LiveMessage, error) {
+	if msg, ok := s.messages[chatID]; ok {
+		copied := *msg
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (s *inMemoryLiveMessageStore) Save(ctx context.Context, chatID string, msg *LiveMessage) error {
+	s.messages[chatID] = msg
+	return nil
+}
+
+func (s *inMemoryLiveMessageStore) Delete(ctx context.Context, chatID string) error {
+	delete(s.messages, chatID)
+	return nil
+}
+
+type telegramCall struct {
+	method string
+	body   map[string]any
+}
+
+// mockTelegramBotApi records Bot API calls and responds to sendMessage and sendPhoto with increasing message IDs.
+// The fields of multipart requests are recorded as strings, including the contents of the files.
+type mockTelegramBotApi struct {
+	calls         []telegramCall
+	nextMessageID int64
+	errors        map[string]string // method to error description, returned with 400
+	results       map[string]string // method to raw JSON result, "true" if not set
+}
+
+func (m *mockTelegramBotApi) start(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		var body map[string]any
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			body = multipartBody(r.MultipartForm)
+		} else {
+			json.NewDecoder(r.Body).Decode(&body)
+		}
+		m.calls = append(m.calls, telegramCall{method, body})
+
+		if description, ok := m.errors[method]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"ok":false,"error_code":400,"description":"%s"}`, description)
+			return
+		}
+
+		if method == "sendMessage" || method == "sendPhoto" {
+			m.nextMessageID++
+			fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d}}`, m.nextMessageID)
+			return
+		}
+		if result, ok := m.results[method]; ok {
+			fmt.Fprintf(w, `{"ok":true,"result":%s}`, result)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func multipartBody(form *multipart.Form) map[string]any {
+	body := make(map[string]any)
+	for name, values := range form.Value {
+		body[name] = values[0]
+	}
+	for name, files := range form.File {
+		file, err := files[0].Open()
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(file)
+		file.Close()
+		body[name] = string(data)
+	}
+	return body
+}
+
+func (m *mockTelegramBotApi) methods() []string {
+	var methods []string
+	for _, c := range m.calls {
+		methods = append(methods, c.method)
+	}
+	return methods
+}
+
+func newTestLiveMessageNotifier(t *testing.T, api *mockTelegramBotApi, store LiveMessageStore, pin bool) *LiveMessageNotifier {
+	server := api.start(t)
+	telegramCfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+	}
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+
+	cfg := &TelegramLiveMessageConfig{Enabled: true, Pin: pin}
+	return NewLiveMessageNotifier(cfg, log, NewTelegramNotifier(telegramCfg, log, &http.Client{}), store)
+}
+
+func TestLiveMessageNotify_WhenQueueOpensAndTicketsChange_PostsPinsAndEditsOneMessage(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{}
+	store := &inMemoryLiveMessageStore{messages: map[string]*LiveMessage{}}
+	sut := newTestLiveMessageNotifier(t, api, store, true)
+
+	occurredAt := time.Date(2026, 3, 2, 7, 30, 15, 0, time.UTC) // 08:30:15 in Warsaw
+	ctx := context.Background()
+
+	// Act
+	openErr := sut.Notify(ctx, &QueueEvent{Type: EventQueueOpened, ChatID: "@channel", QueueID: 24, Text: "opened, 10 left", OccurredAt: occurredAt})
+	changeErr := sut.Notify(ctx, &QueueEvent{Type: EventTicketsChanged, ChatID: "@channel", QueueID: 24, Text: "opened, 9 left", OccurredAt: occurredAt.Add(time.Minute)})
+
+	// Assert
+	if openErr != nil || changeErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", openErr, changeErr)
+	}
+
+	expectedMethods := []string{"sendMessage", "pinChatMessage", "editMessageText"}
+	if fmt.Sprint(api.methods()) != fmt.Sprint(expectedMethods) {
+		t.Fatalf("Expected calls %v, but got %v", expectedMethods, api.methods())
+	}
+
+	if text := api.calls[0].body["text"]; text != "opened, 10 left\n🕒 Zaktualizowano: <i>08:30:15</i>" {
+		t.Errorf("Unexpected live message text: %v", text)
+	}
+	if api.calls[1].body["message_id"] != float64(1) || api.calls[1].body["disable_notification"] != true {
+		t.Errorf("Expected the live message to be pinned silently, but got %v", api.calls[1].body)
+	}
+	edit := api.calls[2].body
+	if edit["message_id"] != float64(1) || edit["text"] != "opened, 9 left\n🕒 Zaktualizowano: <i>08:31:15</i>" {
+		t.Errorf("Unexpected edit request: %v", edit)
+	}
+}
+
+func TestLiveMessageNotify_WhenQueueCloses_PostsNewMessageAndForgetsLiveMessage(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{nextMessageID: 41}
+	store := &inMemoryLiveMessageStore{messages: map[string]*LiveMessage{"@channel:24": {MessageID: 41, Pinned: true}}}
+	sut := newTestLiveMessageNotifier(t, api, store, true)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueUnavailable, ChatID: "@channel", QueueID: 24, Text: "closed"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	expectedMethods := []string{"sendMessage", "unpinChatMessage"}
+	if fmt.Sprint(api.methods()) != fmt.Sprint(expectedMethods) {
+		t.Errorf("Expected calls %v, but got %v", expectedMethods, api.methods())
+	}
+	if api.calls[0].body["text"] != "closed" {
+		t.Errorf("Expected closing message to be sent as is, but got %v", api.calls[0].body["text"])
+	}
+	if _, ok := store.messages["@channel:24"]; ok {
+		t.Error("Expected live message to be forgotten after closing")
+	}
+}
+
+func TestLiveMessageNotify_WhenTicketsRunLow_PostsAlertAndEditsLiveMessage(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{nextMessageID: 41}
+	store := &inMemoryLiveMessageStore{messages: map[string]*LiveMessage{"@channel:24": {MessageID: 41, Pinned: true}}}
+	sut := newTestLiveMessageNotifier(t, api, store, true)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsLow, ChatID: "@channel", QueueID: 24, Text: "running out, 5 left"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	expectedMethods := []string{"sendMessage", "editMessageText"}
+	if fmt.Sprint(api.methods()) != fmt.Sprint(expectedMethods) {
+		t.Fatalf("Expected calls %v, but got %v", expectedMethods, api.methods())
+	}
+	if api.calls[0].body["text"] != "running out, 5 left" {
+		t.Errorf("Expected the alert to be sent as is, but got %v", api.calls[0].body["text"])
+	}
+	if api.calls[1].body["message_id"] != float64(41) {
+		t.Errorf("Expected the live message to be edited, but got %v", api.calls[1].body)
+	}
+	if live := store.messages["@channel:24"]; live == nil || live.MessageID != 41 || !live.Pinned {
+		t.Errorf("Expected the live message to be kept, but got %+v", live)
+	}
+}
+
+func TestLiveMessageNotify_WhenEditFails_HandlesTelegramErrors(t *testing.T) {
+	testCases := []struct {
+		name            string
+		editError       string
+		expectedMethods []string
+		expectedLiveID  int64
+	}{
+		{"Text did not change", "Bad Request: message is not modified", []string{"editMessageText"}, 7},
+		{"Message was deleted", "Bad Request: message to edit not found", []string{"editMessageText", "sendMessage"}, 8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			api := &mockTelegramBotApi{nextMessageID: 7, errors: map[string]string{"editMessageText": tc.editError}}
+			store := &inMemoryLiveMessageStore{messages: map[string]*LiveMessage{"@channel:24": {MessageID: 7}}}
+			sut := newTestLiveMessageNotifier(t, api, store, false)
+
+			// Act
+			err := sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged, ChatID: "@channel", QueueID: 24, Text: "9 left"})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if fmt.Sprint(api.methods()) != fmt.Sprint(tc.expectedMethods) {
+				t.Errorf("Expected calls %v, but got %v", tc.expectedMethods, api.methods())
+			}
+			if live := store.messages["@channel:24"]; live == nil || live.MessageID != tc.expectedLiveID {
+				t.Errorf("Expected live message %d, but got %+v", tc.expectedLiveID, live)
+			}
+		})
+	}
+}
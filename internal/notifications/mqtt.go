@@ -0,0 +1,225 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	mqttPayloadOnline  = "online"
+	mqttPayloadOffline = "offline"
+	mqttPayloadOn      = "ON"
+	mqttPayloadOff     = "OFF"
+
+	mqttDisconnectQuiesceMs = 250
+)
+
+// mqttClient is the subset of the paho client used by the publisher.
+type mqttClient interface {
+	Connect() mqtt.Token
+	Publish(topic string, qos byte, retained bool, payload any) mqtt.Token
+	Disconnect(quiesce uint)
+}
+
+// MqttPublisher publishes the queue state to retained MQTT topics, so that it can be consumed by Home Assistant and other automations.
+// Availability is reported using a retained topic which is set to "offline" by the broker (last will) if the publisher disconnects unexpectedly.
+// When discovery is enabled, Home Assistant MQTT discovery configs are published, so the sensors appear automatically.
+type MqttPublisher struct {
+	cfg    *MqttConfig
+	log    *logger.Logger
+	client mqttClient
+
+	mu         sync.Mutex
+	discovered map[int]bool // queues for which discovery configs were published since the last (re)connect
+}
+
+func NewMqttPublisher(cfg *MqttConfig, log *logger.Logger) *MqttPublisher {
+	p := &MqttPublisher{
+		cfg:        cfg,
+		log:        log,
+		discovered: make(map[int]bool),
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.BrokerUrl).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetWill(p.availabilityTopic(), mqttPayloadOffline, cfg.Qos, true).
+		SetOnConnectHandler(func(mqtt.Client) { p.onConnect() }).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Warn("Connection to MQTT broker lost, reconnecting", "error", err)
+		})
+	p.client = mqtt.NewClient(opts)
+
+	return p
+}
+
+// Connect starts connecting to the broker. Connection is retried in the background, messages published in the meantime are queued.
+func (p *MqttPublisher) Connect() {
+	p.client.Connect()
+}
+
+// Close marks the publisher as offline and disconnects from the broker.
+func (p *MqttPublisher) Close() {
+	if err := p.publish(p.availabilityTopic(), mqttPayloadOffline); err != nil {
+		p.log.Error("Failed to publish MQTT availability", err)
+	}
+	p.client.Disconnect(mqttDisconnectQuiesceMs)
+}
+
+func (p *MqttPublisher) onConnect() {
+	p.log.Info("Connected to MQTT broker")
+
+	p.mu.Lock()
+	p.discovered = make(map[int]bool) // the broker may have lost retained messages, so discovery is repeated
+	p.mu.Unlock()
+
+	if err := p.publish(p.availabilityTopic(), mqttPayloadOnline); err != nil {
+		p.log.Error("Failed to publish MQTT availability", err)
+	}
+}
+
+// SendMessage is a no-op: MQTT topics represent the queue state, not free-form messages.
+func (p *MqttPublisher) SendMessage(ctx context.Context, chatID, text string) error {
+	return nil
+}
+
+func (p *MqttPublisher) Notify(ctx context.Context, event *QueueEvent) error {
+	if p.cfg.DiscoveryEnabled {
+		if err := p.publishDiscovery(event.QueueID, event.QueueName); err != nil {
+			return fmt.Errorf("failed to publish Home Assistant discovery configs: %w", err)
+		}
+	}
+
+	states := map[string]string{
+		"active":       mqttBool(event.Active),
+		"enabled":      mqttBool(event.Enabled),
+		"tickets_left": strconv.Itoa(event.TicketsLeft),
+		"ticket_value": event.TicketValue,
+	}
+
+	var errs []error
+	for field, value := range states {
+		if err := p.publish(p.stateTopic(event.QueueID, field), value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to publish queue state to MQTT: %w", errors.Join(errs...))
+	}
+
+	p.log.Info("Queue state published successfully to MQTT.")
+	return nil
+}
+
+// haDiscoveryConfig is the Home Assistant MQTT discovery payload. See https://www.home-assistant.io/integrations/mqtt/#mqtt-discovery
+type haDiscoveryConfig struct {
+	Name              string   `json:"name"`
+	UniqueID          string   `json:"unique_id"`
+	ObjectID          string   `json:"object_id"`
+	StateTopic        string   `json:"state_topic"`
+	AvailabilityTopic string   `json:"availability_topic"`
+	PayloadOn         string   `json:"payload_on,omitempty"`
+	PayloadOff        string   `json:"payload_off,omitempty"`
+	UnitOfMeasurement string   `json:"unit_of_measurement,omitempty"`
+	StateClass        string   `json:"state_class,omitempty"`
+	Icon              string   `json:"icon,omitempty"`
+	Device            haDevice `json:"device"`
+}
+
+type haDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+}
+
+func (p *MqttPublisher) publishDiscovery(queueID int, queueName string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered[queueID] {
+		return nil
+	}
+
+	device := haDevice{
+		Identifiers:  []string{fmt.Sprintf("duw_queue_%d", queueID)},
+		Name:         fmt.Sprintf("DUW: %s", queueName),
+		Manufacturer: "Dolnośląski Urząd Wojewódzki",
+		Model:        "Queue monitor",
+	}
+
+	sensors := []struct {
+		component string
+		field     string
+		config    haDiscoveryConfig
+	}{
+		{"binary_sensor", "active", haDiscoveryConfig{Name: "Active", PayloadOn: mqttPayloadOn, PayloadOff: mqttPayloadOff, Icon: "mdi:office-building"}},
+		{"binary_sensor", "enabled", haDiscoveryConfig{Name: "Enabled", PayloadOn: mqttPayloadOn, PayloadOff: mqttPayloadOff, Icon: "mdi:ticket-confirmation"}},
+		{"sensor", "tickets_left", haDiscoveryConfig{Name: "Tickets left", UnitOfMeasurement: "tickets", StateClass: "measurement", Icon: "mdi:ticket"}},
+		{"sensor", "ticket_value", haDiscoveryConfig{Name: "Last called ticket", Icon: "mdi:ticket-account"}},
+	}
+
+	for _, sensor := range sensors {
+		objectID := fmt.Sprintf("duw_queue_%d_%s", queueID, sensor.field)
+
+		cfg := sensor.config
+		cfg.UniqueID = objectID
+		cfg.ObjectID = objectID
+		cfg.StateTopic = p.stateTopic(queueID, sensor.field)
+		cfg.AvailabilityTopic = p.availabilityTopic()
+		cfg.Device = device
+
+		payload, err := json.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal discovery config: %w", err)
+		}
+
+		topic := fmt.Sprintf("%s/%s/%s/config", p.cfg.DiscoveryPrefix, sensor.component, objectID)
+		if err := p.publish(topic, payload); err != nil {
+			return err
+		}
+	}
+
+	p.discovered[queueID] = true
+	return nil
+}
+
+// publish sends a retained message and waits until it is delivered according to the configured QoS.
+func (p *MqttPublisher) publish(topic string, payload any) error {
+	token := p.client.Publish(topic, p.cfg.Qos, true, payload)
+	if !token.WaitTimeout(time.Duration(p.cfg.PublishTimeoutSeconds) * time.Second) {
+		return fmt.Errorf("timed out publishing to topic %s", topic)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("failed to publish to topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (p *MqttPublisher) availabilityTopic() string {
+	return p.cfg.TopicPrefix + "/status"
+}
+
+func (p *MqttPublisher) stateTopic(queueID int, field string) string {
+	return fmt.Sprintf("%s/queue/%d/%s", p.cfg.TopicPrefix, queueID, field)
+}
+
+func mqttBool(v bool) string {
+	if v {
+		return mqttPayloadOn
+	}
+	return mqttPayloadOff
+}
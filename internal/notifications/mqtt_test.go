@@ -0,0 +1,189 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+type mockMqttToken struct {
+	err error
+}
+
+func (t *mockMqttToken) Wait() bool                     { return true }
+func (t *mockMqttToken) WaitTimeout(time.Duration) bool { return true }
+func (t *mockMqttToken) Error() error                   { return t.err }
+func (t *mockMqttToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+type publishedMqttMessage struct {
+	qos      byte
+	retained bool
+	payload  string
+}
+
+type mockMqttClient struct {
+	mu         sync.Mutex
+	shouldFail bool
+	published  map[string]publishedMqttMessage
+	publishes  int
+}
+
+func (c *mockMqttClient) Connect() mqtt.Token { return &mockMqttToken{} }
+func (c *mockMqttClient) Disconnect(uint)     {}
+
+func (c *mockMqttClient) Publish(topic string, qos byte, retained bool, payload any) mqtt.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shouldFail {
+		return &mockMqttToken{err: fmt.Errorf("not connected")}
+	}
+
+	var p string
+	switch v := payload.(type) {
+	case string:
+		p = v
+	case []byte:
+		p = string(v)
+	}
+	c.published[topic] = publishedMqttMessage{qos: qos, retained: retained, payload: p}
+	c.publishes++
+	return &mockMqttToken{}
+}
+
+func newTestMqttPublisher(client *mockMqttClient) *MqttPublisher {
+	cfg := &MqttConfig{
+		TopicPrefix:           "duw",
+		DiscoveryEnabled:      true,
+		DiscoveryPrefix:       "homeassistant",
+		Qos:                   1,
+		PublishTimeoutSeconds: 1,
+	}
+
+	sut := NewMqttPublisher(cfg, logger.NewLogger(&logger.Config{Level: "error"}))
+	sut.client = client
+	return sut
+}
+
+func TestMqttNotify_WhenEventReceived_PublishesRetainedStateTopics(t *testing.T) {
+	// Arrange
+	client := &mockMqttClient{published: map[string]publishedMqttMessage{}}
+	sut := newTestMqttPublisher(client)
+
+	event := &QueueEvent{Type: EventQueueOpened, QueueID: 24, QueueName: "Odbiór karty", Active: true, Enabled: true, TicketValue: "K80", TicketsLeft: 10}
+
+	// Act
+	err := sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected state to be published, but got error: %v", err)
+	}
+
+	expected := map[string]string{
+		"duw/queue/24/active":       "ON",
+		"duw/queue/24/enabled":      "ON",
+		"duw/queue/24/tickets_left": "10",
+		"duw/queue/24/ticket_value": "K80",
+	}
+	for topic, payload := range expected {
+		msg, ok := client.published[topic]
+		if !ok {
+			t.Errorf("Expected message on topic '%s', but there was none", topic)
+			continue
+		}
+		if msg.payload != payload || !msg.retained || msg.qos != 1 {
+			t.Errorf("Unexpected message on topic '%s': %+v", topic, msg)
+		}
+	}
+}
+
+func TestMqttNotify_WhenDiscoveryEnabled_PublishesHomeAssistantConfigsOncePerConnection(t *testing.T) {
+	// Arrange
+	client := &mockMqttClient{published: map[string]publishedMqttMessage{}}
+	sut := newTestMqttPublisher(client)
+
+	event := &QueueEvent{Type: EventTicketsChanged, QueueID: 24, QueueName: "Odbiór karty", Active: true, Enabled: true, TicketsLeft: 9}
+
+	// Act
+	firstErr := sut.Notify(context.Background(), event)
+	publishesAfterFirst := client.publishes
+	secondErr := sut.Notify(context.Background(), event)
+	publishesAfterSecond := client.publishes
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", firstErr, secondErr)
+	}
+
+	if publishesAfterFirst != 8 || publishesAfterSecond-publishesAfterFirst != 4 {
+		t.Errorf("Expected 4 discovery and 4 state messages first, and only 4 state messages afterwards, but got %d and %d",
+			publishesAfterFirst, publishesAfterSecond-publishesAfterFirst)
+	}
+
+	msg, ok := client.published["homeassistant/sensor/duw_queue_24_tickets_left/config"]
+	if !ok || !msg.retained {
+		t.Fatalf("Expected retained discovery config for tickets_left sensor, but got %+v", msg)
+	}
+
+	var cfg haDiscoveryConfig
+	if err := json.Unmarshal([]byte(msg.payload), &cfg); err != nil {
+		t.Fatalf("Failed to decode discovery config: %v", err)
+	}
+	if cfg.StateTopic != "duw/queue/24/tickets_left" || cfg.AvailabilityTopic != "duw/status" || cfg.UniqueID != "duw_queue_24_tickets_left" {
+		t.Errorf("Unexpected discovery config: %+v", cfg)
+	}
+	if len(cfg.Device.Identifiers) != 1 || cfg.Device.Name != "DUW: Odbiór karty" {
+		t.Errorf("Unexpected discovery device: %+v", cfg.Device)
+	}
+
+	if _, ok := client.published["homeassistant/binary_sensor/duw_queue_24_active/config"]; !ok {
+		t.Error("Expected discovery config for active binary sensor")
+	}
+}
+
+func TestMqttOnConnect_Always_PublishesOnlineAvailabilityAndRepeatsDiscovery(t *testing.T) {
+	// Arrange
+	client := &mockMqttClient{published: map[string]publishedMqttMessage{}}
+	sut := newTestMqttPublisher(client)
+	sut.Notify(context.Background(), &QueueEvent{QueueID: 24})
+
+	// Act
+	sut.onConnect()
+	publishesBefore := client.publishes
+	sut.Notify(context.Background(), &QueueEvent{QueueID: 24})
+
+	// Assert
+	if msg := client.published["duw/status"]; msg.payload != "online" || !msg.retained {
+		t.Errorf("Expected retained 'online' availability, but got %+v", msg)
+	}
+
+	if client.publishes-publishesBefore != 8 {
+		t.Errorf("Expected discovery to be repeated after reconnect, but got %d publishes", client.publishes-publishesBefore)
+	}
+}
+
+func TestMqttNotify_WhenPublishFails_ReturnsError(t *testing.T) {
+	// Arrange
+	client := &mockMqttClient{published: map[string]publishedMqttMessage{}, shouldFail: true}
+	sut := newTestMqttPublisher(client)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{QueueID: 24})
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when publishing fails, but got nil")
+	}
+}
@@ -0,0 +1,56 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+// MultiNotifier fans out every notification to all configured notifiers.
+// Notifiers implementing EventNotifier receive the structured event, the rest receive the pre-formatted text.
+// An error is returned only when every notifier failed: otherwise the monitor would not move to the new state and
+// would resend the same notification through the notifiers which already succeeded.
+type MultiNotifier struct {
+	log       *logger.Logger
+	notifiers []Notifier
+}
+
+func NewMultiNotifier(log *logger.Logger, notifiers ...Notifier) *MultiNotifier {
+	return &MultiNotifier{
+		log:       log,
+		notifiers: notifiers,
+	}
+}
+
+func (m *MultiNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return m.fanOut(func(n Notifier) error {
+		return n.SendMessage(ctx, chatID, text)
+	})
+}
+
+func (m *MultiNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	return m.fanOut(func(n Notifier) error {
+		if en, ok := n.(EventNotifier); ok {
+			return en.Notify(ctx, event)
+		}
+		return n.SendMessage(ctx, event.ChatID, event.Text)
+	})
+}
+
+func (m *MultiNotifier) fanOut(send func(n Notifier) error) error {
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := send(n); err != nil {
+			m.log.Error("Failed to send notification", err, "notifier", fmt.Sprintf("%T", n))
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(m.notifiers) {
+		return fmt.Errorf("all notifiers failed: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
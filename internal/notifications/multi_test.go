@@ -0,0 +1,90 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+type mockTextNotifier struct {
+	shouldFail   bool
+	receivedText string
+}
+
+func (m *mockTextNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	m.receivedText = text
+	if m.shouldFail {
+		return fmt.Errorf("failed to send message")
+	}
+	return nil
+}
+
+type mockEventNotifier struct {
+	mockTextNotifier
+	receivedEvent *QueueEvent
+}
+
+func (m *mockEventNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	m.receivedEvent = event
+	if m.shouldFail {
+		return fmt.Errorf("failed to notify")
+	}
+	return nil
+}
+
+func TestMultiNotifierNotify_Always_RoutesEventsAndTextToMatchingNotifiers(t *testing.T) {
+	// Arrange
+	textNotifier := &mockTextNotifier{}
+	eventNotifier := &mockEventNotifier{}
+	sut := NewMultiNotifier(logger.NewLogger(&logger.Config{Level: "error"}), textNotifier, eventNotifier)
+
+	event := &QueueEvent{Type: EventQueueOpened, ChatID: "@channel", Text: "text"}
+
+	// Act
+	err := sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if textNotifier.receivedText != "text" {
+		t.Errorf("Expected text notifier to receive pre-formatted text, but got '%s'", textNotifier.receivedText)
+	}
+
+	if eventNotifier.receivedEvent != event {
+		t.Errorf("Expected event notifier to receive the event, but got %+v", eventNotifier.receivedEvent)
+	}
+
+	if eventNotifier.receivedText != "" {
+		t.Errorf("Expected event notifier not to receive text, but got '%s'", eventNotifier.receivedText)
+	}
+}
+
+func TestMultiNotifierNotify_WhenNotifiersFail_ReturnsErrorOnlyIfAllFailed(t *testing.T) {
+	testCases := []struct {
+		name        string
+		notifiers   []Notifier
+		expectError bool
+	}{
+		{"One of two failed", []Notifier{&mockTextNotifier{shouldFail: true}, &mockEventNotifier{}}, false},
+		{"All failed", []Notifier{&mockTextNotifier{shouldFail: true}, &mockEventNotifier{mockTextNotifier{shouldFail: true}, nil}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			sut := NewMultiNotifier(logger.NewLogger(&logger.Config{Level: "error"}), tc.notifiers...)
+
+			// Act
+			err := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened})
+
+			// Assert
+			if (err != nil) != tc.expectError {
+				t.Errorf("Expected error: %v, but got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package notifications
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	outboxPendingRedisKey  = "outbox:pending"  // sorted set of message IDs scored by the enqueue time (unix ms)
+	outboxMessagesRedisKey = "outbox:messages" // hash of message ID to message JSON, for both pending and dead messages
+	outboxDeadRedisKey     = "outbox:dead"     // list of IDs of messages which could not be delivered
+)
+
+// ErrOutboxMessageNotFound is returned when replaying a message which is not in the dead-letter list.
+var ErrOutboxMessageNotFound = errors.New("outbox message not found")
+
+// OutboxMessage is a notification waiting for delivery.
+// Event is set for queue status updates, so that event notifiers still receive structured data after a restart.
+// Delivered lists the targets which already received the message, so a retry only goes to the ones which failed.
+type OutboxMessage struct {
+	ID            string      `json:"id"`
+	ChatID        string      `json:"chat_id"`
+	Text          string      `json:"text"`
+	Event         *QueueEvent `json:"event,omitempty"`
+	Attempts      uint        `json:"attempts"`
+	LastError     string      `json:"last_error,omitempty"`
+	EnqueuedAt    time.Time   `json:"enqueued_at"`
+	NextAttemptAt time.Time   `json:"next_attempt_at"`
+	Delivered     []string    `json:"delivered,omitempty"`
+}
+
+func NewOutboxMessage(chatID, text string, event *QueueEvent) (*OutboxMessage, error) {
+	id, err := newDeliveryID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate outbox message ID: %w", err)
+	}
+
+	now := time.Now().UTC()
+	return &OutboxMessage{
+		ID:            id,
+		ChatID:        chatID,
+		Text:          text,
+		Event:         event,
+		EnqueuedAt:    now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// OutboxStore is the storage used by the delivery worker.
+type OutboxStore interface {
+	Pending(ctx context.Context, limit int) ([]*OutboxMessage, error)
+	Ack(ctx context.Context, msg *OutboxMessage) error
+	Reschedule(ctx context.Context, msg *OutboxMessage) error
+	DeadLetter(ctx context.Context, msg *OutboxMessage) error
+}
+
+// RedisOutbox stores notifications in Redis, so they survive restarts and outages of the messaging services.
+type RedisOutbox struct {
+	redisClient *redis.Client
+}
+
+func NewRedisOutbox(redisClient *redis.Client) *RedisOutbox {
+	return &RedisOutbox{redisClient: redisClient}
+}
+
+// EnqueueTx adds the messages to a transaction, so they are stored atomically with other changes made in it.
+func (o *RedisOutbox) EnqueueTx(ctx context.Context, pipe redis.Pipeliner, msgs ...*OutboxMessage) error {
+	for _, msg := range msgs {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal outbox message: %w", err)
+		}
+		pipe.HSet(ctx, outboxMessagesRedisKey, msg.ID, data)
+		pipe.ZAdd(ctx, outboxPendingRedisKey, redis.Z{Score: float64(msg.EnqueuedAt.UnixMilli()), Member: msg.ID})
+	}
+	return nil
+}
+
+func (o *RedisOutbox) Enqueue(ctx context.Context, msgs ...*OutboxMessage) error {
+	_, err := o.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		return o.EnqueueTx(ctx, pipe, msgs...)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue outbox messages: %w", err)
+	}
+	return nil
+}
+
+// Pending returns the messages waiting for delivery, oldest first, whether or not their next attempt is due.
+func (o *RedisOutbox) Pending(ctx context.Context, limit int) ([]*OutboxMessage, error) {
+	ids, err := o.redisClient.ZRange(ctx, outboxPendingRedisKey, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending outbox messages: %w", err)
+	}
+
+	return o.get(ctx, ids)
+}
+
+func (o *RedisOutbox) Ack(ctx context.Context, msg *OutboxMessage) error {
+	_, err := o.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, outboxPendingRedisKey, msg.ID)
+		pipe.HDel(ctx, outboxMessagesRedisKey, msg.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge outbox message: %w", err)
+	}
+	return nil
+}
+
+// Reschedule stores the updated attempt details. The message keeps its position, so the ones enqueued after it
+// wait until it's delivered.
+func (o *RedisOutbox) Reschedule(ctx context.Context, msg *OutboxMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+	if err := o.redisClient.HSet(ctx, outboxMessagesRedisKey, msg.ID, data).Err(); err != nil {
+		return fmt.Errorf("failed to reschedule outbox message: %w", err)
+	}
+	return nil
+}
+
+// DeadLetter moves the message to the dead-letter list, where it stays until replayed.
+func (o *RedisOutbox) DeadLetter(ctx context.Context, msg *OutboxMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+
+	_, err = o.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, outboxPendingRedisKey, msg.ID)
+		pipe.HSet(ctx, outboxMessagesRedisKey, msg.ID, data)
+		pipe.RPush(ctx, outboxDeadRedisKey, msg.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move outbox message to dead letters: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters returns the messages which could not be delivered, oldest first.
+func (o *RedisOutbox) DeadLetters(ctx context.Context) ([]*OutboxMessage, error) {
+	ids, err := o.redisClient.LRange(ctx, outboxDeadRedisKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead outbox messages: %w", err)
+	}
+
+	return o.get(ctx, ids)
+}
+
+// Replay moves a dead message back to the pending ones with the attempts counter reset.
+// The targets which already received it are not sent it again.
+func (o *RedisOutbox) Replay(ctx context.Context, id string) error {
+	replay := func(tx *redis.Tx) error {
+		if err := tx.LPos(ctx, outboxDeadRedisKey, id, redis.LPosArgs{}).Err(); err == redis.Nil {
+			return ErrOutboxMessageNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to find dead outbox message: %w", err)
+		}
+
+		data, err := tx.HGet(ctx, outboxMessagesRedisKey, id).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get outbox message: %w", err)
+		}
+
+		var msg OutboxMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			return fmt.Errorf("failed to unmarshal outbox message: %w", err)
+		}
+		msg.Attempts = 0
+		msg.LastError = ""
+		msg.NextAttemptAt = time.Now().UTC()
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LRem(ctx, outboxDeadRedisKey, 0, id)
+			return o.EnqueueTx(ctx, pipe, &msg)
+		})
+		return err
+	}
+
+	// WATCH makes sure the message is not replayed twice by concurrent calls
+	if err := o.redisClient.Watch(ctx, replay, outboxDeadRedisKey); err != nil {
+		if errors.Is(err, ErrOutboxMessageNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to replay outbox message: %w", err)
+	}
+	return nil
+}
+
+// ReplayAll moves all dead messages back to the pending ones and returns their number.
+func (o *RedisOutbox) ReplayAll(ctx context.Context) (int, error) {
+	msgs, err := o.DeadLetters(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, msg := range msgs {
+		if err := o.Replay(ctx, msg.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(msgs), nil
+}
+
+func (o *RedisOutbox) get(ctx context.Context, ids []string) ([]*OutboxMessage, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	values, err := o.redisClient.HMGet(ctx, outboxMessagesRedisKey, ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox messages: %w", err)
+	}
+
+	msgs := make([]*OutboxMessage, 0, len(values))
+	for _, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue // removed in the meantime
+		}
+
+		var msg OutboxMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal outbox message: %w", err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, nil
+}
+
+// OutboxWorker delivers pending messages from the outbox to every target notifier. Failed deliveries are retried
+// with exponential backoff, and messages which failed permanently or too many times are moved to the dead-letter list.
+// A message is acknowledged only when every target received it; the targets are identified by their type, so the
+// progress survives restarts.
+// Messages are delivered in order: a failure postpones the remaining ones until the failed one is delivered.
+type OutboxWorker struct {
+	cfg     *OutboxConfig
+	log     *logger.Logger
+	store   OutboxStore
+	targets []Notifier
+}
+
+func NewOutboxWorker(cfg *OutboxConfig, log *logger.Logger, store OutboxStore, targets ...Notifier) *OutboxWorker {
+	return &OutboxWorker{
+		cfg:     cfg,
+		log:     log,
+		store:   store,
+		targets: targets,
+	}
+}
+
+func (w *OutboxWorker) Run(ctx context.Context, done chan<- bool) {
+	w.log.Info("Started outbox delivery worker")
+	ticker := time.NewTicker(time.Duration(w.cfg.PollIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Info("Stopped outbox delivery worker")
+			done <- true
+			return
+		case <-ticker.C:
+			if err := w.DeliverDue(ctx); err != nil {
+				w.log.Error("Error during outbox delivery", err)
+			}
+		}
+	}
+}
+
+// DeliverDue sends the pending messages in order, until it reaches one whose next attempt is not due yet.
+func (w *OutboxWorker) DeliverDue(ctx context.Context) error {
+	msgs, err := w.store.Pending(ctx, int(w.cfg.BatchSize))
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		if msg.NextAttemptAt.After(time.Now()) {
+			return nil
+		}
+
+		permanent, sendErr := w.send(ctx, msg)
+		if sendErr == nil {
+			if err := w.store.Ack(ctx, msg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		msg.Attempts++
+		msg.LastError = logger.Redact(sendErr.Error()) // shown by the dead-letters command
+		if permanent || msg.Attempts >= w.cfg.MaxAttempts {
+			w.log.Error("Outbox message could not be delivered, moving it to dead letters", sendErr, "id", msg.ID, "attempts", msg.Attempts)
+			if err := w.store.DeadLetter(ctx, msg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		msg.NextAttemptAt = time.Now().UTC().Add(w.backoff(msg.Attempts))
+		w.log.Warn("Outbox message delivery failed, will retry", "id", msg.ID, "attempts", msg.Attempts, "nextAttemptAt", msg.NextAttemptAt, "error", sendErr)
+		if err := w.store.Reschedule(ctx, msg); err != nil {
+			return err
+		}
+		return nil // keep the order of the remaining messages
+	}
+
+	return nil
+}
+
+// send delivers the message to the targets which didn't receive it yet and records the ones which succeeded.
+// The failure is permanent if every failed target failed permanently, so retrying can't help.
+func (w *OutboxWorker) send(ctx context.Context, msg *OutboxMessage) (bool, error) {
+	var errs []error
+	permanent := true
+	for _, target := range w.targets {
+		name := fmt.Sprintf("%T", target)
+		if slices.Contains(msg.Delivered, name) {
+			continue
+		}
+
+		var err error
+		if eventNotifier, ok := target.(EventNotifier); ok && msg.Event != nil {
+			err = eventNotifier.Notify(ctx, msg.Event)
+		} else {
+			err = target.SendMessage(ctx, msg.ChatID, msg.Text)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			permanent = permanent && IsPermanentTelegramError(err)
+			continue
+		}
+		msg.Delivered = append(msg.Delivered, name)
+	}
+	return len(errs) > 0 && permanent, errors.Join(errs...)
+}
+
+// backoff doubles the retry delay after every failed attempt, up to the maximum delay.
+func (w *OutboxWorker) backoff(attempts uint) time.Duration {
+	delay := time.Duration(w.cfg.RetryDelayMs) * time.Millisecond
+	maxDelay := time.Duration(w.cfg.MaxRetryDelayMs) * time.Millisecond
+	for i := uint(1); i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+func newDeliveryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRedisOutbox_WhenMessageIsDeadLetteredAndReplayed_BecomesPendingAgain(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisOutbox(initRedisContainer(ctx, t))
+
+	msg, _ := NewOutboxMessage("@channel", "Test message", &QueueEvent{Type: EventQueueOpened, QueueID: 24})
+	if err := sut.Enqueue(ctx, msg); err != nil {
+		t.Fatalf("Failed to enqueue message: %v", err)
+	}
+
+	// Act
+	pending, pendingErr := sut.Pending(ctx, 10)
+	msg.Attempts = 5
+	msg.LastError = "connection refused"
+	deadErr := sut.DeadLetter(ctx, msg)
+	pendingAfterDead, _ := sut.Pending(ctx, 10)
+	dead, deadListErr := sut.DeadLetters(ctx)
+	replayErr := sut.Replay(ctx, msg.ID)
+	secondReplayErr := sut.Replay(ctx, msg.ID)
+	pendingAfterReplay, _ := sut.Pending(ctx, 10)
+
+	// Assert
+	if pendingErr != nil || deadErr != nil || deadListErr != nil || replayErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v", pendingErr, deadErr, deadListErr, replayErr)
+	}
+
+	if len(pending) != 1 || pending[0].ID != msg.ID || pending[0].Event == nil || pending[0].Event.QueueID != 24 {
+		t.Errorf("Expected the enqueued message with its event to be pending, but got %v", pending)
+	}
+	if len(pendingAfterDead) != 0 {
+		t.Errorf("Expected dead message not to be pending, but got %v", pendingAfterDead)
+	}
+	if len(dead) != 1 || dead[0].LastError != "connection refused" {
+		t.Errorf("Expected dead message with the last error, but got %v", dead)
+	}
+	if !errors.Is(secondReplayErr, ErrOutboxMessageNotFound) {
+		t.Errorf("Expected replaying twice to fail with not found, but got %v", secondReplayErr)
+	}
+	if len(pendingAfterReplay) != 1 || pendingAfterReplay[0].Attempts != 0 {
+		t.Errorf("Expected replayed message to be pending with attempts reset, but got %v", pendingAfterReplay)
+	}
+}
+
+func TestRedisOutbox_WhenMessageIsAcknowledged_IsNotPendingAnymore(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisOutbox(initRedisContainer(ctx, t))
+
+	msg, _ := NewOutboxMessage("@channel", "Test message", nil)
+	sut.Enqueue(ctx, msg)
+
+	// Act
+	ackErr := sut.Ack(ctx, msg)
+	pending, pendingErr := sut.Pending(ctx, 10)
+
+	// Assert
+	if ackErr != nil || pendingErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", ackErr, pendingErr)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending messages, but got %v", pending)
+	}
+}
@@ -0,0 +1,244 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+// mockOutboxStore keeps the pending messages in order, like the Redis outbox.
+type mockOutboxStore struct {
+	pending      []*OutboxMessage
+	acked        []string
+	rescheduled  []*OutboxMessage
+	deadLettered []*OutboxMessage
+}
+
+func (s *mockOutboxStore) Pending(ctx context.Context, limit int) ([]*OutboxMessage, error) {
+	return slices.Clone(s.pending[:min(limit, len(s.pending))]), nil
+}
+
+func (s *mockOutboxStore) Ack(ctx context.Context, msg *OutboxMessage) error {
+	s.acked = append(s.acked, msg.ID)
+	s.remove(msg)
+	return nil
+}
+
+func (s *mockOutboxStore) Reschedule(ctx context.Context, msg *OutboxMessage) error {
+	s.rescheduled = append(s.rescheduled, msg)
+	return nil
+}
+
+func (s *mockOutboxStore) DeadLetter(ctx context.Context, msg *OutboxMessage) error {
+	s.deadLettered = append(s.deadLettered, msg)
+	s.remove(msg)
+	return nil
+}
+
+func (s *mockOutboxStore) remove(msg *OutboxMessage) {
+	s.pending = slices.DeleteFunc(s.pending, func(m *OutboxMessage) bool { return m.ID == msg.ID })
+}
+
+// scriptedNotifier fails for the chats listed in errs, or only the given number of times for the chats listed in failures.
+type scriptedNotifier struct {
+	errs     map[string]error
+	failures map[string]int
+	sent     []string
+	events   []*QueueEvent
+}
+
+func (n *scriptedNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	if err := n.errs[chatID]; err != nil {
+		return err
+	}
+	if n.failures[chatID] > 0 {
+		n.failures[chatID]--
+		return fmt.Errorf("connection refused")
+	}
+	n.sent = append(n.sent, chatID)
+	return nil
+}
+
+func (n *scriptedNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	if err := n.SendMessage(ctx, event.ChatID, event.Text); err != nil {
+		return err
+	}
+	n.events = append(n.events, event)
+	return nil
+}
+
+// otherScriptedNotifier is a target of another type, as the targets are identified by their type.
+type otherScriptedNotifier struct {
+	scriptedNotifier
+}
+
+func newTestOutboxWorker(store OutboxStore, targets ...Notifier) *OutboxWorker {
+	cfg := &OutboxConfig{
+		BatchSize:       10,
+		MaxAttempts:     3,
+		RetryDelayMs:    1000,
+		MaxRetryDelayMs: 3000,
+	}
+	return NewOutboxWorker(cfg, logger.NewLogger(&logger.Config{Level: "error"}), store, targets...)
+}
+
+func TestOutboxDeliverDue_WhenDeliverySucceeds_AcknowledgesMessagesAndSendsEvents(t *testing.T) {
+	// Arrange
+	event := &QueueEvent{Type: EventQueueOpened, ChatID: "@channel", Text: "opened"}
+	store := &mockOutboxStore{pending: []*OutboxMessage{
+		{ID: "1", ChatID: "@channel", Text: "opened", Event: event},
+		{ID: "2", ChatID: "@feedback", Text: "plain"},
+	}}
+	notifier := &scriptedNotifier{}
+	sut := newTestOutboxWorker(store, notifier)
+
+	// Act
+	err := sut.DeliverDue(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected successful delivery, but got error: %v", err)
+	}
+	if len(store.acked) != 2 {
+		t.Errorf("Expected both messages to be acknowledged, but got %v", store.acked)
+	}
+	if len(notifier.events) != 1 || notifier.events[0] != event {
+		t.Errorf("Expected the stored event to be passed to the event notifier, but got %v", notifier.events)
+	}
+}
+
+func TestOutboxDeliverDue_WhenDeliveryFails_ReschedulesWithBackoffAndKeepsOrder(t *testing.T) {
+	// Arrange
+	store := &mockOutboxStore{pending: []*OutboxMessage{
+		{ID: "1", ChatID: "@channel", Text: "first", Attempts: 1},
+		{ID: "2", ChatID: "@other", Text: "second"},
+	}}
+	notifier := &scriptedNotifier{errs: map[string]error{"@channel": fmt.Errorf("connection refused")}}
+	sut := newTestOutboxWorker(store, notifier)
+
+	// Act
+	started := time.Now()
+	err := sut.DeliverDue(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected failed delivery to be rescheduled without error, but got: %v", err)
+	}
+
+	if len(store.rescheduled) != 1 {
+		t.Fatalf("Expected the failed message to be rescheduled, but got %v", store.rescheduled)
+	}
+	msg := store.rescheduled[0]
+	if msg.Attempts != 2 || !strings.HasSuffix(msg.LastError, "connection refused") {
+		t.Errorf("Expected attempt details to be updated, but got %+v", msg)
+	}
+	if delay := msg.NextAttemptAt.Sub(started); delay < 2*time.Second || delay > 3*time.Second {
+		t.Errorf("Expected the retry delay to be doubled to 2s, but got %v", delay)
+	}
+
+	if len(notifier.sent) != 0 || len(store.acked) != 0 {
+		t.Errorf("Expected the next message to wait for the failed one, but it was sent")
+	}
+}
+
+func TestOutboxDeliverDue_WhenDeliveryCantSucceed_MovesMessageToDeadLetters(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attempts uint
+		err      error
+	}{
+		{"Permanent Telegram error", 0, &TelegramApiError{StatusCode: 403, Description: "Forbidden: bot was blocked by the user"}},
+		{"Too many attempts", 2, fmt.Errorf("connection refused")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			store := &mockOutboxStore{pending: []*OutboxMessage{
+				{ID: "1", ChatID: "@channel", Text: "first", Attempts: tc.attempts},
+				{ID: "2", ChatID: "@other", Text: "second"},
+			}}
+			notifier := &scriptedNotifier{errs: map[string]error{"@channel": tc.err}}
+			sut := newTestOutboxWorker(store, notifier)
+
+			// Act
+			err := sut.DeliverDue(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if len(store.deadLettered) != 1 || store.deadLettered[0].ID != "1" || store.deadLettered[0].LastError == "" {
+				t.Errorf("Expected the first message to be dead-lettered with the error, but got %v", store.deadLettered)
+			}
+			if len(store.acked) != 1 || store.acked[0] != "2" {
+				t.Errorf("Expected delivery to continue with the next message, but got acked %v", store.acked)
+			}
+		})
+	}
+}
+
+func TestOutboxDeliverDue_WhenFirstMessageFailsOnce_DeliversMessagesInOrderAfterBackoff(t *testing.T) {
+	// Arrange
+	store := &mockOutboxStore{pending: []*OutboxMessage{
+		{ID: "1", ChatID: "@channel", Text: "first"},
+		{ID: "2", ChatID: "@other", Text: "second"},
+	}}
+	notifier := &scriptedNotifier{failures: map[string]int{"@channel": 1}}
+	sut := newTestOutboxWorker(store, notifier)
+
+	// Act
+	firstErr := sut.DeliverDue(context.Background())
+	beforeRetryErr := sut.DeliverDue(context.Background())
+	sentBeforeRetry := slices.Clone(notifier.sent)
+	store.pending[0].NextAttemptAt = time.Now().Add(-time.Millisecond)
+	retryErr := sut.DeliverDue(context.Background())
+
+	// Assert
+	if firstErr != nil || beforeRetryErr != nil || retryErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v", firstErr, beforeRetryErr, retryErr)
+	}
+	if len(sentBeforeRetry) != 0 {
+		t.Errorf("Expected nothing to be sent before the retry of the first message, but got %v", sentBeforeRetry)
+	}
+	if !slices.Equal(notifier.sent, []string{"@channel", "@other"}) {
+		t.Errorf("Expected the messages to be sent in order, but got %v", notifier.sent)
+	}
+	if !slices.Equal(store.acked, []string{"1", "2"}) {
+		t.Errorf("Expected both messages to be acknowledged in order, but got %v", store.acked)
+	}
+}
+
+func TestOutboxDeliverDue_WhenOneTargetFails_RetriesOnlyThatTarget(t *testing.T) {
+	// Arrange
+	store := &mockOutboxStore{pending: []*OutboxMessage{{ID: "1", ChatID: "@channel", Text: "first"}}}
+	telegram := &scriptedNotifier{failures: map[string]int{"@channel": 1}}
+	slack := &otherScriptedNotifier{}
+	sut := newTestOutboxWorker(store, telegram, slack)
+
+	// Act
+	firstErr := sut.DeliverDue(context.Background())
+	ackedAfterFailure := len(store.acked)
+	store.pending[0].NextAttemptAt = time.Now().Add(-time.Millisecond)
+	retryErr := sut.DeliverDue(context.Background())
+
+	// Assert
+	if firstErr != nil || retryErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", firstErr, retryErr)
+	}
+	if ackedAfterFailure != 0 {
+		t.Error("Expected the message not to be acknowledged while a target failed")
+	}
+	if len(telegram.sent) != 1 || len(slack.sent) != 1 {
+		t.Errorf("Expected each target to receive the message once, but got %v and %v", telegram.sent, slack.sent)
+	}
+	if !slices.Equal(store.acked, []string{"1"}) {
+		t.Errorf("Expected the message to be acknowledged after the retry, but got %v", store.acked)
+	}
+}
@@ -0,0 +1,242 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/avast/retry-go/v4"
+)
+
+// pushPriority is the importance of a push notification, mapped to the priority scale of each push server.
+type pushPriority int
+
+const (
+	pushPriorityLow pushPriority = iota
+	pushPriorityDefault
+	pushPriorityHigh
+)
+
+// eventPushPriority maps an event to a push priority: an opening and the last tickets are what users wait for,
+// while ticket count changes are routine.
+func eventPushPriority(eventType EventType) pushPriority {
+	switch eventType {
+	case EventQueueOpened, EventTicketsLow:
+		return pushPriorityHigh
+	case EventTicketsChanged:
+		return pushPriorityLow
+	default:
+		return pushPriorityDefault
+	}
+}
+
+// splitPushMessage converts the Telegram HTML message to a plain text title (first line) and body (remaining lines).
+func splitPushMessage(text string) (string, string) {
+	title, body, found := strings.Cut(stripTelegramHTML(text), "\n")
+	if !found {
+		return title, title
+	}
+	return title, body
+}
+
+// NtfyNotifier publishes notifications to ntfy topics. See https://docs.ntfy.sh/publish/
+type NtfyNotifier struct {
+	cfg        *NtfyConfig
+	log        *logger.Logger
+	httpClient *http.Client
+}
+
+func NewNtfyNotifier(cfg *NtfyConfig, log *logger.Logger, httpClient *http.Client) *NtfyNotifier {
+	return &NtfyNotifier{
+		cfg:        cfg,
+		log:        log,
+		httpClient: httpClient,
+	}
+}
+
+// NtfyMessage is the JSON publishing payload of ntfy.
+type NtfyMessage struct {
+	Topic    string `json:"topic"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+	Click    string `json:"click,omitempty"`
+}
+
+var ntfyPriorities = map[pushPriority]int{
+	pushPriorityLow:     2,
+	pushPriorityDefault: 3,
+	pushPriorityHigh:    4,
+}
+
+func (n *NtfyNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return n.publish(ctx, text, pushPriorityDefault)
+}
+
+func (n *NtfyNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	return n.publish(ctx, event.Text, eventPushPriority(event.Type))
+}
+
+func (n *NtfyNotifier) publish(ctx context.Context, text string, priority pushPriority) error {
+	title, body := splitPushMessage(text)
+
+	headers := map[string]string{}
+	if n.cfg.Token != "" {
+		headers["Authorization"] = "Bearer " + n.cfg.Token
+	}
+
+	var errs []error
+	for _, topic := range n.cfg.Topics {
+		msg := NtfyMessage{
+			Topic:    topic,
+			Title:    title,
+			Message:  body,
+			Priority: ntfyPriorities[priority],
+			Click:    n.cfg.ClickUrl,
+		}
+
+		req := pushRequest{
+			service:        "ntfy",
+			url:            strings.TrimRight(n.cfg.BaseUrl, "/"),
+			headers:        headers,
+			body:           msg,
+			maxAttempts:    n.cfg.MaxRetryAttempts,
+			retryDelayMs:   n.cfg.RetryDelayMs,
+			timeoutSeconds: n.cfg.RequestTimeoutSeconds,
+		}
+		if err := req.sendWithRetries(ctx, n.httpClient, n.log); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish to ntfy topic %s: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GotifyNotifier sends notifications to a Gotify application. See https://gotify.net/docs/pushmsg
+type GotifyNotifier struct {
+	cfg        *GotifyConfig
+	log        *logger.Logger
+	httpClient *http.Client
+}
+
+func NewGotifyNotifier(cfg *GotifyConfig, log *logger.Logger, httpClient *http.Client) *GotifyNotifier {
+	return &GotifyNotifier{
+		cfg:        cfg,
+		log:        log,
+		httpClient: httpClient,
+	}
+}
+
+// GotifyMessage is the message payload of Gotify. Extras are used to open the click URL when the notification is tapped.
+type GotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+var gotifyPriorities = map[pushPriority]int{
+	pushPriorityLow:     2,
+	pushPriorityDefault: 5,
+	pushPriorityHigh:    8,
+}
+
+func (g *GotifyNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return g.publish(ctx, text, pushPriorityDefault)
+}
+
+func (g *GotifyNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	return g.publish(ctx, event.Text, eventPushPriority(event.Type))
+}
+
+func (g *GotifyNotifier) publish(ctx context.Context, text string, priority pushPriority) error {
+	title, body := splitPushMessage(text)
+
+	msg := GotifyMessage{
+		Title:    title,
+		Message:  body,
+		Priority: gotifyPriorities[priority],
+	}
+	if g.cfg.ClickUrl != "" {
+		msg.Extras = map[string]any{
+			"client::notification": map[string]any{
+				"click": map[string]string{"url": g.cfg.ClickUrl},
+			},
+		}
+	}
+
+	req := pushRequest{
+		service:        "Gotify",
+		url:            strings.TrimRight(g.cfg.BaseUrl, "/") + "/message",
+		headers:        map[string]string{"X-Gotify-Key": g.cfg.Token},
+		body:           msg,
+		maxAttempts:    g.cfg.MaxRetryAttempts,
+		retryDelayMs:   g.cfg.RetryDelayMs,
+		timeoutSeconds: g.cfg.RequestTimeoutSeconds,
+	}
+	return req.sendWithRetries(ctx, g.httpClient, g.log)
+}
+
+// pushRequest is a JSON POST request to a push server, shared by ntfy and Gotify notifiers.
+type pushRequest struct {
+	service        string
+	url            string
+	headers        map[string]string
+	body           any
+	maxAttempts    uint
+	retryDelayMs   uint
+	timeoutSeconds uint
+}
+
+func (p *pushRequest) sendWithRetries(ctx context.Context, httpClient *http.Client, log *logger.Logger) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(p.timeoutSeconds)*time.Second)
+	defer cancel()
+
+	b, err := json.Marshal(p.body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body when sending message to %s: %w", p.service, err)
+	}
+
+	return retry.Do(
+		func() error {
+			req, err := http.NewRequestWithContext(timeoutCtx, "POST", p.url, bytes.NewReader(b))
+			if err != nil {
+				return fmt.Errorf("failed to create HTTP request: %w", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			for k, v := range p.headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to send message to %s: %w", p.service, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				respTxt, err := io.ReadAll(resp.Body)
+				if err != nil {
+					return fmt.Errorf("failed to read response body when sending message to %s. got unsuccessful status code: %d", p.service, resp.StatusCode)
+				}
+
+				return fmt.Errorf("sending message to %s failed. got unsuccessful status code: %d, api response: \"%s\"", p.service, resp.StatusCode, respTxt)
+			}
+
+			log.Info(fmt.Sprintf("Message sent successfully to %s.", p.service))
+			return nil
+		},
+		retry.Attempts(p.maxAttempts),
+		retry.Delay(time.Duration(p.retryDelayMs)*time.Millisecond),
+		retry.DelayType(retry.FixedDelay),
+		retry.Context(timeoutCtx),
+	)
+}
@@ -0,0 +1,151 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testPushText = "🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!\n🧾 Pozostało biletów: <b>10</b>"
+
+func TestNtfyNotify_Always_PublishesToEachTopicWithPriorityFromEventType(t *testing.T) {
+	testCases := []struct {
+		name             string
+		eventType        EventType
+		expectedPriority int
+	}{
+		{"Opening is high priority", EventQueueOpened, 4},
+		{"Tickets change is low priority", EventTicketsChanged, 2},
+		{"Low tickets are high priority", EventTicketsLow, 4},
+		{"Other events have default priority", EventQueueInactive, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var captured []NtfyMessage
+			var authHeaders []string
+			mockNtfy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var msg NtfyMessage
+				json.NewDecoder(r.Body).Decode(&msg)
+				captured = append(captured, msg)
+				authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer mockNtfy.Close()
+
+			cfg := &NtfyConfig{
+				BaseUrl:               mockNtfy.URL,
+				Topics:                []string{"duw-first", "duw-second"},
+				Token:                 "tk_test",
+				ClickUrl:              "https://rezerwacje.duw.pl/",
+				MaxRetryAttempts:      1,
+				RetryDelayMs:          100,
+				RequestTimeoutSeconds: 2,
+			}
+			sut := NewNtfyNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+			// Act
+			err := sut.Notify(context.Background(), &QueueEvent{Type: tc.eventType, Text: testPushText})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected successful publishing, but got error: %v", err)
+			}
+
+			expected := []NtfyMessage{
+				{Topic: "duw-first", Title: "🔔 Kolejka Odbiór karty jest teraz dostępna!", Message: "🧾 Pozostało biletów: 10", Priority: tc.expectedPriority, Click: "https://rezerwacje.duw.pl/"},
+				{Topic: "duw-second", Title: "🔔 Kolejka Odbiór karty jest teraz dostępna!", Message: "🧾 Pozostało biletów: 10", Priority: tc.expectedPriority, Click: "https://rezerwacje.duw.pl/"},
+			}
+			if diff := cmp.Diff(expected, captured); diff != "" {
+				t.Errorf("ntfy messages mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, h := range authHeaders {
+				if h != "Bearer tk_test" {
+					t.Errorf("Expected bearer token authorization, but got '%s'", h)
+				}
+			}
+		})
+	}
+}
+
+func TestGotifyNotify_Always_SendsMessageWithTokenPriorityAndClickUrl(t *testing.T) {
+	// Arrange
+	var captured map[string]any
+	var tokenHeader, path string
+	mockGotify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&captured)
+		tokenHeader = r.Header.Get("X-Gotify-Key")
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockGotify.Close()
+
+	cfg := &GotifyConfig{
+		BaseUrl:               mockGotify.URL + "/",
+		Token:                 "app-token",
+		ClickUrl:              "https://rezerwacje.duw.pl/",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          100,
+		RequestTimeoutSeconds: 2,
+	}
+	sut := NewGotifyNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, Text: testPushText})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected successful publishing, but got error: %v", err)
+	}
+
+	if path != "/message" || tokenHeader != "app-token" {
+		t.Errorf("Expected request to /message with app token, but got path '%s' and token '%s'", path, tokenHeader)
+	}
+
+	expected := map[string]any{
+		"title":    "🔔 Kolejka Odbiór karty jest teraz dostępna!",
+		"message":  "🧾 Pozostało biletów: 10",
+		"priority": float64(8),
+		"extras": map[string]any{
+			"client::notification": map[string]any{
+				"click": map[string]any{"url": "https://rezerwacje.duw.pl/"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, captured); diff != "" {
+		t.Errorf("Gotify message mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGotifySendMessage_WhenServerReturnsError_ReturnsError(t *testing.T) {
+	// Arrange
+	mockGotify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer mockGotify.Close()
+
+	cfg := &GotifyConfig{
+		BaseUrl:               mockGotify.URL,
+		Token:                 "wrong-token",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          100,
+		RequestTimeoutSeconds: 2,
+	}
+	sut := NewGotifyNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.SendMessage(context.Background(), "", "Test message")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when Gotify rejects the message, but got nil")
+	}
+}
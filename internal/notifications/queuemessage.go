@@ -0,0 +1,27 @@
+package notifications
+
+import "github.com/UladzK/duw-queue-monitor/internal/templates"
+
+// RenderQueueMessage formats the text of the queue event in the language of the event, for Telegram (HTML),
+// with the template named after the event type. The values come from the DUW API, so they are escaped.
+func RenderQueueMessage(event *QueueEvent) string {
+	data := templates.NotificationData{
+		Event: string(event.Type),
+		State: event.State,
+		Queue: templates.QueueData{
+			ID:               event.QueueID,
+			Name:             EscapeHTML(event.QueueName),
+			Active:           event.Active,
+			Enabled:          event.Enabled,
+			TicketValue:      EscapeHTML(event.TicketValue),
+			TicketsLeft:      event.TicketsLeft,
+			TicketsAtOpening: event.TicketsAtOpening,
+		},
+		BurnRate:  event.BurnRate(),
+		LocalTime: templates.Default.LocalTime(event.OccurredAt),
+	}
+	if !event.OpenedAt.IsZero() {
+		data.Queue.OpenedAt = templates.Default.LocalTime(event.OpenedAt)
+	}
+	return templates.Default.Execute(string(event.Type), event.Language, data)
+}
@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderQueueMessage_Always_RendersEventInItsLanguage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    *QueueEvent
+		expected string
+	}{
+		{
+			"Polish, opened with ticket",
+			&QueueEvent{Type: EventQueueOpened, Language: "pl", QueueName: "Odbiór karty", Enabled: true, TicketValue: "K80", TicketsLeft: 3},
+			"🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>K80</b>\n🧾 Pozostały <b>3</b> bilety",
+		},
+		{
+			"English, tickets changed",
+			&QueueEvent{Type: EventTicketsChanged, Language: "en", QueueName: "Odbiór karty", Enabled: true, TicketsLeft: 1},
+			"🔔 Queue <b>Odbiór karty</b> is now available!\n🧾 <b>1</b> ticket left",
+		},
+		{
+			"Ukrainian, unavailable",
+			&QueueEvent{Type: EventQueueUnavailable, Language: "uk", QueueName: "Odbiór karty", Active: true},
+			"💤 Черга <b>Odbiór karty</b> наразі недоступна.",
+		},
+		{
+			"Belarusian, inactive",
+			&QueueEvent{Type: EventQueueInactive, Language: "be", QueueName: "Odbiór <karty>"},
+			"🌙 Чарга <b>Odbiór &lt;karty&gt;</b> неактыўная — верагодна, скончыліся гадзіны працы DUW.",
+		},
+		{
+			"Unsupported language",
+			&QueueEvent{Type: EventQueueInactive, Language: "de", QueueName: "Odbiór karty"},
+			"🌙 Kolejka <b>Odbiór karty</b> jest nieaktywna — prawdopodobnie koniec godzin pracy DUW.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := RenderQueueMessage(tc.event)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQueueEventBurnRate_Always_ReturnsTicketsTakenPerMinute(t *testing.T) {
+	openedAt := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		event    *QueueEvent
+		expected float64
+	}{
+		{"tickets taken", &QueueEvent{OpenedAt: openedAt, OccurredAt: openedAt.Add(10 * time.Minute), TicketsAtOpening: 50, TicketsLeft: 30}, 2},
+		{"unknown opening", &QueueEvent{OccurredAt: openedAt, TicketsAtOpening: 50, TicketsLeft: 30}, 0},
+		{"tickets added", &QueueEvent{OpenedAt: openedAt, OccurredAt: openedAt.Add(time.Minute), TicketsAtOpening: 10, TicketsLeft: 30}, 0},
+		{"just opened", &QueueEvent{OpenedAt: openedAt, OccurredAt: openedAt, TicketsAtOpening: 50, TicketsLeft: 50}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := tc.event.BurnRate()
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected burn rate %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package notifications
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket allows bursts of up to capacity events and refills at the given rate.
+type tokenBucket struct {
+	mu         sync.Mutex
+	capacity   float64
+	refillRate float64 // tokens per second
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newTokenBucket(capacity int, refillRate float64) *tokenBucket {
+	return &tokenBucket{
+		capacity:   float64(capacity),
+		refillRate: refillRate,
+		tokens:     float64(capacity),
+		lastRefill: time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+// Tokens can go negative, so concurrent callers are queued in order instead of racing for the next token.
+// A nil bucket doesn't limit anything.
+func (b *tokenBucket) reserve() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.lastRefill).Seconds()*b.refillRate)
+	b.lastRefill = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.refillRate * float64(time.Second))
+}
+
+// telegramRateLimiter keeps sending within Telegram limits: about 30 messages per second in total
+// and 20 messages per minute to the same group or channel. See https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+type telegramRateLimiter struct {
+	global *tokenBucket
+
+	mu            sync.Mutex
+	chats         map[string]*tokenBucket
+	chatCapacity  int
+	chatPerSecond float64
+}
+
+// newTelegramRateLimiter creates the limiter from config. Zero rates disable the corresponding limit.
+func newTelegramRateLimiter(cfg *TelegramConfig) *telegramRateLimiter {
+	l := &telegramRateLimiter{
+		chats:         make(map[string]*tokenBucket),
+		chatCapacity:  max(1, int(cfg.ChatRateLimitBurst)),
+		chatPerSecond: float64(cfg.ChatRateLimitPerMinute) / 60,
+	}
+	if cfg.GlobalRateLimitPerSecond > 0 {
+		l.global = newTokenBucket(int(cfg.GlobalRateLimitPerSecond), float64(cfg.GlobalRateLimitPerSecond))
+	}
+	return l
+}
+
+// Wait blocks until a message can be sent to the chat or the context is done.
+func (l *telegramRateLimiter) Wait(ctx context.Context, chatID string) error {
+	delay := max(l.global.reserve(), l.chat(chatID).reserve())
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *telegramRateLimiter) chat(chatID string) *tokenBucket {
+	if l.chatPerSecond == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.chats[chatID]
+	if !ok {
+		bucket = newTokenBucket(l.chatCapacity, l.chatPerSecond)
+		l.chats[chatID] = bucket
+	}
+	return bucket
+}
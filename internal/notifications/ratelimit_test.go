@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTelegramRateLimiterWait_WhenChatBurstExceeded_DelaysOnlyThatChat(t *testing.T) {
+	// Arrange
+	cfg := &TelegramConfig{
+		GlobalRateLimitPerSecond: 30,
+		ChatRateLimitPerMinute:   300, // one message every 200ms
+		ChatRateLimitBurst:       1,
+	}
+	sut := newTelegramRateLimiter(cfg)
+	ctx := context.Background()
+
+	// Act
+	started := time.Now()
+	sut.Wait(ctx, "chat-1")
+	sut.Wait(ctx, "chat-2")
+	otherChatElapsed := time.Since(started)
+	sut.Wait(ctx, "chat-1")
+	sameChatElapsed := time.Since(started)
+
+	// Assert
+	if otherChatElapsed > 50*time.Millisecond {
+		t.Errorf("Expected first messages to different chats not to be delayed, but waited %v", otherChatElapsed)
+	}
+	if sameChatElapsed < 150*time.Millisecond {
+		t.Errorf("Expected second message to the same chat to be delayed by ~200ms, but waited %v", sameChatElapsed)
+	}
+}
+
+func TestTelegramRateLimiterWait_WhenGlobalLimitExceeded_DelaysAllChats(t *testing.T) {
+	// Arrange
+	cfg := &TelegramConfig{
+		GlobalRateLimitPerSecond: 5,
+	}
+	sut := newTelegramRateLimiter(cfg)
+	ctx := context.Background()
+
+	// Act
+	started := time.Now()
+	for i := range 6 {
+		sut.Wait(ctx, string(rune('a'+i)))
+	}
+	elapsed := time.Since(started)
+
+	// Assert
+	if elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the sixth message to wait for a global token (~200ms), but waited %v", elapsed)
+	}
+}
+
+func TestTelegramRateLimiterWait_WhenContextCancelled_ReturnsError(t *testing.T) {
+	// Arrange
+	sut := newTelegramRateLimiter(&TelegramConfig{ChatRateLimitPerMinute: 1, ChatRateLimitBurst: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sut.Wait(ctx, "chat-1")
+
+	// Act
+	err := sut.Wait(ctx, "chat-1")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when context is done before a token is available, but got nil")
+	}
+}
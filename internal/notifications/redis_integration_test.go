@@ -0,0 +1,35 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func initRedisContainer(ctx context.Context, t *testing.T) *redis.Client {
+	req := testcontainers.ContainerRequest{
+		Image:        "redis:latest",
+		Name:         "notifications-redis-integration-test",
+		ExposedPorts: []string{"6379/tcp"},
+		WaitingFor:   wait.ForLog("Ready to accept connections"),
+	}
+
+	redisC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start Redis container: \"%v\". Test cannot be executed", err)
+	}
+	t.Cleanup(func() { testcontainers.CleanupContainer(t, redisC) })
+
+	endpoint, err := redisC.Endpoint(ctx, "")
+	if err != nil {
+		t.Fatalf("Failed to get Redis endpoint: \"%v\". Test cannot be executed", err)
+	}
+
+	return redis.NewClient(&redis.Options{Addr: endpoint})
+}
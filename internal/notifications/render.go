@@ -0,0 +1,162 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// ParseModeHTML is the formatting syntax of the Telegram messages. See https://core.telegram.org/bots/api#formatting-options
+	ParseModeHTML = "HTML"
+
+	// MaxMessageLength is the maximum length of a Telegram message, in UTF-16 code units.
+	MaxMessageLength = 4096
+	// MaxCaptionLength is the maximum length of the caption of a photo, in UTF-16 code units.
+	MaxCaptionLength = 1024
+)
+
+var (
+	ErrEmptyMessage   = errors.New("message is empty")
+	ErrMessageTooLong = fmt.Errorf("message is longer than %d characters", MaxMessageLength)
+	ErrCaptionTooLong = fmt.Errorf("caption is longer than %d characters", MaxCaptionLength)
+	htmlEscaper       = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// EscapeHTML makes the text safe to be included in a message, so it's displayed as is.
+func EscapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
+// MessageLength returns the length of the text as counted by Telegram, i.e. in UTF-16 code units.
+func MessageLength(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16Len(r)
+	}
+	return length
+}
+
+// ValidateMessage checks that the message can be sent as a single Telegram message.
+// The markup is counted as well, so the check is stricter than Telegram's.
+func ValidateMessage(text string) error {
+	switch {
+	case strings.TrimSpace(text) == "":
+		return ErrEmptyMessage
+	case MessageLength(text) > MaxMessageLength:
+		return ErrMessageTooLong
+	}
+	return nil
+}
+
+// SplitMessage splits the HTML text into parts which fit in a Telegram message. The text is split between lines if possible,
+// and never inside a tag or an entity. The elements open at a split are closed at the end of the part and reopened at the
+// start of the next one, so each part is valid by itself.
+func SplitMessage(text string) []string {
+	var parts []string
+	for MessageLength(text) > MaxMessageLength {
+		cut, open := splitPosition(text)
+		parts = append(parts, strings.TrimRight(text[:cut], "\n")+closingTags(open))
+		text = strings.Join(open, "") + strings.TrimLeft(text[cut:], "\n")
+	}
+	return append(parts, text)
+}
+
+// splitPosition returns the byte offset where the first part of the text ends: after the last line which fits,
+// or at the last safe position if the first line is too long by itself. The opening tags of the elements open at the offset
+// are returned as well; the part must have room for their closing tags.
+func splitPosition(text string) (int, []string) {
+	length, lastLineEnd, lastSafe := 0, 0, 0
+	var open, lineEndTags, safeTags []string
+	tagStart, inEntity, content := -1, false, false
+
+	for i, r := range text {
+		if length+utf16Len(r) > MaxMessageLength {
+			break
+		}
+		length += utf16Len(r)
+		next := i + utf8.RuneLen(r)
+
+		if tagStart >= 0 || r == '<' {
+			if tagStart < 0 {
+				tagStart = i
+			}
+			if r != '>' {
+				continue
+			}
+			open = updateOpenTags(open, text[tagStart:next])
+			tagStart = -1
+		} else {
+			// a part must have some text, not only the reopened tags, or the splitting would never end
+			content = true
+			switch r {
+			case '&':
+				inEntity = true
+			case ';', ' ', '\n':
+				inEntity = false
+			}
+		}
+
+		if inEntity || !content || length+len(closingTags(open)) > MaxMessageLength {
+			continue
+		}
+		lastSafe, safeTags = next, open
+		if r == '\n' {
+			lastLineEnd, lineEndTags = next, open
+		}
+	}
+
+	switch {
+	case lastLineEnd > 0:
+		return lastLineEnd, lineEndTags
+	case lastSafe > 0:
+		return lastSafe, safeTags
+	default:
+		// a single tag or entity longer than the limit, which Telegram would reject anyway
+		_, size := utf8.DecodeRuneInString(text)
+		return size, nil
+	}
+}
+
+// updateOpenTags returns the opening tags of the elements open after the tag. The slice is never modified in place,
+// so the earlier results stay valid.
+func updateOpenTags(open []string, tag string) []string {
+	if name, ok := strings.CutPrefix(tag, "</"); ok {
+		name = strings.TrimSuffix(name, ">")
+		for i := len(open) - 1; i >= 0; i-- {
+			if tagName(open[i]) == name {
+				return append(open[:i:i], open[i+1:]...)
+			}
+		}
+		return open
+	}
+	if strings.HasSuffix(tag, "/>") {
+		return open
+	}
+	return append(open[:len(open):len(open)], tag)
+}
+
+// closingTags returns the closing tags of the open elements, innermost first.
+func closingTags(open []string) string {
+	var sb strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		sb.WriteString("</" + tagName(open[i]) + ">")
+	}
+	return sb.String()
+}
+
+func tagName(tag string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(tag, "<"), ">")
+	if i := strings.IndexAny(name, " \t\n"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,145 @@
+package notifications
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEscapeHTML_EscapesMarkupAndEntities(t *testing.T) {
+	// Act
+	actual := EscapeHTML(`A & "B" <C>`)
+
+	// Assert
+	if expected := "A &amp; &quot;B&quot; &lt;C&gt;"; actual != expected {
+		t.Errorf("Expected %q, but got %q", expected, actual)
+	}
+}
+
+func TestValidateMessage_WhenMessageCannotBeSent_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected error
+	}{
+		{"Valid", "hello", nil},
+		{"Empty", " \n", ErrEmptyMessage},
+		{"Exactly at limit", strings.Repeat("a", MaxMessageLength), nil},
+		{"Too long", strings.Repeat("a", MaxMessageLength+1), ErrMessageTooLong},
+		{"Too long in UTF-16", strings.Repeat("🎟", MaxMessageLength/2+1), ErrMessageTooLong},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := ValidateMessage(tc.text)
+
+			// Assert
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected error %v, but got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestSplitMessage_WhenMessageIsTooLong_SplitsBetweenLines(t *testing.T) {
+	// Arrange
+	line := strings.Repeat("a", 1000)
+	text := strings.Join([]string{line, line, line, line, line}, "\n")
+
+	// Act
+	parts := SplitMessage(text)
+
+	// Assert
+	expected := []string{strings.Join([]string{line, line, line, line}, "\n"), line}
+	if diff := cmp.Diff(expected, parts); diff != "" {
+		t.Errorf("Unexpected parts (-want +got):\n%s", diff)
+	}
+}
+
+func TestSplitMessage_WhenLineIsTooLong_DoesNotSplitMarkup(t *testing.T) {
+	testCases := []struct {
+		name           string
+		text           string
+		expectedPrefix string
+	}{
+		{"Entity", strings.Repeat("a", MaxMessageLength-2) + "&amp;b", strings.Repeat("a", MaxMessageLength-2)},
+		{"Tag", strings.Repeat("a", MaxMessageLength-2) + "<b>x</b>", strings.Repeat("a", MaxMessageLength-2)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			parts := SplitMessage(tc.text)
+
+			// Assert
+			if len(parts) != 2 || parts[0] != tc.expectedPrefix || parts[0]+parts[1] != tc.text {
+				t.Errorf("Expected the markup to start the second part, but got %q", parts[len(parts)-1])
+			}
+		})
+	}
+}
+
+func TestSplitMessage_WhenMessageFits_ReturnsItAsIs(t *testing.T) {
+	// Act
+	parts := SplitMessage("<b>short</b>")
+
+	// Assert
+	if diff := cmp.Diff([]string{"<b>short</b>"}, parts); diff != "" {
+		t.Errorf("Unexpected parts (-want +got):\n%s", diff)
+	}
+}
+
+func TestSplitMessage_WhenElementSpansSplit_ClosesAndReopensIt(t *testing.T) {
+	line := strings.Repeat("a", 1000)
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			"Between lines",
+			`<a href="https://duw.pl"><b>` + strings.Join([]string{line, line, line, line, line}, "\n") + "</b></a> end",
+			[]string{
+				`<a href="https://duw.pl"><b>` + strings.Join([]string{line, line, line, line}, "\n") + "</b></a>",
+				`<a href="https://duw.pl"><b>` + line + "</b></a> end",
+			},
+		},
+		{
+			"Inside a line, with room for the closing tag",
+			"<b>" + strings.Repeat("a", 5000) + "</b>",
+			[]string{
+				"<b>" + strings.Repeat("a", MaxMessageLength-len("<b></b>")) + "</b>",
+				"<b>" + strings.Repeat("a", 5000-MaxMessageLength+len("<b></b>")) + "</b>",
+			},
+		},
+		{
+			"Closed element is not reopened",
+			"<i>x</i>" + strings.Join([]string{line, line, line, line, "<b>" + line + "</b>"}, "\n"),
+			[]string{
+				"<i>x</i>" + strings.Join([]string{line, line, line, line}, "\n"),
+				"<b>" + line + "</b>",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			parts := SplitMessage(tc.text)
+
+			// Assert
+			if diff := cmp.Diff(tc.expected, parts); diff != "" {
+				t.Errorf("Unexpected parts (-want +got):\n%s", diff)
+			}
+			for _, part := range parts {
+				if err := ValidateMessage(part); err != nil {
+					t.Errorf("Expected every part to fit in a message, got %v", err)
+				}
+			}
+		})
+	}
+}
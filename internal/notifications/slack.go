@@ -0,0 +1,224 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/avast/retry-go/v4"
+)
+
+const (
+	slackFieldTicketValue = "Ostatni przywołany bilet"
+	slackFieldTicketsLeft = "Pozostało biletów"
+	slackContextTemplate  = "Kolejka: *%s* • %s"
+	slackHeaderMaxLength  = 150 // Slack rejects header blocks with longer text
+	slackTimeLayout       = "02.01.2006 15:04:05 MST"
+)
+
+// SlackNotifier sends notifications to a Slack channel using an incoming webhook.
+// The channel is defined by the webhook itself, so chat IDs passed to the notifier are ignored.
+type SlackNotifier struct {
+	cfg        *SlackConfig
+	log        *logger.Logger
+	httpClient *http.Client
+}
+
+func NewSlackNotifier(cfg *SlackConfig, log *logger.Logger, httpClient *http.Client) *SlackNotifier {
+	return &SlackNotifier{
+		cfg:        cfg,
+		log:        log,
+		httpClient: httpClient,
+	}
+}
+
+// SlackMessage is the payload of the incoming webhook. Text is used as a fallback for notifications and clients not supporting blocks.
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a Block Kit layout block. Only the subset used by the notifier is supported.
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Fields   []SlackText `json:"fields,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+// SlackText is a Block Kit text object: either "plain_text" or "mrkdwn".
+type SlackText struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+func (s *SlackNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	mrkdwn := telegramHTMLToMrkdwn(text)
+
+	msg := SlackMessage{
+		Text: mrkdwn,
+		Blocks: []SlackBlock{
+			{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: mrkdwn}},
+		},
+	}
+
+	return s.sendMessageWithRetries(ctx, msg)
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	return s.sendMessageWithRetries(ctx, buildSlackMessage(event))
+}
+
+func buildSlackMessage(event *QueueEvent) SlackMessage {
+	fallback := telegramHTMLToMrkdwn(event.Text)
+
+	header, _, _ := strings.Cut(stripTelegramHTML(event.Text), "\n")
+	if header == "" {
+		header = event.QueueName
+	}
+	if r := []rune(header); len(r) > slackHeaderMaxLength {
+		header = string(r[:slackHeaderMaxLength-1]) + "…"
+	}
+
+	blocks := []SlackBlock{
+		{Type: "header", Text: &SlackText{Type: "plain_text", Text: header, Emoji: true}},
+		{Type: "context", Elements: []SlackText{{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf(slackContextTemplate, escapeMrkdwn(event.QueueName), event.OccurredAt.Format(slackTimeLayout)),
+		}}},
+	}
+
+	if event.Active && event.Enabled {
+		var fields []SlackText
+		if event.TicketValue != "" {
+			fields = append(fields, slackField(slackFieldTicketValue, event.TicketValue))
+		}
+		fields = append(fields, slackField(slackFieldTicketsLeft, strconv.Itoa(event.TicketsLeft)))
+		blocks = append(blocks, SlackBlock{Type: "section", Fields: fields})
+	}
+
+	return SlackMessage{Text: fallback, Blocks: blocks}
+}
+
+func slackField(label, value string) SlackText {
+	return SlackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", escapeMrkdwn(label), escapeMrkdwn(value))}
+}
+
+func (s *SlackNotifier) sendMessageWithRetries(ctx context.Context, msg SlackMessage) error {
+	requestTimeout := time.Duration(s.cfg.RequestTimeoutSeconds) * time.Second
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	retryDelay := time.Duration(s.cfg.RetryDelayMs) * time.Millisecond
+
+	return retry.Do(
+		func() error {
+			b, err := json.Marshal(msg)
+			if err != nil {
+				return fmt.Errorf("failed to marshal request body when sending message to Slack: %w", err)
+			}
+
+			req, err := http.NewRequestWithContext(timeoutCtx, "POST", s.cfg.WebhookUrl, bytes.NewBuffer(b))
+			if err != nil {
+				return fmt.Errorf("failed to create HTTP request: %w", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := s.httpClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to send message to Slack: %w", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				respTxt, err := io.ReadAll(resp.Body)
+				if err != nil {
+					return fmt.Errorf("failed to read response body when sending message to Slack. got unsuccessful status code: %d", resp.StatusCode)
+				}
+
+				return fmt.Errorf("sending message to Slack failed. got unsuccessful status code: %d, api response: \"%s\"", resp.StatusCode, respTxt)
+			}
+
+			s.log.Info("Message sent successfully to Slack.")
+			return nil
+		},
+		retry.Attempts(s.cfg.MaxRetryAttempts),
+		retry.Delay(retryDelay),
+		retry.DelayType(retry.FixedDelay),
+		retry.Context(timeoutCtx),
+	)
+}
+
+var (
+	telegramTagRegex  = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)([^>]*)>`)
+	telegramHrefRegex = regexp.MustCompile(`href\s*=\s*"([^"]*)"`)
+)
+
+// telegramHTMLToMrkdwn converts the subset of HTML supported by Telegram to Slack mrkdwn.
+// Unsupported tags (underline, spoiler, etc.) are dropped while their content is kept.
+func telegramHTMLToMrkdwn(s string) string {
+	var sb strings.Builder
+	var openLinks []bool // whether each currently open <a> tag produced a Slack link
+	last := 0
+
+	for _, m := range telegramTagRegex.FindAllStringSubmatchIndex(s, -1) {
+		sb.WriteString(escapeMrkdwn(html.UnescapeString(s[last:m[0]])))
+		last = m[1]
+
+		closing := s[m[2]:m[3]] == "/"
+		switch strings.ToLower(s[m[4]:m[5]]) {
+		case "b", "strong":
+			sb.WriteString("*")
+		case "i", "em":
+			sb.WriteString("_")
+		case "s", "strike", "del":
+			sb.WriteString("~")
+		case "code":
+			sb.WriteString("`")
+		case "pre":
+			sb.WriteString("```")
+		case "a":
+			if closing {
+				if n := len(openLinks); n > 0 {
+					if openLinks[n-1] {
+						sb.WriteString(">")
+					}
+					openLinks = openLinks[:n-1]
+				}
+				continue
+			}
+
+			href := telegramHrefRegex.FindStringSubmatch(s[m[6]:m[7]])
+			if href == nil || href[1] == "" {
+				openLinks = append(openLinks, false)
+				continue
+			}
+			sb.WriteString("<" + escapeMrkdwn(html.UnescapeString(href[1])) + "|")
+			openLinks = append(openLinks, true)
+		}
+	}
+	sb.WriteString(escapeMrkdwn(html.UnescapeString(s[last:])))
+
+	return sb.String()
+}
+
+// stripTelegramHTML removes all tags and returns the plain text.
+func stripTelegramHTML(s string) string {
+	return html.UnescapeString(telegramTagRegex.ReplaceAllString(s, ""))
+}
+
+// escapeMrkdwn escapes the control characters which Slack requires to be HTML-encoded.
+func escapeMrkdwn(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
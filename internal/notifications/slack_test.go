@@ -0,0 +1,164 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSlackNotify_WhenQueueIsEnabled_SendsBlockKitMessageWithHeaderContextAndTicketFields(t *testing.T) {
+	// Arrange
+	var captured SlackMessage
+	mockSlackWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, fmt.Sprintf("Expected Content-Type to be 'application/json' but got '%s'", r.Header.Get("Content-Type")), http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to decode request body: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprint(w, "ok")
+	}))
+	defer mockSlackWebhook.Close()
+
+	cfg := &SlackConfig{
+		WebhookUrl:            mockSlackWebhook.URL,
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          100,
+		RequestTimeoutSeconds: 2,
+	}
+	sut := NewSlackNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	event := &QueueEvent{
+		Type:        EventQueueOpened,
+		Text:        "🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>K80</b>\n🧾 Pozostało biletów: <b>10</b>",
+		QueueName:   "Odbiór karty",
+		Active:      true,
+		Enabled:     true,
+		TicketValue: "K80",
+		TicketsLeft: 10,
+		OccurredAt:  time.Date(2025, 3, 3, 9, 15, 0, 0, time.UTC),
+	}
+
+	expected := SlackMessage{
+		Text: "🔔 Kolejka *Odbiór karty* jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: *K80*\n🧾 Pozostało biletów: *10*",
+		Blocks: []SlackBlock{
+			{Type: "header", Text: &SlackText{Type: "plain_text", Text: "🔔 Kolejka Odbiór karty jest teraz dostępna!", Emoji: true}},
+			{Type: "context", Elements: []SlackText{{Type: "mrkdwn", Text: "Kolejka: *Odbiór karty* • 03.03.2025 09:15:00 UTC"}}},
+			{Type: "section", Fields: []SlackText{
+				{Type: "mrkdwn", Text: "*Ostatni przywołany bilet*\nK80"},
+				{Type: "mrkdwn", Text: "*Pozostało biletów*\n10"},
+			}},
+		},
+	}
+
+	// Act
+	err := sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected successful message sending, but got error: \"%v\"", err)
+	}
+
+	if diff := cmp.Diff(expected, captured); diff != "" {
+		t.Errorf("Slack message mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSlackNotify_WhenQueueIsInactive_SendsMessageWithoutTicketFields(t *testing.T) {
+	// Arrange
+	var captured SlackMessage
+	mockSlackWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&captured)
+		fmt.Fprint(w, "ok")
+	}))
+	defer mockSlackWebhook.Close()
+
+	cfg := &SlackConfig{
+		WebhookUrl:            mockSlackWebhook.URL,
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          100,
+		RequestTimeoutSeconds: 2,
+	}
+	sut := NewSlackNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{
+		Type:      EventQueueInactive,
+		Text:      "🌙 Kolejka <b>test-queue</b> jest nieaktywna — prawdopodobnie koniec godzin pracy DUW.",
+		QueueName: "test-queue",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected successful message sending, but got error: \"%v\"", err)
+	}
+
+	if len(captured.Blocks) != 2 {
+		t.Fatalf("Expected header and context blocks only, but got %d blocks: %+v", len(captured.Blocks), captured.Blocks)
+	}
+}
+
+func TestSlackSendMessage_WhenWebhookReturnsError_ReturnsError(t *testing.T) {
+	// Arrange
+	mockSlackWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer mockSlackWebhook.Close()
+
+	cfg := &SlackConfig{
+		WebhookUrl:            mockSlackWebhook.URL,
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          100,
+		RequestTimeoutSeconds: 2,
+	}
+	sut := NewSlackNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.SendMessage(context.Background(), "ignored", "Test message")
+
+	// Assert
+	if err == nil {
+		t.Fatalf("Expected error when webhook returns bad request, but got nil")
+	}
+}
+
+func TestTelegramHTMLToMrkdwn_ConvertsFormattingCorrectly(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Bold", "Kolejka <b>K1</b> i <strong>K2</strong>", "Kolejka *K1* i *K2*"},
+		{"Italic and strikethrough", "<i>a</i> <em>b</em> <s>c</s> <del>d</del>", "_a_ _b_ ~c~ ~d~"},
+		{"Code and pre", "<code>x</code>\n<pre>y</pre>", "`x`\n```y```"},
+		{"Link", `<a href="https://rezerwacje.duw.pl/?a=1&amp;b=2">DUW</a>`, "<https://rezerwacje.duw.pl/?a=1&amp;b=2|DUW>"},
+		{"Link without href", `<a>DUW</a>`, "DUW"},
+		{"Unsupported tags are dropped", "<u>under</u> <tg-spoiler>spoiler</tg-spoiler>", "under spoiler"},
+		{"Entities are re-escaped for Slack", "1 &lt; 2 &amp;&amp; 3 &gt; 2", "1 &lt; 2 &amp;&amp; 3 &gt; 2"},
+		{"Quotes are unescaped", "&quot;K&quot;", `"K"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := telegramHTMLToMrkdwn(tc.input)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
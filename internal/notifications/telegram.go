@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"time"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
 
@@ -14,78 +17,447 @@ import (
 )
 
 type TelegramNotifier struct {
-	cfg        *TelegramConfig
-	log        *logger.Logger
-	httpClient *http.Client
+	cfg          *TelegramConfig
+	log          *logger.Logger
+	httpClient   *http.Client
+	rateLimiter  *telegramRateLimiter
+	sentMessages SentMessageStore // optional, records the IDs of posted messages
+	topicChatID  string           // the forum supergroup of the topics
+	topicThreads map[int]int64    // forum topic thread of each queue, see ResolveForumTopics
 }
 
 func NewTelegramNotifier(cfg *TelegramConfig, log *logger.Logger, httpClient *http.Client) *TelegramNotifier {
 	return &TelegramNotifier{
-		cfg:        cfg,
-		log:        log,
-		httpClient: httpClient,
+		cfg:         cfg,
+		log:         log,
+		httpClient:  httpClient,
+		rateLimiter: newTelegramRateLimiter(cfg),
 	}
 }
 
+// NewRecordingTelegramNotifier returns a notifier which records every posted message, so it can be cleaned up later.
+func NewRecordingTelegramNotifier(cfg *TelegramConfig, log *logger.Logger, httpClient *http.Client, sentMessages SentMessageStore) *TelegramNotifier {
+	notifier := NewTelegramNotifier(cfg, log, httpClient)
+	notifier.sentMessages = sentMessages
+	return notifier
+}
+
+// WithoutRecording returns a notifier which shares the HTTP client and the rate limits of the bot with this one, but
+// doesn't record the sent messages, e.g. for the direct messages to the bot users, which must not be cleaned up.
+func (s *TelegramNotifier) WithoutRecording() *TelegramNotifier {
+	notifier := *s
+	notifier.sentMessages = nil
+	return &notifier
+}
+
+// TelegramApiError is an unsuccessful response of the Bot API. See https://core.telegram.org/bots/api#making-requests
+type TelegramApiError struct {
+	StatusCode  int
+	Description string
+	RetryAfter  time.Duration // set when the request was rate limited (429)
+}
+
+func (e *TelegramApiError) Error() string {
+	return fmt.Sprintf("TelegramApi returned status code %d: \"%s\"", e.StatusCode, e.Description)
+}
+
+// Permanent reports whether repeating the request can't succeed, e.g. the chat was not found or the bot was blocked.
+func (e *TelegramApiError) Permanent() bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPermanentTelegramError reports whether err is caused by a Telegram error which won't go away on retry.
+func IsPermanentTelegramError(err error) bool {
+	var apiErr *TelegramApiError
+	return errors.As(err, &apiErr) && apiErr.Permanent()
+}
+
+type telegramErrorResponse struct {
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+func parseTelegramApiError(statusCode int, body []byte) *TelegramApiError {
+	apiErr := &TelegramApiError{StatusCode: statusCode, Description: string(body)}
+
+	var resp telegramErrorResponse
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Description != "" {
+		apiErr.Description = resp.Description
+		apiErr.RetryAfter = time.Duration(resp.Parameters.RetryAfter) * time.Second
+	}
+	return apiErr
+}
+
 type SendMessageChannelRequest struct {
+	ChatID              string                `json:"chat_id"`
+	MessageThreadID     int64                 `json:"message_thread_id,omitempty"` // forum topic of a supergroup
+	Text                string                `json:"text"`
+	ParseMode           string                `json:"parse_mode"` // needed to correctly format the message in Telegram
+	DisableNotification bool                  `json:"disable_notification,omitempty"`
+	ReplyMarkup         *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+const (
+	DeliveryPriorityLoud   = "loud"   // members are notified with a sound
+	DeliveryPrioritySilent = "silent" // members receive the message without a sound
+)
+
+// MessageOptions are the delivery options of a sent message.
+// EventType is not sent to Telegram: it's recorded with the message, so the cleanup can tell the ticket updates apart.
+type MessageOptions struct {
+	DisableNotification bool
+	ReplyMarkup         *InlineKeyboardMarkup
+	MessageThreadID     int64
+	EventType           EventType
+}
+
+// EditMessageTextRequest replaces the text of a message sent before.
+type EditMessageTextRequest struct {
+	ChatID      string                `json:"chat_id"`
+	MessageID   int64                 `json:"message_id"`
+	Text        string                `json:"text"`
+	ParseMode   string                `json:"parse_mode"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"` // the keyboard is removed if not sent again
+}
+
+type PinChatMessageRequest struct {
+	ChatID              string `json:"chat_id"`
+	MessageID           int64  `json:"message_id"`
+	DisableNotification bool   `json:"disable_notification"`
+}
+
+type UnpinChatMessageRequest struct {
 	ChatID    string `json:"chat_id"`
-	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode"` // needed to correctly format the message in Telegram
+	MessageID int64  `json:"message_id"`
+}
+
+type DeleteMessagesRequest struct {
+	ChatID     string  `json:"chat_id"`
+	MessageIDs []int64 `json:"message_ids"`
+}
+
+type telegramMessage struct {
+	MessageID int64 `json:"message_id"`
 }
 
 func (s *TelegramNotifier) SendMessage(ctx context.Context, chatID, text string) error {
-	botApiFullUrl := fmt.Sprintf("%s/bot%s/sendMessage", s.cfg.BaseApiUrl, s.cfg.BotToken)
+	return s.send(ctx, chatID, text, MessageOptions{})
+}
+
+// Notify sends the event text with the delivery options configured for the event, e.g. silently for routine updates.
+func (s *TelegramNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	return s.send(ctx, event.ChatID, event.Text, s.EventMessageOptions(event))
+}
+
+// Post sends the post, into the forum topic of its queue if it has one. A photo is sent with the text as its caption,
+// unless the text is too long for a caption.
+func (s *TelegramNotifier) Post(ctx context.Context, post *ChannelPost) error {
+	text := post.Render(post.Language)
+	opts := MessageOptions{DisableNotification: post.Silent}
+	if post.ChatID == s.topicChatID {
+		opts.MessageThreadID = s.topicThreads[post.QueueID]
+	}
+
+	if post.Photo != nil && MessageLength(text) <= MaxCaptionLength {
+		_, err := s.SendPhoto(ctx, post.ChatID, post.Photo, text, opts)
+		return err
+	}
+	_, err := s.PostMessage(ctx, post.ChatID, text, opts)
+	return err
+}
+
+// EventMessageOptions returns the delivery options of the event, based on its configured priority.
+// Events without a configured priority are loud. While tickets can be taken, the queue buttons are attached.
+// Events of queues with a forum topic are sent to the topic, unless they go to another chat, e.g. a language channel.
+func (s *TelegramNotifier) EventMessageOptions(event *QueueEvent) MessageOptions {
+	opts := MessageOptions{
+		DisableNotification: s.cfg.EventPriorities[string(event.Type)] == DeliveryPrioritySilent,
+		EventType:           event.Type,
+	}
+	if event.ChatID == s.topicChatID {
+		opts.MessageThreadID = s.topicThreads[event.QueueID]
+	}
+	if event.Enabled {
+		opts.ReplyMarkup = queueKeyboard(s.cfg.QueueButtons, event.QueueID)
+	}
+	return opts
+}
+
+// send sends the text, split into several messages if it's too long. The buttons are attached to the last message,
+// and only the first one may notify the members.
+func (s *TelegramNotifier) send(ctx context.Context, chatID, text string, opts MessageOptions) error {
+	parts := SplitMessage(text)
+	for i, part := range parts {
+		partOpts := opts
+		if i > 0 {
+			partOpts.DisableNotification = true
+		}
+		if i < len(parts)-1 {
+			partOpts.ReplyMarkup = nil
+		}
+
+		if err := s.sendPart(ctx, chatID, part, partOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TelegramNotifier) sendPart(ctx context.Context, chatID, text string, opts MessageOptions) error {
+	if s.sentMessages != nil {
+		_, err := s.PostMessage(ctx, chatID, text, opts)
+		return err
+	}
+
+	if err := ValidateMessage(text); err != nil {
+		return fmt.Errorf("invalid Telegram message: %w", err)
+	}
+	return s.callWithRetries(ctx, "sendMessage", chatID, newSendMessageChannelRequest(chatID, text, opts), nil)
+}
+
+// PostMessage sends a message and returns its ID, which can be used to edit or pin it later.
+// Unlike SendMessage, it doesn't split long messages: they are rejected.
+func (s *TelegramNotifier) PostMessage(ctx context.Context, chatID, text string, opts MessageOptions) (int64, error) {
+	if err := ValidateMessage(text); err != nil {
+		return 0, fmt.Errorf("invalid Telegram message: %w", err)
+	}
 
-	reqBody := SendMessageChannelRequest{
+	var msg telegramMessage
+	if err := s.callWithRetries(ctx, "sendMessage", chatID, newSendMessageChannelRequest(chatID, text, opts), &msg); err != nil {
+		return 0, err
+	}
+
+	s.recordSentMessage(ctx, chatID, msg.MessageID, text, opts.EventType)
+	return msg.MessageID, nil
+}
+
+// SendPhoto sends a PNG image with the caption and returns the ID of the message. Captions longer than
+// MaxCaptionLength are rejected.
+func (s *TelegramNotifier) SendPhoto(ctx context.Context, chatID string, photo []byte, caption string, opts MessageOptions) (int64, error) {
+	if MessageLength(caption) > MaxCaptionLength {
+		return 0, fmt.Errorf("invalid Telegram caption: %w", ErrCaptionTooLong)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := map[string]string{
+		"chat_id":    chatID,
+		"caption":    caption,
+		"parse_mode": ParseModeHTML,
+	}
+	if opts.MessageThreadID != 0 {
+		fields["message_thread_id"] = fmt.Sprint(opts.MessageThreadID)
+	}
+	if opts.DisableNotification {
+		fields["disable_notification"] = "true"
+	}
+	if opts.ReplyMarkup != nil {
+		markup, err := json.Marshal(opts.ReplyMarkup)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal reply markup of the photo: %w", err)
+		}
+		fields["reply_markup"] = string(markup)
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return 0, fmt.Errorf("failed to build sendPhoto request: %w", err)
+		}
+	}
+	file, err := form.CreateFormFile("photo", "photo.png")
+	if err != nil {
+		return 0, fmt.Errorf("failed to build sendPhoto request: %w", err)
+	}
+	if _, err := file.Write(photo); err != nil {
+		return 0, fmt.Errorf("failed to build sendPhoto request: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return 0, fmt.Errorf("failed to build sendPhoto request: %w", err)
+	}
+
+	var msg telegramMessage
+	if err := s.postWithRetries(ctx, "sendPhoto", chatID, form.FormDataContentType(), body.Bytes(), &msg); err != nil {
+		return 0, err
+	}
+	s.recordSentMessage(ctx, chatID, msg.MessageID, caption, opts.EventType)
+	return msg.MessageID, nil
+}
+
+func (s *TelegramNotifier) recordSentMessage(ctx context.Context, chatID string, messageID int64, text string, eventType EventType) {
+	if s.sentMessages == nil {
+		return
+	}
+	sent := &SentMessage{MessageID: messageID, Text: text, SentAt: time.Now().UTC(), EventType: eventType}
+	if err := s.sentMessages.Add(ctx, chatID, sent); err != nil {
+		s.log.Error("Failed to record sent message", err, "chatId", chatID, "messageId", messageID)
+	}
+}
+
+func newSendMessageChannelRequest(chatID, text string, opts MessageOptions) SendMessageChannelRequest {
+	return SendMessageChannelRequest{
+		ChatID:              chatID,
+		MessageThreadID:     opts.MessageThreadID,
+		Text:                text,
+		ParseMode:           ParseModeHTML,
+		DisableNotification: opts.DisableNotification,
+		ReplyMarkup:         opts.ReplyMarkup,
+	}
+}
+
+// EditMessageText replaces the text of the message. Only the reply markup of opts is used: edits never notify.
+func (s *TelegramNotifier) EditMessageText(ctx context.Context, chatID string, messageID int64, text string, opts MessageOptions) error {
+	if err := ValidateMessage(text); err != nil {
+		return fmt.Errorf("invalid Telegram message: %w", err)
+	}
+
+	reqBody := EditMessageTextRequest{
+		ChatID:      chatID,
+		MessageID:   messageID,
+		Text:        text,
+		ParseMode:   ParseModeHTML,
+		ReplyMarkup: opts.ReplyMarkup,
+	}
+	return s.callWithRetries(ctx, "editMessageText", chatID, reqBody, nil)
+}
+
+// PinChatMessage pins the message silently: the message itself has already notified the members.
+func (s *TelegramNotifier) PinChatMessage(ctx context.Context, chatID string, messageID int64) error {
+	reqBody := PinChatMessageRequest{
+		ChatID:              chatID,
+		MessageID:           messageID,
+		DisableNotification: true,
+	}
+	return s.callWithRetries(ctx, "pinChatMessage", chatID, reqBody, nil)
+}
+
+func (s *TelegramNotifier) UnpinChatMessage(ctx context.Context, chatID string, messageID int64) error {
+	reqBody := UnpinChatMessageRequest{
 		ChatID:    chatID,
-		Text:      text,
-		ParseMode: "HTML",
+		MessageID: messageID,
 	}
+	return s.callWithRetries(ctx, "unpinChatMessage", chatID, reqBody, nil)
+}
 
-	return s.sendMessageWithRetries(ctx, botApiFullUrl, reqBody)
+// DeleteMessages deletes up to 100 messages at once. Messages which can't be deleted, e.g. older than 48 hours, are skipped by Telegram.
+func (s *TelegramNotifier) DeleteMessages(ctx context.Context, chatID string, messageIDs []int64) error {
+	reqBody := DeleteMessagesRequest{
+		ChatID:     chatID,
+		MessageIDs: messageIDs,
+	}
+	return s.callWithRetries(ctx, "deleteMessages", chatID, reqBody, nil)
 }
 
-func (s *TelegramNotifier) sendMessageWithRetries(ctx context.Context, url string, reqBody SendMessageChannelRequest) error {
-	requestTimeout := time.Duration(s.cfg.RequestTimeoutSeconds) * time.Second
-	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
-	defer cancel()
+// callWithRetries calls the Bot API method and decodes its result, if result is not nil.
+// It retries transient failures. When rate limited, it waits as long as Telegram advises,
+// and it stops immediately on permanent errors, returning *TelegramApiError.
+func (s *TelegramNotifier) callWithRetries(ctx context.Context, method, chatID string, reqBody, result any) error {
+	b, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body when calling TelegramApi %s: %w", method, err)
+	}
+	return s.postWithRetries(ctx, method, chatID, "application/json", b, result)
+}
 
+// postWithRetries posts the encoded request body to the Bot API method, see callWithRetries.
+func (s *TelegramNotifier) postWithRetries(ctx context.Context, method, chatID, contentType string, b []byte, result any) error {
+	apiUrl := fmt.Sprintf("%s/bot%s/%s", s.cfg.BaseApiUrl, s.cfg.BotToken, method)
+	requestTimeout := time.Duration(s.cfg.RequestTimeoutSeconds) * time.Second
 	retryDelay := time.Duration(s.cfg.RetryDelayMs) * time.Millisecond
+	maxRetryAfter := time.Duration(s.cfg.MaxRetryAfterSeconds) * time.Second
 
-	return retry.Do(
+	callCtx := ctx
+	if s.cfg.MaxCallSeconds > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, time.Duration(s.cfg.MaxCallSeconds)*time.Second)
+		defer cancel()
+	}
+
+	err := retry.Do(
 		func() error {
-			b, err := json.Marshal(reqBody)
-			if err != nil {
-				return fmt.Errorf("failed to marshal request body when sending message to TelegramApi: %w", err)
+			if err := s.rateLimiter.Wait(callCtx, chatID); err != nil {
+				return retry.Unrecoverable(err)
 			}
 
-			req, err := http.NewRequestWithContext(timeoutCtx, "POST", url, bytes.NewBuffer(b))
+			timeoutCtx, cancel := context.WithTimeout(callCtx, requestTimeout)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(timeoutCtx, "POST", apiUrl, bytes.NewBuffer(b))
 			if err != nil {
-				return fmt.Errorf("failed to create HTTP request: %w", err)
+				return retry.Unrecoverable(fmt.Errorf("failed to create HTTP request: %w", err))
 			}
-			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Type", contentType)
 
 			resp, err := s.httpClient.Do(req)
 			if err != nil {
-				return fmt.Errorf("failed to send message to TelegramApi: %w", err)
+				// the URL of the request contains the bot token
+				var urlErr *url.Error
+				if errors.As(err, &urlErr) {
+					urlErr.URL = logger.Redact(urlErr.URL)
+				}
+				return fmt.Errorf("failed to call TelegramApi %s: %w", method, err)
 			}
 			defer resp.Body.Close()
 
+			respTxt, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return fmt.Errorf("failed to read response body when calling TelegramApi %s. status code: %d", method, resp.StatusCode)
+			}
+
 			if resp.StatusCode != http.StatusOK {
-				respTxt, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return fmt.Errorf("failed to read response body when sending message to TelegramApi. got unsuccessful status code: %d", resp.StatusCode)
+				apiErr := parseTelegramApiError(resp.StatusCode, respTxt)
+				switch {
+				case apiErr.Permanent():
+					return retry.Unrecoverable(fmt.Errorf("calling TelegramApi %s failed permanently: %w", method, apiErr))
+				case apiErr.RetryAfter > maxRetryAfter || !canWait(callCtx, apiErr.RetryAfter):
+					return retry.Unrecoverable(fmt.Errorf("calling TelegramApi %s is rate limited for too long: %w", method, apiErr))
 				}
+				return fmt.Errorf("calling TelegramApi %s failed: %w", method, apiErr)
+			}
 
-				return fmt.Errorf("sending message to TelegramApi failed. got unsuccessful status code: %d, api response: \"%s\"", resp.StatusCode, respTxt)
+			if result != nil {
+				var okResp struct {
+					Result json.RawMessage `json:"result"`
+				}
+				if err := json.Unmarshal(respTxt, &okResp); err != nil || len(okResp.Result) == 0 {
+					return retry.Unrecoverable(fmt.Errorf("failed to decode TelegramApi %s response: \"%s\"", method, respTxt))
+				}
+				if err := json.Unmarshal(okResp.Result, result); err != nil {
+					return retry.Unrecoverable(fmt.Errorf("failed to decode TelegramApi %s result: %w", method, err))
+				}
 			}
 
-			s.log.Info("Message sent successfully to TelegramApi.")
+			s.log.Info(fmt.Sprintf("TelegramApi %s called successfully.", method))
 			return nil
 		},
 		retry.Attempts(s.cfg.MaxRetryAttempts),
 		retry.Delay(retryDelay),
-		retry.DelayType(retry.FixedDelay),
-		retry.Context(timeoutCtx),
+		retry.DelayType(telegramRetryDelay),
+		retry.Context(callCtx),
+		retry.LastErrorOnly(true),
 	)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("calling TelegramApi %s took longer than %ds, giving up: %w", method, s.cfg.MaxCallSeconds, err)
+	}
+	return err
+}
+
+// canWait returns whether the context lasts longer than the wait.
+func canWait(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > wait
+}
+
+// telegramRetryDelay waits as long as advised by retry_after, or the configured delay otherwise.
+func telegramRetryDelay(n uint, err error, config *retry.Config) time.Duration {
+	var apiErr *TelegramApiError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	return retry.FixedDelay(n, err, config)
 }
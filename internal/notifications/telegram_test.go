@@ -3,10 +3,13 @@ package notifications
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
 )
 
@@ -113,3 +116,435 @@ func TestSendMessage_WhenApiReturnsError_ReturnsError(t *testing.T) {
 		t.Fatalf("Expected error when API returns bad request, but got nil")
 	}
 }
+
+func TestSendMessage_WhenRateLimited_WaitsRetryAfterAndRetries(t *testing.T) {
+	// Arrange
+	requests := 0
+	mockTelegramApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintln(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+			return
+		}
+		fmt.Fprintln(w, `{"ok":true}`)
+	}))
+	defer mockTelegramApi.Close()
+
+	cfg := &TelegramConfig{
+		BaseApiUrl:            mockTelegramApi.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      3,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+		MaxRetryAfterSeconds:  5,
+	}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	started := time.Now()
+	err := sut.SendMessage(context.Background(), "123456789", "Test message")
+	elapsed := time.Since(started)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected message to be sent after waiting, but got error: \"%v\"", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, but got %d", requests)
+	}
+	if elapsed < time.Second {
+		t.Errorf("Expected to wait retry_after (1s) before retrying, but retried after %v", elapsed)
+	}
+}
+
+func TestSendMessage_WhenCallTakesTooLong_GivesUp(t *testing.T) {
+	testCases := []struct {
+		name        string
+		statusCode  int
+		body        string
+		maxDuration time.Duration
+	}{
+		{"Retries exceed the limit", http.StatusBadGateway, "", 2 * time.Second},
+		{"Retry after exceeds the limit", http.StatusTooManyRequests, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5}}`, 500 * time.Millisecond},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			mockTelegramApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statusCode)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer mockTelegramApi.Close()
+
+			cfg := &TelegramConfig{
+				BaseApiUrl:            mockTelegramApi.URL,
+				BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+				MaxRetryAttempts:      1000,
+				RetryDelayMs:          100,
+				RequestTimeoutSeconds: 2,
+				MaxRetryAfterSeconds:  60,
+				MaxCallSeconds:        1,
+			}
+			sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+			// Act
+			started := time.Now()
+			err := sut.SendMessage(context.Background(), "123456789", "Test message")
+			elapsed := time.Since(started)
+
+			// Assert
+			if err == nil {
+				t.Fatal("Expected error when the call takes too long, but got nil")
+			}
+			if elapsed > tc.maxDuration {
+				t.Errorf("Expected to give up within %v, but took %v", tc.maxDuration, elapsed)
+			}
+		})
+	}
+}
+
+func TestSendMessage_WhenErrorIsPermanent_StopsRetryingAndReturnsTypedError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		statusCode  int
+		description string
+	}{
+		{"Chat not found", http.StatusBadRequest, "Bad Request: chat not found"},
+		{"Bot was blocked", http.StatusForbidden, "Forbidden: bot was blocked by the user"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			requests := 0
+			mockTelegramApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tc.statusCode)
+				fmt.Fprintf(w, `{"ok":false,"error_code":%d,"description":"%s"}`, tc.statusCode, tc.description)
+			}))
+			defer mockTelegramApi.Close()
+
+			cfg := &TelegramConfig{
+				BaseApiUrl:            mockTelegramApi.URL,
+				BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+				MaxRetryAttempts:      5,
+				RetryDelayMs:          10,
+				RequestTimeoutSeconds: 2,
+			}
+			sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+			// Act
+			err := sut.SendMessage(context.Background(), "123456789", "Test message")
+
+			// Assert
+			if !IsPermanentTelegramError(err) {
+				t.Fatalf("Expected permanent error, but got \"%v\"", err)
+			}
+
+			var apiErr *TelegramApiError
+			errors.As(err, &apiErr)
+			if apiErr.StatusCode != tc.statusCode || apiErr.Description != tc.description {
+				t.Errorf("Unexpected error details: %+v", apiErr)
+			}
+
+			if requests != 1 {
+				t.Errorf("Expected no retries on permanent error, but got %d requests", requests)
+			}
+		})
+	}
+}
+
+func TestSendMessage_WhenServerErrorIsTransient_RetriesUntilSuccess(t *testing.T) {
+	// Arrange
+	requests := 0
+	mockTelegramApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintln(w, `{"ok":true}`)
+	}))
+	defer mockTelegramApi.Close()
+
+	cfg := &TelegramConfig{
+		BaseApiUrl:            mockTelegramApi.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      5,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+	}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.SendMessage(context.Background(), "123456789", "Test message")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected message to be sent after retries, but got error: \"%v\"", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, but got %d", requests)
+	}
+}
+
+func TestNotify_WhenEventHasConfiguredPriority_SetsDisableNotification(t *testing.T) {
+	priorities := map[string]string{
+		"queue_opened":    DeliveryPriorityLoud,
+		"tickets_low":     DeliveryPriorityLoud,
+		"tickets_changed": DeliveryPrioritySilent,
+	}
+
+	testCases := []struct {
+		name           string
+		event          *QueueEvent
+		expectedSilent bool
+	}{
+		{"Opening is loud", &QueueEvent{Type: EventQueueOpened, TicketsLeft: 100}, false},
+		{"Routine count change is silent", &QueueEvent{Type: EventTicketsChanged, TicketsLeft: 40}, true},
+		{"Low tickets alert is loud", &QueueEvent{Type: EventTicketsLow, TicketsLeft: 5}, false},
+		{"Count change below the alert is silent", &QueueEvent{Type: EventTicketsChanged, TicketsLeft: 4}, true},
+		{"Event without priority is loud", &QueueEvent{Type: EventQueueInactive}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			api := &mockTelegramBotApi{}
+			server := api.start(t)
+
+			cfg := &TelegramConfig{
+				BaseApiUrl:            server.URL,
+				BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+				MaxRetryAttempts:      1,
+				RetryDelayMs:          10,
+				RequestTimeoutSeconds: 2,
+				EventPriorities:       priorities,
+			}
+			sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+			tc.event.ChatID = "@channel"
+			tc.event.Text = "update"
+
+			// Act
+			err := sut.Notify(context.Background(), tc.event)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			silent, _ := api.calls[0].body["disable_notification"].(bool)
+			if silent != tc.expectedSilent {
+				t.Errorf("Expected disable_notification to be %v, but got %v", tc.expectedSilent, silent)
+			}
+		})
+	}
+}
+
+func TestNotify_WhenQueueButtonsConfigured_AttachesKeyboardOnlyWhileQueueIsEnabled(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+		QueueButtons:          []QueueButton{{QueueID: 24, Text: "Rezerwacja", Url: "https://rezerwacje.duw.pl/"}},
+	}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	openedErr := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, ChatID: "@channel", Text: "opened", QueueID: 24, Enabled: true})
+	inactiveErr := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueInactive, ChatID: "@channel", Text: "inactive", QueueID: 24})
+
+	// Assert
+	if openedErr != nil || inactiveErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", openedErr, inactiveErr)
+	}
+
+	expectedMarkup := `map[inline_keyboard:[[map[text:Rezerwacja url:https://rezerwacje.duw.pl/]]]]`
+	if markup := fmt.Sprint(api.calls[0].body["reply_markup"]); markup != expectedMarkup {
+		t.Errorf("Expected reply_markup %s, but got %s", expectedMarkup, markup)
+	}
+	if _, ok := api.calls[1].body["reply_markup"]; ok {
+		t.Errorf("Expected no reply_markup for inactive queue, but got %v", api.calls[1].body["reply_markup"])
+	}
+}
+
+func TestSendMessage_WhenMessageIsTooLong_SendsItInPartsWithButtonsOnLastOne(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+		QueueButtons:          []QueueButton{{Text: "Rezerwacja", Url: "https://rezerwacje.duw.pl/"}},
+	}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	line := strings.Repeat("a", 3000)
+	event := &QueueEvent{Type: EventQueueOpened, ChatID: "@channel", Text: line + "\n" + line, Enabled: true}
+
+	// Act
+	err := sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(api.calls) != 2 {
+		t.Fatalf("Expected 2 messages, but got %d", len(api.calls))
+	}
+
+	first, last := api.calls[0].body, api.calls[1].body
+	if first["text"] != line || last["text"] != line {
+		t.Error("Expected the message to be split between lines")
+	}
+	if _, ok := first["reply_markup"]; ok || last["reply_markup"] == nil {
+		t.Error("Expected the buttons on the last part only")
+	}
+	if first["disable_notification"] == true || last["disable_notification"] != true {
+		t.Error("Expected only the first part to notify")
+	}
+}
+
+func TestSendMessage_WhenMessageIsEmpty_ReturnsErrorWithoutCallingApi(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{BaseApiUrl: server.URL, BotToken: "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ", MaxRetryAttempts: 1}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	err := sut.SendMessage(context.Background(), "@channel", "")
+
+	// Assert
+	if !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("Expected ErrEmptyMessage, but got %v", err)
+	}
+	if len(api.calls) != 0 {
+		t.Errorf("Expected no Telegram calls, but got %v", api.methods())
+	}
+}
+
+func TestSendPhoto_WhenRequestSuccessful_UploadsPhotoWithCaptionAndReturnsMessageID(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{nextMessageID: 41}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{BaseApiUrl: server.URL, BotToken: "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ", MaxRetryAttempts: 1, RequestTimeoutSeconds: 2}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	messageID, err := sut.SendPhoto(context.Background(), "@channel", []byte("png"), "<b>Summary</b>", MessageOptions{DisableNotification: true})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if messageID != 42 {
+		t.Errorf("Expected message ID 42, got %d", messageID)
+	}
+	if fmt.Sprint(api.methods()) != "[sendPhoto]" {
+		t.Fatalf("Expected a single sendPhoto call, got %v", api.methods())
+	}
+	expected := map[string]any{
+		"chat_id":              "@channel",
+		"caption":              "<b>Summary</b>",
+		"parse_mode":           "HTML",
+		"disable_notification": "true",
+		"photo":                "png",
+	}
+	if fmt.Sprint(api.calls[0].body) != fmt.Sprint(expected) {
+		t.Errorf("Expected request %v, got %v", expected, api.calls[0].body)
+	}
+}
+
+func TestSendPhoto_WhenCaptionIsTooLong_ReturnsErrorWithoutCallingApi(t *testing.T) {
+	// Arrange
+	api := &mockTelegramBotApi{}
+	server := api.start(t)
+
+	cfg := &TelegramConfig{BaseApiUrl: server.URL, BotToken: "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ", MaxRetryAttempts: 1}
+	sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+
+	// Act
+	_, err := sut.SendPhoto(context.Background(), "@channel", []byte("png"), strings.Repeat("a", MaxCaptionLength+1), MessageOptions{})
+
+	// Assert
+	if !errors.Is(err, ErrCaptionTooLong) {
+		t.Errorf("Expected ErrCaptionTooLong, but got %v", err)
+	}
+	if len(api.calls) != 0 {
+		t.Errorf("Expected no Telegram calls, but got %v", api.methods())
+	}
+}
+
+func TestPost_WhenQueueHasForumTopic_SendsPhotoWithCaptionToTopicOrTextIfCaptionIsTooLong(t *testing.T) {
+	testCases := []struct {
+		name           string
+		text           string
+		expectedMethod string
+	}{
+		{"Short text is the caption", "<b>Summary</b>", "sendPhoto"},
+		{"Long text is sent without photo", strings.Repeat("a", MaxCaptionLength+1), "sendMessage"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			api := &mockTelegramBotApi{}
+			server := api.start(t)
+
+			cfg := &TelegramConfig{
+				BaseApiUrl:            server.URL,
+				BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+				MaxRetryAttempts:      1,
+				RequestTimeoutSeconds: 2,
+				ForumTopics:           []ForumTopic{{QueueID: 24, Name: "Karty pobytu", ThreadID: 15}},
+			}
+			sut := NewTelegramNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{})
+			if err := sut.ResolveForumTopics(context.Background(), "@group", &inMemoryForumTopicStore{threads: map[string]int64{}}); err != nil {
+				t.Fatalf("Failed to resolve forum topics: %v", err)
+			}
+
+			post := &ChannelPost{
+				ChatID:   "@group",
+				QueueID:  24,
+				Language: "pl",
+				Render:   func(language string) string { return tc.text },
+				Photo:    []byte("png"),
+				Silent:   true,
+			}
+
+			// Act
+			err := sut.Post(context.Background(), post)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if fmt.Sprint(api.methods()) != fmt.Sprintf("[%s]", tc.expectedMethod) {
+				t.Fatalf("Expected a single %s call, got %v", tc.expectedMethod, api.methods())
+			}
+			// the multipart photo upload sends the values as strings
+			if fmt.Sprint(api.calls[0].body["message_thread_id"]) != "15" {
+				t.Errorf("Expected the post to be sent to the topic thread 15, got %v", api.calls[0].body["message_thread_id"])
+			}
+			if fmt.Sprint(api.calls[0].body["disable_notification"]) != "true" {
+				t.Errorf("Expected the post to be silent, got %v", api.calls[0].body["disable_notification"])
+			}
+		})
+	}
+}
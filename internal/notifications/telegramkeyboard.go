@@ -0,0 +1,69 @@
+package notifications
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// InlineKeyboardButton is a button shown below a message. Only URL buttons are used.
+// See https://core.telegram.org/bots/api#inlinekeyboardbutton
+type InlineKeyboardButton struct {
+	Text string `json:"text"`
+	Url  string `json:"url"`
+}
+
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// QueueButton is a link shown below the notifications of a queue, e.g. the reservation page or the office in maps.
+// It's configured as "queue|text|url", where queue is the queue ID or "*" for all queues.
+type QueueButton struct {
+	QueueID int // 0 for all queues
+	Text    string
+	Url     string
+}
+
+func (b *QueueButton) UnmarshalText(data []byte) error {
+	parts := strings.SplitN(strings.TrimSpace(string(data)), "|", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid queue button %q, expected \"queue|text|url\"", data)
+	}
+
+	queue, text, link := parts[0], parts[1], parts[2]
+	if queue != "*" {
+		queueID, err := strconv.Atoi(queue)
+		if err != nil || queueID <= 0 {
+			return fmt.Errorf("invalid queue ID in queue button %q", data)
+		}
+		b.QueueID = queueID
+	}
+
+	if text == "" {
+		return fmt.Errorf("empty text in queue button %q", data)
+	}
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid URL in queue button %q", data)
+	}
+
+	b.Text = text
+	b.Url = link
+	return nil
+}
+
+// queueKeyboard returns the buttons configured for the queue, one per row, or nil if there are none.
+func queueKeyboard(buttons []QueueButton, queueID int) *InlineKeyboardMarkup {
+	var rows [][]InlineKeyboardButton
+	for _, b := range buttons {
+		if b.QueueID == 0 || b.QueueID == queueID {
+			rows = append(rows, []InlineKeyboardButton{{Text: b.Text, Url: b.Url}})
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
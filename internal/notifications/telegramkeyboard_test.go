@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQueueButtonUnmarshalText_WhenValueIsValid_ParsesButton(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected QueueButton
+	}{
+		{"*|📅 Rezerwacja|https://rezerwacje.duw.pl/", QueueButton{Text: "📅 Rezerwacja", Url: "https://rezerwacje.duw.pl/"}},
+		{"24|📍 Mapa|https://maps.google.com/?q=51.1,17.0", QueueButton{QueueID: 24, Text: "📍 Mapa", Url: "https://maps.google.com/?q=51.1,17.0"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			// Arrange
+			var sut QueueButton
+
+			// Act
+			err := sut.UnmarshalText([]byte(tc.value))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, sut); diff != "" {
+				t.Errorf("Unexpected button (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQueueButtonUnmarshalText_WhenValueIsInvalid_ReturnsError(t *testing.T) {
+	testCases := []string{
+		"https://rezerwacje.duw.pl/",
+		"abc|Rezerwacja|https://rezerwacje.duw.pl/",
+		"*||https://rezerwacje.duw.pl/",
+		"*|Rezerwacja|javascript:alert(1)",
+	}
+
+	for _, value := range testCases {
+		t.Run(value, func(t *testing.T) {
+			// Arrange
+			var sut QueueButton
+
+			// Act
+			err := sut.UnmarshalText([]byte(value))
+
+			// Assert
+			if err == nil {
+				t.Errorf("Expected an error for %q", value)
+			}
+		})
+	}
+}
+
+func TestQueueKeyboard_ReturnsButtonsOfQueueAndAllQueues(t *testing.T) {
+	// Arrange
+	buttons := []QueueButton{
+		{Text: "Rezerwacja", Url: "https://rezerwacje.duw.pl/"},
+		{QueueID: 24, Text: "Mapa", Url: "https://maps.example/24"},
+		{QueueID: 25, Text: "Info", Url: "https://duw.example/25"},
+	}
+
+	// Act
+	keyboard := queueKeyboard(buttons, 24)
+	noKeyboard := queueKeyboard(nil, 24)
+
+	// Assert
+	expected := &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{
+		{{Text: "Rezerwacja", Url: "https://rezerwacje.duw.pl/"}},
+		{{Text: "Mapa", Url: "https://maps.example/24"}},
+	}}
+	if diff := cmp.Diff(expected, keyboard); diff != "" {
+		t.Errorf("Unexpected keyboard (-want +got):\n%s", diff)
+	}
+	if noKeyboard != nil {
+		t.Errorf("Expected no keyboard without buttons, but got %+v", noKeyboard)
+	}
+}
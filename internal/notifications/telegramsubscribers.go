@@ -0,0 +1,100 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+)
+
+// TelegramSubscribersNotifier sends the queue events as direct messages to the bot users who subscribed to them,
+// in the language of each user. During the quiet hours of a user, the messages are sent without a sound.
+// Users who blocked the bot (403) are unsubscribed.
+// The messages are sent in the background by the direct message queue, so the failures are only logged. Without a queue,
+// they are sent before Notify returns and the failures are returned, e.g. for the outbox worker to retry them.
+type TelegramSubscribersNotifier struct {
+	log      *logger.Logger
+	telegram *TelegramNotifier
+	store    TelegramSubscriptionStore
+	queue    *DirectMessageQueue
+}
+
+func NewTelegramSubscribersNotifier(log *logger.Logger, telegram *TelegramNotifier, store TelegramSubscriptionStore, queue *DirectMessageQueue) *TelegramSubscribersNotifier {
+	return &TelegramSubscribersNotifier{
+		log:      log,
+		telegram: telegram,
+		store:    store,
+		queue:    queue,
+	}
+}
+
+// SendMessage does nothing: subscribers only receive queue events.
+func (n *TelegramSubscribersNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return nil
+}
+
+func (n *TelegramSubscribersNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	if n.queue == nil {
+		return n.deliver(ctx, event)
+	}
+
+	queued := *event
+	n.queue.Enqueue("subscribers", func(ctx context.Context) {
+		if err := n.deliver(ctx, &queued); err != nil {
+			n.log.Error("Failed to notify subscribers", err, "type", queued.Type, "queueId", queued.QueueID)
+		}
+	})
+	return nil
+}
+
+func (n *TelegramSubscribersNotifier) deliver(ctx context.Context, event *QueueEvent) error {
+	subs, err := n.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	delivered := 0
+	for _, sub := range subs {
+		if !sub.Wants(event) {
+			continue
+		}
+
+		err := n.send(ctx, sub, event)
+		switch {
+		case isBotBlocked(err):
+			n.log.Info("User blocked the bot, removing the subscription", "chatId", sub.ChatID)
+			if err := n.store.Remove(ctx, sub.ChatID); err != nil {
+				n.log.Error("Failed to remove Telegram subscription", err, "chatId", sub.ChatID)
+			}
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			delivered++
+		}
+	}
+
+	n.log.Info("Subscriber notifications sent.", "subscriptions", len(subs), "delivered", delivered, "failed", len(errs))
+	return errors.Join(errs...)
+}
+
+func (n *TelegramSubscribersNotifier) send(ctx context.Context, sub *TelegramSubscription, event *QueueEvent) error {
+	personal := *event
+	personal.ChatID = strconv.FormatInt(sub.ChatID, 10)
+	personal.Language = sub.Language
+	personal.Text = RenderQueueMessage(&personal)
+
+	opts := n.telegram.EventMessageOptions(&personal)
+	if sub.QuietHours != nil && sub.QuietHours.Contains(templates.Default.LocalTime(event.OccurredAt)) {
+		opts.DisableNotification = true
+	}
+	return n.telegram.send(ctx, personal.ChatID, personal.Text, opts)
+}
+
+// isBotBlocked reports whether the message can't be delivered because the user blocked the bot or deleted their account.
+func isBotBlocked(err error) bool {
+	var apiErr *TelegramApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,220 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+type inMemoryTelegramSubscriptionStore struct {
+	subs map[int64]*TelegramSubscription
+}
+
+func newInMemoryTelegramSubscriptionStore(subs ...*TelegramSubscription) *inMemoryTelegramSubscriptionStore {
+	store := &inMemoryTelegramSubscriptionStore{subs: make(map[int64]*TelegramSubscription)}
+	for _, sub := range subs {
+		store.subs[sub.ChatID] = sub
+	}
+	return store
+}
+
+func (s *inMemoryTelegramSubscriptionStore) Get(ctx context.Context, chatID int64) (*TelegramSubscription, error) {
+	return s.subs[chatID], nil
+}
+
+func (s *inMemoryTelegramSubscriptionStore) Save(ctx context.Context, sub *TelegramSubscription) error {
+	s.subs[sub.ChatID] = sub
+	return nil
+}
+
+func (s *inMemoryTelegramSubscriptionStore) Remove(ctx context.Context, chatID int64) error {
+	delete(s.subs, chatID)
+	return nil
+}
+
+func (s *inMemoryTelegramSubscriptionStore) List(ctx context.Context) ([]*TelegramSubscription, error) {
+	subs := make([]*TelegramSubscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// startSubscribersTelegramApi records the sent messages by chat ID and rejects the messages to the blocked chats with 403.
+func startSubscribersTelegramApi(t *testing.T, blockedChatIDs ...string) (*httptest.Server, map[string]SendMessageChannelRequest) {
+	sent := make(map[string]SendMessageChannelRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageChannelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if slices.Contains(blockedChatIDs, req.ChatID) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`)
+			return
+		}
+		sent[req.ChatID] = req
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, sent
+}
+
+func newTestTelegramSubscribersNotifier(server *httptest.Server, store TelegramSubscriptionStore) *TelegramSubscribersNotifier {
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+	}
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	return NewTelegramSubscribersNotifier(log, NewTelegramNotifier(cfg, log, &http.Client{}), store, NewDirectMessageQueue(log))
+}
+
+func TestTelegramSubscribersNotifierNotify_Always_SendsInBackground(t *testing.T) {
+	// Arrange
+	server, sent := startSubscribersTelegramApi(t)
+	store := newInMemoryTelegramSubscriptionStore(&TelegramSubscription{ChatID: 1001, Language: "en", Events: SubscriptionEventsOpening})
+	sut := newTestTelegramSubscribersNotifier(server, store)
+
+	event := &QueueEvent{Type: EventQueueOpened, QueueID: 24, QueueName: "Odbiór karty", Active: true, Enabled: true, TicketsLeft: 5}
+
+	// Act
+	err := sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("Expected no messages before the worker runs, got %v", sent)
+	}
+
+	job := <-sut.queue.jobs
+	job(context.Background())
+	if _, ok := sent["1001"]; !ok {
+		t.Error("Expected the worker to send the message to the subscriber")
+	}
+}
+
+func TestTelegramSubscribersNotifierNotify_WhenThereIsNoQueue_SendsBeforeReturningAndReturnsFailures(t *testing.T) {
+	// Arrange
+	server, sent := startSubscribersTelegramApi(t)
+	store := newInMemoryTelegramSubscriptionStore(&TelegramSubscription{ChatID: 1001, Language: "en", Events: SubscriptionEventsOpening})
+	sut := newTestTelegramSubscribersNotifier(server, store)
+	sut.queue = nil
+
+	event := &QueueEvent{Type: EventQueueOpened, QueueID: 24, QueueName: "Odbiór karty", Active: true, Enabled: true, TicketsLeft: 5}
+
+	// Act
+	err := sut.Notify(context.Background(), event)
+	server.Close()
+	errAfterApiIsDown := sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, ok := sent["1001"]; !ok {
+		t.Error("Expected the message to be sent to the subscriber before Notify returns")
+	}
+	if errAfterApiIsDown == nil {
+		t.Error("Expected the failure to be returned")
+	}
+}
+
+func TestTelegramSubscribersNotifierDeliver_Always_SendsEventToMatchingSubscribersInTheirLanguage(t *testing.T) {
+	// Arrange
+	server, sent := startSubscribersTelegramApi(t)
+	store := newInMemoryTelegramSubscriptionStore(
+		&TelegramSubscription{ChatID: 1001, Language: "en", Events: SubscriptionEventsOpening},
+		&TelegramSubscription{ChatID: 1002, Language: "uk", Events: SubscriptionEventsAll, Queues: []int{24}},
+		&TelegramSubscription{ChatID: 1003, Language: "en", Events: SubscriptionEventsAll, Queues: []int{25}},
+	)
+	sut := newTestTelegramSubscribersNotifier(server, store)
+
+	event := &QueueEvent{Type: EventQueueOpened, ChatID: "@duw", Language: "pl", QueueID: 24, QueueName: "Odbiór karty", Active: true, Enabled: true, TicketsLeft: 5}
+
+	// Act
+	err := sut.deliver(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"1001": "🔔 Queue <b>Odbiór karty</b> is now available!\n🧾 <b>5</b> tickets left",
+		"1002": "🔔 Черга <b>Odbiór karty</b> тепер доступна!\n🧾 Залишилося <b>5</b> квитків",
+	}
+	if len(sent) != len(expected) {
+		t.Fatalf("Expected messages to %d subscribers, got %v", len(expected), sent)
+	}
+	for chatID, text := range expected {
+		if sent[chatID].Text != text {
+			t.Errorf("Expected %q to be sent to %s, got %q", text, chatID, sent[chatID].Text)
+		}
+	}
+	if event.ChatID != "@duw" || event.Language != "pl" {
+		t.Errorf("Expected the original event not to be modified, got %+v", event)
+	}
+}
+
+func TestTelegramSubscribersNotifierDeliver_WhenUserBlockedBot_RemovesSubscription(t *testing.T) {
+	// Arrange
+	server, sent := startSubscribersTelegramApi(t, "1002")
+	store := newInMemoryTelegramSubscriptionStore(
+		&TelegramSubscription{ChatID: 1001, Language: "en", Events: SubscriptionEventsOpening},
+		&TelegramSubscription{ChatID: 1002, Language: "en", Events: SubscriptionEventsOpening},
+	)
+	sut := newTestTelegramSubscribersNotifier(server, store)
+
+	// Act
+	err := sut.deliver(context.Background(), &QueueEvent{Type: EventQueueOpened, QueueID: 24, QueueName: "Odbiór karty", Active: true, Enabled: true, TicketsLeft: 5})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected blocked user not to be reported as error, got: %v", err)
+	}
+	if _, ok := sent["1001"]; !ok {
+		t.Error("Expected the other subscriber to receive the message")
+	}
+	if sub, _ := store.Get(context.Background(), 1002); sub != nil {
+		t.Errorf("Expected the subscription of the blocked user to be removed, got %+v", sub)
+	}
+	if sub, _ := store.Get(context.Background(), 1001); sub == nil {
+		t.Error("Expected the other subscription to be kept")
+	}
+}
+
+func TestTelegramSubscribersNotifierDeliver_WhenInQuietHours_SendsWithoutSound(t *testing.T) {
+	// Arrange
+	server, sent := startSubscribersTelegramApi(t)
+	allDay := QuietHours{From: 0, To: 24*time.Hour - time.Minute}
+	store := newInMemoryTelegramSubscriptionStore(
+		&TelegramSubscription{ChatID: 1001, Language: "en", Events: SubscriptionEventsOpening, QuietHours: &allDay},
+		&TelegramSubscription{ChatID: 1002, Language: "en", Events: SubscriptionEventsOpening},
+	)
+	sut := newTestTelegramSubscribersNotifier(server, store)
+
+	// Act
+	err := sut.deliver(context.Background(), &QueueEvent{Type: EventQueueOpened, QueueID: 24, QueueName: "Odbiór karty", Active: true, Enabled: true, TicketsLeft: 5, OccurredAt: time.Date(2026, 7, 1, 21, 0, 0, 0, time.UTC)})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !sent["1001"].DisableNotification {
+		t.Error("Expected the message to be silent during the quiet hours")
+	}
+	if sent["1002"].DisableNotification {
+		t.Error("Expected the message to be loud without quiet hours")
+	}
+}
@@ -0,0 +1,188 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const telegramSubscriptionsRedisKey = "telegram:subscriptions"
+
+// SubscriptionEvents selects the queue events sent to a subscriber.
+type SubscriptionEvents string
+
+const (
+	SubscriptionEventsOpening SubscriptionEvents = "opening" // only when the queue opens
+	SubscriptionEventsLow     SubscriptionEvents = "low"     // when the queue opens and when the tickets run low
+	SubscriptionEventsAll     SubscriptionEvents = "all"     // every change of the queue
+)
+
+// ParseSubscriptionEvents returns the events named by s, e.g. "opening".
+func ParseSubscriptionEvents(s string) (SubscriptionEvents, error) {
+	switch events := SubscriptionEvents(strings.ToLower(s)); events {
+	case SubscriptionEventsOpening, SubscriptionEventsLow, SubscriptionEventsAll:
+		return events, nil
+	default:
+		return "", fmt.Errorf("unknown subscription events %q", s)
+	}
+}
+
+// QuietHours is a daily period in local time, written as "22:00-07:00", when the subscriber receives notifications without a sound.
+// The period may span midnight.
+type QuietHours struct {
+	From time.Duration // since midnight
+	To   time.Duration // since midnight
+}
+
+func (q *QuietHours) UnmarshalText(data []byte) error {
+	from, to, ok := strings.Cut(string(data), "-")
+	if !ok {
+		return fmt.Errorf("invalid quiet hours %q, expected \"HH:MM-HH:MM\"", data)
+	}
+
+	var err error
+	if q.From, err = parseClock(from); err != nil {
+		return fmt.Errorf("invalid quiet hours %q: %w", data, err)
+	}
+	if q.To, err = parseClock(to); err != nil {
+		return fmt.Errorf("invalid quiet hours %q: %w", data, err)
+	}
+	if q.From == q.To {
+		return fmt.Errorf("invalid quiet hours %q: the period is empty", data)
+	}
+	return nil
+}
+
+func (q QuietHours) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+func (q QuietHours) String() string {
+	return formatClock(q.From) + "-" + formatClock(q.To)
+}
+
+// Contains reports whether the local time t falls into the quiet hours.
+func (q *QuietHours) Contains(t time.Time) bool {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.From < q.To {
+		return clock >= q.From && clock < q.To
+	}
+	return clock >= q.From || clock < q.To
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("time must be written as HH:MM")
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// TelegramSubscription is the subscription of a bot user to direct messages about the queues.
+type TelegramSubscription struct {
+	ChatID     int64              `json:"chat_id"` // private chat with the user
+	Language   string             `json:"language"`
+	Events     SubscriptionEvents `json:"events"`
+	Queues     []int              `json:"queues,omitempty"` // all queues if empty
+	QuietHours *QuietHours        `json:"quiet_hours,omitempty"`
+}
+
+// Wants reports whether the event must be sent to the subscriber.
+func (s *TelegramSubscription) Wants(event *QueueEvent) bool {
+	if len(s.Queues) > 0 && !slices.Contains(s.Queues, event.QueueID) {
+		return false
+	}
+
+	switch s.Events {
+	case SubscriptionEventsAll:
+		return true
+	case SubscriptionEventsLow:
+		return event.Type == EventQueueOpened || event.Type == EventTicketsLow
+	default:
+		return event.Type == EventQueueOpened
+	}
+}
+
+// TelegramSubscriptionStore stores the subscriptions of the bot users.
+type TelegramSubscriptionStore interface {
+	Get(ctx context.Context, chatID int64) (*TelegramSubscription, error) // returns nil if the user is not subscribed
+	Save(ctx context.Context, sub *TelegramSubscription) error
+	Remove(ctx context.Context, chatID int64) error
+	List(ctx context.Context) ([]*TelegramSubscription, error)
+}
+
+// RedisTelegramSubscriptionStore keeps subscriptions in a Redis hash keyed by chat ID, so subscribing again replaces the settings.
+type RedisTelegramSubscriptionStore struct {
+	log         *logger.Logger
+	redisClient *redis.Client
+}
+
+func NewRedisTelegramSubscriptionStore(log *logger.Logger, redisClient *redis.Client) *RedisTelegramSubscriptionStore {
+	return &RedisTelegramSubscriptionStore{log: log, redisClient: redisClient}
+}
+
+func (s *RedisTelegramSubscriptionStore) Get(ctx context.Context, chatID int64) (*TelegramSubscription, error) {
+	data, err := s.redisClient.HGet(ctx, telegramSubscriptionsRedisKey, strconv.FormatInt(chatID, 10)).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get Telegram subscription from Redis: %w", err)
+	}
+
+	var sub TelegramSubscription
+	if err := json.Unmarshal([]byte(data), &sub); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Telegram subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+func (s *RedisTelegramSubscriptionStore) Save(ctx context.Context, sub *TelegramSubscription) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Telegram subscription: %w", err)
+	}
+
+	if err := s.redisClient.HSet(ctx, telegramSubscriptionsRedisKey, strconv.FormatInt(sub.ChatID, 10), data).Err(); err != nil {
+		return fmt.Errorf("failed to save Telegram subscription to Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisTelegramSubscriptionStore) Remove(ctx context.Context, chatID int64) error {
+	if err := s.redisClient.HDel(ctx, telegramSubscriptionsRedisKey, strconv.FormatInt(chatID, 10)).Err(); err != nil {
+		return fmt.Errorf("failed to remove Telegram subscription from Redis: %w", err)
+	}
+	return nil
+}
+
+// List returns the subscriptions which can be read: a corrupt one is logged and skipped, so it doesn't stop the others.
+func (s *RedisTelegramSubscriptionStore) List(ctx context.Context) ([]*TelegramSubscription, error) {
+	entries, err := s.redisClient.HGetAll(ctx, telegramSubscriptionsRedisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Telegram subscriptions from Redis: %w", err)
+	}
+
+	subs := make([]*TelegramSubscription, 0, len(entries))
+	for chatID, data := range entries {
+		var sub TelegramSubscription
+		if err := json.Unmarshal([]byte(data), &sub); err != nil {
+			s.log.Error("Skipping corrupt Telegram subscription", err, "chatId", chatID)
+			continue
+		}
+		subs = append(subs, &sub)
+	}
+	return subs, nil
+}
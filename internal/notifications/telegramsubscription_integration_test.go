@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+func TestRedisTelegramSubscriptionStore_WhenRedisIsAvailable_SavesGetsListsAndRemovesSubscriptions(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisTelegramSubscriptionStore(logger.NewLogger(&logger.Config{Level: "error"}), initRedisContainer(ctx, t))
+
+	quietHours := QuietHours{From: 22 * time.Hour, To: 7 * time.Hour}
+	first := &TelegramSubscription{ChatID: 1001, Language: "en", Events: SubscriptionEventsOpening}
+	second := &TelegramSubscription{ChatID: 1002, Language: "uk", Events: SubscriptionEventsLow, Queues: []int{24}, QuietHours: &quietHours}
+
+	// Act
+	saveFirstErr := sut.Save(ctx, first)
+	saveSecondErr := sut.Save(ctx, second)
+	got, getErr := sut.Get(ctx, second.ChatID)
+	removeErr := sut.Remove(ctx, first.ChatID)
+	missing, getMissingErr := sut.Get(ctx, first.ChatID)
+	subs, listErr := sut.List(ctx)
+
+	// Assert
+	if saveFirstErr != nil || saveSecondErr != nil || getErr != nil || removeErr != nil || getMissingErr != nil || listErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v, %v, %v", saveFirstErr, saveSecondErr, getErr, removeErr, getMissingErr, listErr)
+	}
+
+	if got == nil || got.Language != "uk" || got.QuietHours == nil || *got.QuietHours != quietHours || len(got.Queues) != 1 {
+		t.Errorf("Expected the second subscription to be read back, but got %+v", got)
+	}
+	if missing != nil {
+		t.Errorf("Expected the removed subscription to be missing, but got %+v", missing)
+	}
+	if len(subs) != 1 || subs[0].ChatID != second.ChatID {
+		t.Errorf("Expected only the second subscription to remain, but got %v", subs)
+	}
+}
+
+func TestRedisTelegramSubscriptionStoreList_WhenSubscriptionIsCorrupt_SkipsIt(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisClient := initRedisContainer(ctx, t)
+	sut := NewRedisTelegramSubscriptionStore(logger.NewLogger(&logger.Config{Level: "error"}), redisClient)
+
+	if err := sut.Save(ctx, &TelegramSubscription{ChatID: 1001, Language: "en", Events: SubscriptionEventsOpening}); err != nil {
+		t.Fatalf("Failed to save subscription: %v", err)
+	}
+	if err := redisClient.HSet(ctx, telegramSubscriptionsRedisKey, "1002", "{not json").Err(); err != nil {
+		t.Fatalf("Failed to save corrupt subscription: %v", err)
+	}
+
+	// Act
+	subs, err := sut.List(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(subs) != 1 || subs[0].ChatID != 1001 {
+		t.Errorf("Expected only the valid subscription, but got %v", subs)
+	}
+}
@@ -0,0 +1,132 @@
+package notifications
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursUnmarshalText_WhenValid_ParsesPeriod(t *testing.T) {
+	// Arrange
+	var sut QuietHours
+
+	// Act
+	err := sut.UnmarshalText([]byte("22:30-07:00"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sut.From != 22*time.Hour+30*time.Minute || sut.To != 7*time.Hour {
+		t.Errorf("Expected 22:30-07:00, got %v-%v", sut.From, sut.To)
+	}
+	if sut.String() != "22:30-07:00" {
+		t.Errorf("Expected the period to be formatted back as 22:30-07:00, got %s", sut.String())
+	}
+}
+
+func TestQuietHoursUnmarshalText_WhenInvalid_ReturnsError(t *testing.T) {
+	testCases := []string{"22:00", "22:00-25:00", "night-morning", "07:00-07:00"}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			// Arrange
+			var sut QuietHours
+
+			// Act
+			err := sut.UnmarshalText([]byte(tc))
+
+			// Assert
+			if err == nil {
+				t.Errorf("Expected error for %q, got nil", tc)
+			}
+		})
+	}
+}
+
+func TestQuietHoursContains_Always_HandlesPeriodsSpanningMidnight(t *testing.T) {
+	testCases := []struct {
+		period   string
+		clock    string
+		expected bool
+	}{
+		{"22:00-07:00", "23:15", true},
+		{"22:00-07:00", "06:59", true},
+		{"22:00-07:00", "07:00", false},
+		{"22:00-07:00", "12:00", false},
+		{"13:00-15:00", "14:00", true},
+		{"13:00-15:00", "22:00", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.period+" at "+tc.clock, func(t *testing.T) {
+			// Arrange
+			var sut QuietHours
+			if err := sut.UnmarshalText([]byte(tc.period)); err != nil {
+				t.Fatalf("Expected valid quiet hours, got: %v", err)
+			}
+			at, _ := time.Parse("15:04", tc.clock)
+
+			// Act
+			actual := sut.Contains(at)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTelegramSubscriptionWants_Always_MatchesQueuesAndEvents(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sub      TelegramSubscription
+		event    QueueEvent
+		expected bool
+	}{
+		{"Opening subscriber gets opening", TelegramSubscription{Events: SubscriptionEventsOpening}, QueueEvent{Type: EventQueueOpened, QueueID: 24}, true},
+		{"Opening subscriber doesn't get changes", TelegramSubscription{Events: SubscriptionEventsOpening}, QueueEvent{Type: EventTicketsChanged, QueueID: 24, TicketsLeft: 3}, false},
+		{"Opening subscriber doesn't get low tickets", TelegramSubscription{Events: SubscriptionEventsOpening}, QueueEvent{Type: EventTicketsLow, QueueID: 24, TicketsLeft: 3}, false},
+		{"Low subscriber gets low tickets", TelegramSubscription{Events: SubscriptionEventsLow}, QueueEvent{Type: EventTicketsLow, QueueID: 24, TicketsLeft: 10}, true},
+		{"Low subscriber doesn't get routine changes", TelegramSubscription{Events: SubscriptionEventsLow}, QueueEvent{Type: EventTicketsChanged, QueueID: 24, TicketsLeft: 3}, false},
+		{"All subscriber gets closing", TelegramSubscription{Events: SubscriptionEventsAll}, QueueEvent{Type: EventQueueInactive, QueueID: 24}, true},
+		{"Subscriber of the queue", TelegramSubscription{Events: SubscriptionEventsAll, Queues: []int{24}}, QueueEvent{Type: EventQueueOpened, QueueID: 24}, true},
+		{"Subscriber of another queue", TelegramSubscription{Events: SubscriptionEventsAll, Queues: []int{25}}, QueueEvent{Type: EventQueueOpened, QueueID: 24}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := tc.sub.Wants(&tc.event)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTelegramSubscription_WhenMarshaled_KeepsQuietHoursReadable(t *testing.T) {
+	// Arrange
+	quietHours := QuietHours{From: 22 * time.Hour, To: 7 * time.Hour}
+	sub := &TelegramSubscription{ChatID: 42, Language: "en", Events: SubscriptionEventsLow, QuietHours: &quietHours}
+
+	// Act
+	data, err := json.Marshal(sub)
+	var actual TelegramSubscription
+	unmarshalErr := json.Unmarshal(data, &actual)
+
+	// Assert
+	if err != nil || unmarshalErr != nil {
+		t.Fatalf("Expected no errors, got: %v, %v", err, unmarshalErr)
+	}
+	expected := `{"chat_id":42,"language":"en","events":"low","quiet_hours":"22:00-07:00"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+	if actual.QuietHours == nil || *actual.QuietHours != quietHours {
+		t.Errorf("Expected quiet hours to survive the round trip, got %v", actual.QuietHours)
+	}
+}
@@ -0,0 +1,366 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	trackedTicketsRedisKey = "telegram:tracked_tickets"
+
+	ticketApproachingTemplate = "ticket_approaching"
+	ticketReachedTemplate     = "ticket_reached"
+)
+
+var ticketPattern = regexp.MustCompile(`^(\p{L}+)(\d+)$`)
+
+// Ticket is a paper ticket of the office, e.g. "A123": the prefix selects the queue and the tickets of a prefix are called in order.
+type Ticket struct {
+	Value  string // normalized, e.g. "A123"
+	Prefix string
+	Number int
+}
+
+// ParseTicket parses a ticket written by a user or returned by the DUW API, ignoring the case and spaces, e.g. "a 123".
+func ParseTicket(s string) (Ticket, error) {
+	value := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	match := ticketPattern.FindStringSubmatch(value)
+	if match == nil {
+		return Ticket{}, fmt.Errorf("invalid ticket %q, expected a prefix and a number, e.g. \"A123\"", s)
+	}
+
+	number, err := strconv.Atoi(match[2])
+	if err != nil {
+		return Ticket{}, fmt.Errorf("invalid ticket number in %q", s)
+	}
+	return Ticket{Value: value, Prefix: match[1], Number: number}, nil
+}
+
+func (t *Ticket) UnmarshalText(data []byte) error {
+	ticket, err := ParseTicket(string(data))
+	if err != nil {
+		return err
+	}
+	*t = ticket
+	return nil
+}
+
+func (t Ticket) MarshalText() ([]byte, error) {
+	return []byte(t.Value), nil
+}
+
+// TrackedTicket is the ticket of a bot user, who is notified when the called ticket approaches it.
+type TrackedTicket struct {
+	ChatID          int64     `json:"chat_id"` // private chat with the user
+	Language        string    `json:"language"`
+	Ticket          Ticket    `json:"ticket"`
+	ExpiresAt       time.Time `json:"expires_at"`       // tickets are valid for a single day
+	ApproachingSent bool      `json:"approaching_sent"` // the user was told that the ticket approaches
+}
+
+// sameAs reports whether both are the same ticket tracked by the user, regardless of the notifications sent about it.
+func (t *TrackedTicket) sameAs(other *TrackedTicket) bool {
+	return t.ChatID == other.ChatID && t.Ticket == other.Ticket && t.ExpiresAt.Equal(other.ExpiresAt)
+}
+
+// TrackedTicketStore stores the tickets tracked by the bot users, one per user.
+type TrackedTicketStore interface {
+	Get(ctx context.Context, chatID int64) (*TrackedTicket, error) // returns nil if the user doesn't track a ticket
+	Save(ctx context.Context, ticket *TrackedTicket) error
+	Remove(ctx context.Context, chatID int64) error
+	List(ctx context.Context) ([]*TrackedTicket, error)
+	// MarkApproachingSent sets TrackedTicket.ApproachingSent of the ticket, unless the user tracks another ticket by now.
+	MarkApproachingSent(ctx context.Context, ticket *TrackedTicket) error
+	// Untrack removes the ticket, unless the user tracks another ticket by now.
+	Untrack(ctx context.Context, ticket *TrackedTicket) error
+}
+
+// RedisTrackedTicketStore keeps the tracked tickets in a Redis hash keyed by chat ID. Expired tickets are not returned by Get,
+// and removed by TicketTracker.
+type RedisTrackedTicketStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisTrackedTicketStore(redisClient *redis.Client) *RedisTrackedTicketStore {
+	return &RedisTrackedTicketStore{redisClient: redisClient}
+}
+
+func (s *RedisTrackedTicketStore) Get(ctx context.Context, chatID int64) (*TrackedTicket, error) {
+	data, err := s.redisClient.HGet(ctx, trackedTicketsRedisKey, strconv.FormatInt(chatID, 10)).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get tracked ticket from Redis: %w", err)
+	}
+
+	var ticket TrackedTicket
+	if err := json.Unmarshal([]byte(data), &ticket); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tracked ticket: %w", err)
+	}
+	if !ticket.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	return &ticket, nil
+}
+
+func (s *RedisTrackedTicketStore) Save(ctx context.Context, ticket *TrackedTicket) error {
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tracked ticket: %w", err)
+	}
+
+	if err := s.redisClient.HSet(ctx, trackedTicketsRedisKey, strconv.FormatInt(ticket.ChatID, 10), data).Err(); err != nil {
+		return fmt.Errorf("failed to save tracked ticket to Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisTrackedTicketStore) Remove(ctx context.Context, chatID int64) error {
+	if err := s.redisClient.HDel(ctx, trackedTicketsRedisKey, strconv.FormatInt(chatID, 10)).Err(); err != nil {
+		return fmt.Errorf("failed to remove tracked ticket from Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisTrackedTicketStore) List(ctx context.Context) ([]*TrackedTicket, error) {
+	entries, err := s.redisClient.HGetAll(ctx, trackedTicketsRedisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked tickets from Redis: %w", err)
+	}
+
+	tickets := make([]*TrackedTicket, 0, len(entries))
+	for _, data := range entries {
+		var ticket TrackedTicket
+		if err := json.Unmarshal([]byte(data), &ticket); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tracked ticket: %w", err)
+		}
+		tickets = append(tickets, &ticket)
+	}
+	return tickets, nil
+}
+
+func (s *RedisTrackedTicketStore) MarkApproachingSent(ctx context.Context, ticket *TrackedTicket) error {
+	return s.updateSame(ctx, ticket, func(pipe redis.Pipeliner, field string, current *TrackedTicket) error {
+		current.ApproachingSent = true
+		updated, err := json.Marshal(current)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tracked ticket: %w", err)
+		}
+		pipe.HSet(ctx, trackedTicketsRedisKey, field, updated)
+		return nil
+	})
+}
+
+func (s *RedisTrackedTicketStore) Untrack(ctx context.Context, ticket *TrackedTicket) error {
+	return s.updateSame(ctx, ticket, func(pipe redis.Pipeliner, field string, current *TrackedTicket) error {
+		pipe.HDel(ctx, trackedTicketsRedisKey, field)
+		return nil
+	})
+}
+
+// updateSame runs the update in a transaction if the user still tracks the ticket.
+func (s *RedisTrackedTicketStore) updateSame(ctx context.Context, ticket *TrackedTicket, update func(pipe redis.Pipeliner, field string, current *TrackedTicket) error) error {
+	field := strconv.FormatInt(ticket.ChatID, 10)
+	txf := func(tx *redis.Tx) error {
+		data, err := tx.HGet(ctx, trackedTicketsRedisKey, field).Result()
+		switch {
+		case err == redis.Nil:
+			return nil
+		case err != nil:
+			return fmt.Errorf("failed to get tracked ticket: %w", err)
+		}
+
+		var current TrackedTicket
+		if err := json.Unmarshal([]byte(data), &current); err != nil {
+			return fmt.Errorf("failed to unmarshal tracked ticket: %w", err)
+		}
+		if !current.sameAs(ticket) {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return update(pipe, field, &current)
+		})
+		return err
+	}
+
+	// WATCH makes sure a ticket tracked by the user in the meantime isn't overwritten or removed
+	if err := s.redisClient.Watch(ctx, txf, trackedTicketsRedisKey); err != nil {
+		return fmt.Errorf("failed to update tracked ticket in Redis: %w", err)
+	}
+	return nil
+}
+
+// ticketCallRate measures how fast the tickets of a prefix are called since the first call observed today.
+type ticketCallRate struct {
+	first, last     int
+	firstAt, lastAt time.Time
+}
+
+func (r *ticketCallRate) perMinute() float64 {
+	minutes := r.lastAt.Sub(r.firstAt).Minutes()
+	if minutes <= 0 || r.last <= r.first {
+		return 0
+	}
+	return float64(r.last-r.first) / minutes
+}
+
+// TicketTracker sends a direct message to the users tracking their tickets when the called ticket is
+// TicketTrackerConfig.ApproachingPositions away from theirs, with an ETA based on the observed call rate, and again when
+// their ticket is called. A ticket is tracked until it's called or the day ends.
+// The tickets are checked in the background by the direct message queue, which runs one job at a time, so the state of
+// the tracker is only used by the worker.
+type TicketTracker struct {
+	cfg      *TicketTrackerConfig
+	log      *logger.Logger
+	telegram *TelegramNotifier
+	store    TrackedTicketStore
+	queue    *DirectMessageQueue
+
+	lastCalled string
+	rates      map[string]*ticketCallRate // by prefix
+}
+
+func NewTicketTracker(cfg *TicketTrackerConfig, log *logger.Logger, telegram *TelegramNotifier, store TrackedTicketStore, queue *DirectMessageQueue) *TicketTracker {
+	return &TicketTracker{
+		cfg:      cfg,
+		log:      log,
+		telegram: telegram,
+		store:    store,
+		queue:    queue,
+		rates:    make(map[string]*ticketCallRate),
+	}
+}
+
+// ObserveTicket is called with the last called ticket on every check of the queue. The tracked tickets are only
+// checked when it changes. Failures are only logged: tracking must not block the queue notifications.
+func (t *TicketTracker) ObserveTicket(ctx context.Context, ticketValue string, checkedAt time.Time) {
+	if ticketValue == "" {
+		return
+	}
+	t.queue.Enqueue("ticket tracker", func(ctx context.Context) {
+		t.observe(ctx, ticketValue, checkedAt)
+	})
+}
+
+// observe checks the tracked tickets against the called one. The called ticket is remembered only once all of them are
+// checked, so they are checked again on the next observation if anything failed.
+func (t *TicketTracker) observe(ctx context.Context, ticketValue string, checkedAt time.Time) {
+	if ticketValue == t.lastCalled {
+		return
+	}
+	called, err := ParseTicket(ticketValue)
+	if err != nil {
+		t.log.Warn("Can't track the called ticket", "ticketValue", ticketValue, "error", err.Error())
+		return
+	}
+	rate := t.updateRate(called, checkedAt)
+
+	tracked, err := t.store.List(ctx)
+	if err != nil {
+		t.log.Error("Failed to get tracked tickets", err)
+		return
+	}
+	checked := true
+	for _, ticket := range tracked {
+		if !t.check(ctx, ticket, called, rate, checkedAt) {
+			checked = false
+		}
+	}
+	if checked {
+		t.lastCalled = ticketValue
+	}
+}
+
+func (t *TicketTracker) updateRate(called Ticket, at time.Time) *ticketCallRate {
+	rate := t.rates[called.Prefix]
+	// the numbering starts again every day
+	if rate == nil || called.Number < rate.last || !sameLocalDay(rate.lastAt, at) {
+		rate = &ticketCallRate{first: called.Number, firstAt: at}
+		t.rates[called.Prefix] = rate
+	}
+	rate.last = called.Number
+	rate.lastAt = at
+	return rate
+}
+
+// check returns false if the ticket needs to be checked again because a message wasn't delivered.
+func (t *TicketTracker) check(ctx context.Context, ticket *TrackedTicket, called Ticket, rate *ticketCallRate, at time.Time) bool {
+	if !ticket.ExpiresAt.After(at) {
+		t.remove(ctx, ticket)
+		return true
+	}
+	if ticket.Ticket.Prefix != called.Prefix {
+		return true
+	}
+
+	data := templates.TicketData{
+		Ticket:       EscapeHTML(ticket.Ticket.Value),
+		CalledTicket: EscapeHTML(called.Value),
+		Positions:    ticket.Ticket.Number - called.Number,
+	}
+	switch {
+	case data.Positions <= 0:
+		delivered, retry := t.send(ctx, ticket, templates.Default.Execute(ticketReachedTemplate, ticket.Language, data))
+		if delivered {
+			t.remove(ctx, ticket)
+		}
+		return !retry
+	case data.Positions <= t.cfg.ApproachingPositions && !ticket.ApproachingSent:
+		if perMinute := rate.perMinute(); perMinute > 0 {
+			data.EtaMinutes = int(math.Ceil(float64(data.Positions) / perMinute))
+		}
+		delivered, retry := t.send(ctx, ticket, templates.Default.Execute(ticketApproachingTemplate, ticket.Language, data))
+		if !delivered {
+			return !retry
+		}
+		if err := t.store.MarkApproachingSent(ctx, ticket); err != nil {
+			t.log.Error("Failed to save tracked ticket", err, "chatId", ticket.ChatID)
+		}
+	}
+	return true
+}
+
+// send returns whether the message was delivered, or else whether it must be sent again.
+// Users who blocked the bot stop tracking their tickets.
+func (t *TicketTracker) send(ctx context.Context, ticket *TrackedTicket, text string) (delivered, retry bool) {
+	err := t.telegram.SendMessage(ctx, strconv.FormatInt(ticket.ChatID, 10), text)
+	switch {
+	case isBotBlocked(err):
+		t.log.Info("User blocked the bot, removing the tracked ticket", "chatId", ticket.ChatID)
+		t.remove(ctx, ticket)
+		return false, false
+	case err != nil:
+		t.log.Error("Failed to send ticket tracker message", err, "chatId", ticket.ChatID)
+		return false, true
+	}
+	return true, false
+}
+
+func (t *TicketTracker) remove(ctx context.Context, ticket *TrackedTicket) {
+	if err := t.store.Untrack(ctx, ticket); err != nil {
+		t.log.Error("Failed to remove tracked ticket", err, "chatId", ticket.ChatID)
+	}
+}
+
+// EndOfDay returns the end of the local day of t, when the tickets of the day expire.
+func EndOfDay(t time.Time) time.Time {
+	local := templates.Default.LocalTime(t)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, local.Location())
+}
+
+func sameLocalDay(a, b time.Time) bool {
+	return EndOfDay(a).Equal(EndOfDay(b))
+}
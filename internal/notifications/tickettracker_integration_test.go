@@ -0,0 +1,109 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedisTrackedTicketStore_WhenRedisIsAvailable_SavesAndGetsOnlyTicketsWhichDidNotExpire(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisTrackedTicketStore(initRedisContainer(ctx, t))
+
+	ticket, _ := ParseTicket("A123")
+	active := &TrackedTicket{ChatID: 1001, Language: "en", Ticket: ticket, ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second)}
+	expired := &TrackedTicket{ChatID: 1002, Language: "en", Ticket: ticket, ExpiresAt: time.Now().Add(-time.Hour)}
+
+	// Act
+	saveActiveErr := sut.Save(ctx, active)
+	saveExpiredErr := sut.Save(ctx, expired)
+	gotActive, getActiveErr := sut.Get(ctx, active.ChatID)
+	gotExpired, getExpiredErr := sut.Get(ctx, expired.ChatID)
+	removeErr := sut.Remove(ctx, expired.ChatID)
+	tickets, listErr := sut.List(ctx)
+
+	// Assert
+	if saveActiveErr != nil || saveExpiredErr != nil || getActiveErr != nil || getExpiredErr != nil || removeErr != nil || listErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v, %v, %v", saveActiveErr, saveExpiredErr, getActiveErr, getExpiredErr, removeErr, listErr)
+	}
+
+	if gotActive == nil || gotActive.Ticket != ticket || !gotActive.ExpiresAt.Equal(active.ExpiresAt) {
+		t.Errorf("Expected the active ticket to be read back, but got %+v", gotActive)
+	}
+	if gotExpired != nil {
+		t.Errorf("Expected the expired ticket not to be returned, but got %+v", gotExpired)
+	}
+	if len(tickets) != 1 || tickets[0].ChatID != active.ChatID {
+		t.Errorf("Expected only the active ticket to remain, but got %v", tickets)
+	}
+}
+
+func TestRedisTrackedTicketStoreMarkApproachingSent_Always_MarksOnlyTheSameTicket(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisTrackedTicketStore(initRedisContainer(ctx, t))
+
+	oldTicket, _ := ParseTicket("A123")
+	newTicket, _ := ParseTicket("B7")
+	expiresAt := time.Now().Add(time.Hour)
+	same := &TrackedTicket{ChatID: 1001, Language: "en", Ticket: oldTicket, ExpiresAt: expiresAt}
+	replaced := &TrackedTicket{ChatID: 1002, Language: "en", Ticket: newTicket, ExpiresAt: expiresAt}
+
+	// Act
+	saveSameErr := sut.Save(ctx, same)
+	saveReplacedErr := sut.Save(ctx, replaced)
+	markSameErr := sut.MarkApproachingSent(ctx, same)
+	markReplacedErr := sut.MarkApproachingSent(ctx, &TrackedTicket{ChatID: 1002, Ticket: oldTicket})
+	markMissingErr := sut.MarkApproachingSent(ctx, &TrackedTicket{ChatID: 1003, Ticket: oldTicket})
+	gotSame, getSameErr := sut.Get(ctx, same.ChatID)
+	gotReplaced, getReplacedErr := sut.Get(ctx, replaced.ChatID)
+	gotMissing, getMissingErr := sut.Get(ctx, 1003)
+
+	// Assert
+	if saveSameErr != nil || saveReplacedErr != nil || markSameErr != nil || markReplacedErr != nil || markMissingErr != nil || getSameErr != nil || getReplacedErr != nil || getMissingErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v, %v, %v, %v, %v", saveSameErr, saveReplacedErr, markSameErr, markReplacedErr, markMissingErr, getSameErr, getReplacedErr, getMissingErr)
+	}
+
+	if gotSame == nil || !gotSame.ApproachingSent || gotSame.Language != "en" {
+		t.Errorf("Expected the ticket to be marked, but got %+v", gotSame)
+	}
+	if gotReplaced == nil || gotReplaced.ApproachingSent || gotReplaced.Ticket != newTicket {
+		t.Errorf("Expected the new ticket of the user to be kept unmarked, but got %+v", gotReplaced)
+	}
+	if gotMissing != nil {
+		t.Errorf("Expected no ticket to be created, but got %+v", gotMissing)
+	}
+}
+
+func TestRedisTrackedTicketStoreUntrack_Always_RemovesOnlyTheSameTicket(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisTrackedTicketStore(initRedisContainer(ctx, t))
+
+	oldTicket, _ := ParseTicket("A123")
+	newTicket, _ := ParseTicket("B7")
+	expiresAt := time.Now().Add(time.Hour)
+	same := &TrackedTicket{ChatID: 1001, Language: "en", Ticket: oldTicket, ExpiresAt: expiresAt}
+	replaced := &TrackedTicket{ChatID: 1002, Language: "en", Ticket: newTicket, ExpiresAt: expiresAt}
+
+	// Act
+	saveSameErr := sut.Save(ctx, same)
+	saveReplacedErr := sut.Save(ctx, replaced)
+	untrackSameErr := sut.Untrack(ctx, same)
+	untrackReplacedErr := sut.Untrack(ctx, &TrackedTicket{ChatID: 1002, Ticket: oldTicket, ExpiresAt: expiresAt})
+	gotSame, getSameErr := sut.Get(ctx, same.ChatID)
+	gotReplaced, getReplacedErr := sut.Get(ctx, replaced.ChatID)
+
+	// Assert
+	if saveSameErr != nil || saveReplacedErr != nil || untrackSameErr != nil || untrackReplacedErr != nil || getSameErr != nil || getReplacedErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v, %v, %v", saveSameErr, saveReplacedErr, untrackSameErr, untrackReplacedErr, getSameErr, getReplacedErr)
+	}
+
+	if gotSame != nil {
+		t.Errorf("Expected the ticket to be removed, but got %+v", gotSame)
+	}
+	if gotReplaced == nil || gotReplaced.Ticket != newTicket {
+		t.Errorf("Expected the new ticket of the user to be kept, but got %+v", gotReplaced)
+	}
+}
@@ -0,0 +1,261 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+type inMemoryTrackedTicketStore struct {
+	tickets   map[int64]*TrackedTicket
+	listFails bool
+}
+
+func newInMemoryTrackedTicketStore(tickets ...*TrackedTicket) *inMemoryTrackedTicketStore {
+	store := &inMemoryTrackedTicketStore{tickets: make(map[int64]*TrackedTicket)}
+	for _, ticket := range tickets {
+		store.tickets[ticket.ChatID] = ticket
+	}
+	return store
+}
+
+func (s *inMemoryTrackedTicketStore) Get(ctx context.Context, chatID int64) (*TrackedTicket, error) {
+	return s.tickets[chatID], nil
+}
+
+func (s *inMemoryTrackedTicketStore) Save(ctx context.Context, ticket *TrackedTicket) error {
+	s.tickets[ticket.ChatID] = ticket
+	return nil
+}
+
+func (s *inMemoryTrackedTicketStore) Remove(ctx context.Context, chatID int64) error {
+	delete(s.tickets, chatID)
+	return nil
+}
+
+func (s *inMemoryTrackedTicketStore) List(ctx context.Context) ([]*TrackedTicket, error) {
+	if s.listFails {
+		return nil, fmt.Errorf("failed to list tracked tickets")
+	}
+	tickets := make([]*TrackedTicket, 0, len(s.tickets))
+	for _, ticket := range s.tickets {
+		tickets = append(tickets, ticket)
+	}
+	return tickets, nil
+}
+
+func (s *inMemoryTrackedTicketStore) MarkApproachingSent(ctx context.Context, ticket *TrackedTicket) error {
+	if current := s.tickets[ticket.ChatID]; current != nil && current.sameAs(ticket) {
+		marked := *current
+		marked.ApproachingSent = true
+		s.tickets[ticket.ChatID] = &marked
+	}
+	return nil
+}
+
+func (s *inMemoryTrackedTicketStore) Untrack(ctx context.Context, ticket *TrackedTicket) error {
+	if current := s.tickets[ticket.ChatID]; current != nil && current.sameAs(ticket) {
+		delete(s.tickets, ticket.ChatID)
+	}
+	return nil
+}
+
+type sentDirectMessage struct {
+	chatID, text string
+}
+
+// startTicketTrackerTelegramApi records the sent messages and rejects the messages to the blocked chats with 403.
+func startTicketTrackerTelegramApi(t *testing.T, blockedChatIDs ...string) (*httptest.Server, *[]sentDirectMessage) {
+	var sent []sentDirectMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageChannelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if slices.Contains(blockedChatIDs, req.ChatID) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`)
+			return
+		}
+		sent = append(sent, sentDirectMessage{req.ChatID, req.Text})
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &sent
+}
+
+func newTestTicketTracker(server *httptest.Server, store TrackedTicketStore) *TicketTracker {
+	cfg := &TelegramConfig{
+		BaseApiUrl:            server.URL,
+		BotToken:              "123456789:ABCdefGHIjklMNOpqrSTUvwxYZ",
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          10,
+		RequestTimeoutSeconds: 2,
+	}
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	return NewTicketTracker(&TicketTrackerConfig{Enabled: true, ApproachingPositions: 10}, log, NewTelegramNotifier(cfg, log, &http.Client{}), store, NewDirectMessageQueue(log))
+}
+
+func TestParseTicket_Always_ParsesPrefixAndNumber(t *testing.T) {
+	testCases := []struct {
+		input       string
+		expected    Ticket
+		expectedErr bool
+	}{
+		{"A123", Ticket{Value: "A123", Prefix: "A", Number: 123}, false},
+		{" k 007 ", Ticket{Value: "K007", Prefix: "K", Number: 7}, false},
+		{"Ab12", Ticket{Value: "AB12", Prefix: "AB", Number: 12}, false},
+		{"123", Ticket{}, true},
+		{"A", Ticket{}, true},
+		{"123A", Ticket{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			// Act
+			actual, err := ParseTicket(tc.input)
+
+			// Assert
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTicketTrackerObserve_WhenCalledTicketApproaches_NotifiesOnceWithEtaAndAgainWhenReached(t *testing.T) {
+	// Arrange
+	server, sent := startTicketTrackerTelegramApi(t)
+	start := time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC)
+	store := newInMemoryTrackedTicketStore(
+		&TrackedTicket{ChatID: 1001, Language: "en", Ticket: Ticket{Value: "A123", Prefix: "A", Number: 123}, ExpiresAt: start.Add(12 * time.Hour)},
+		&TrackedTicket{ChatID: 1002, Language: "en", Ticket: Ticket{Value: "B115", Prefix: "B", Number: 115}, ExpiresAt: start.Add(12 * time.Hour)},
+	)
+	sut := newTestTicketTracker(server, store)
+	ctx := context.Background()
+
+	// Act
+	sut.observe(ctx, "A100", start)
+	sut.observe(ctx, "A113", start.Add(13*time.Minute)) // one ticket per minute, 10 tickets away
+	sut.observe(ctx, "A115", start.Add(15*time.Minute))
+	sut.observe(ctx, "A115", start.Add(16*time.Minute))
+	sut.observe(ctx, "A124", start.Add(25*time.Minute))
+
+	// Assert
+	expected := []sentDirectMessage{
+		{"1001", "⏳ Now calling <b>A113</b>. Your ticket <b>A123</b> is 10 tickets away.\n🕒 About 10 minutes left"},
+		{"1001", "🔔 Your ticket <b>A123</b> has been reached, now calling <b>A124</b>. Good luck!"},
+	}
+	if !slices.Equal(*sent, expected) {
+		t.Errorf("Expected messages %v, got %v", expected, *sent)
+	}
+	if ticket, _ := store.Get(ctx, 1001); ticket != nil {
+		t.Errorf("Expected the reached ticket to stop being tracked, got %+v", ticket)
+	}
+	if ticket, _ := store.Get(ctx, 1002); ticket == nil || ticket.ApproachingSent {
+		t.Errorf("Expected the ticket of another prefix to be tracked without notifications, got %+v", ticket)
+	}
+}
+
+func TestTicketTrackerObserve_WhenTicketExpiredOrUserBlockedBot_StopsTrackingIt(t *testing.T) {
+	// Arrange
+	server, sent := startTicketTrackerTelegramApi(t, "1002")
+	now := time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC)
+	store := newInMemoryTrackedTicketStore(
+		&TrackedTicket{ChatID: 1001, Language: "en", Ticket: Ticket{Value: "A105", Prefix: "A", Number: 105}, ExpiresAt: now.Add(-time.Hour)},
+		&TrackedTicket{ChatID: 1002, Language: "en", Ticket: Ticket{Value: "A105", Prefix: "A", Number: 105}, ExpiresAt: now.Add(time.Hour)},
+	)
+	sut := newTestTicketTracker(server, store)
+
+	// Act
+	sut.observe(context.Background(), "A100", now)
+
+	// Assert
+	if len(*sent) != 0 {
+		t.Errorf("Expected no messages to be delivered, got %v", *sent)
+	}
+	if len(store.tickets) != 0 {
+		t.Errorf("Expected the expired ticket and the ticket of the blocked user to be removed, got %v", store.tickets)
+	}
+}
+
+func TestTicketTrackerObserveTicket_Always_ChecksTicketsInBackground(t *testing.T) {
+	// Arrange
+	server, sent := startTicketTrackerTelegramApi(t)
+	now := time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC)
+	store := newInMemoryTrackedTicketStore(&TrackedTicket{ChatID: 1001, Language: "en", Ticket: Ticket{Value: "A100", Prefix: "A", Number: 100}, ExpiresAt: now.Add(time.Hour)})
+	sut := newTestTicketTracker(server, store)
+
+	// Act
+	sut.ObserveTicket(context.Background(), "A100", now)
+
+	// Assert
+	if len(*sent) != 0 || sut.lastCalled != "" {
+		t.Fatalf("Expected nothing to be checked before the worker runs, got %v", *sent)
+	}
+
+	job := <-sut.queue.jobs
+	job(context.Background())
+	if len(*sent) != 1 || sut.lastCalled != "A100" {
+		t.Errorf("Expected the worker to notify the user and remember the called ticket, got %v", *sent)
+	}
+}
+
+func TestTicketTrackerObserve_WhenCheckFails_ChecksSameTicketAgain(t *testing.T) {
+	testCases := []struct {
+		name      string
+		listFails bool
+		sendFails bool
+	}{
+		{"Store fails", true, false},
+		{"Message fails", false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var failures int
+			var sent []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.sendFails && failures == 0 {
+					failures++
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprint(w, `{"ok":false,"error_code":500,"description":"Internal Server Error"}`)
+					return
+				}
+				var req SendMessageChannelRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				sent = append(sent, req.ChatID)
+				fmt.Fprint(w, `{"ok":true,"result":{"message_id":1}}`)
+			}))
+			t.Cleanup(server.Close)
+
+			now := time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC)
+			store := newInMemoryTrackedTicketStore(&TrackedTicket{ChatID: 1001, Language: "en", Ticket: Ticket{Value: "A105", Prefix: "A", Number: 105}, ExpiresAt: now.Add(time.Hour)})
+			store.listFails = tc.listFails
+			sut := newTestTicketTracker(server, store)
+
+			// Act
+			sut.observe(context.Background(), "A100", now)
+			store.listFails = false
+			sut.observe(context.Background(), "A100", now.Add(time.Minute))
+
+			// Assert
+			if len(sent) != 1 || sent[0] != "1001" {
+				t.Errorf("Expected the approaching message to be sent on the second observation, got %v", sent)
+			}
+			if ticket, _ := store.Get(context.Background(), 1001); ticket == nil || !ticket.ApproachingSent {
+				t.Errorf("Expected the approaching message to be recorded, got %+v", ticket)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/avast/retry-go/v4"
+)
+
+const (
+	// WebhookEventVersion is the version of the webhook payload schema. It must be bumped on every breaking change of WebhookEvent.
+	WebhookEventVersion = "1"
+
+	WebhookSignatureHeader = "X-Duw-Signature" // "sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">"
+	WebhookTimestampHeader = "X-Duw-Timestamp" // unix time in seconds, part of the signed content to prevent replays
+	WebhookEventHeader     = "X-Duw-Event"
+	WebhookDeliveryHeader  = "X-Duw-Delivery"
+
+	// webhookEventMessage is the type of events created from plain text messages which are not related to a queue status update.
+	webhookEventMessage EventType = "message"
+)
+
+// WebhookEvent is the versioned JSON payload posted to webhook targets.
+type WebhookEvent struct {
+	Version    string        `json:"version"`
+	ID         string        `json:"id"` // the same for every target and every time the event is sent, so receivers can drop duplicates
+	Type       EventType     `json:"type"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Text       string        `json:"text"` // human-readable message without formatting
+	Queue      *WebhookQueue `json:"queue,omitempty"`
+}
+
+type WebhookQueue struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Active      bool   `json:"active"`
+	Enabled     bool   `json:"enabled"`
+	TicketValue string `json:"ticket_value"`
+	TicketsLeft int    `json:"tickets_left"`
+}
+
+// WebhookNotifier posts signed queue events to the configured URLs. Each URL has its own secret used for HMAC-SHA256 signatures.
+// Failed deliveries are retried with exponential backoff, until the delivery takes too long, and every attempt is recorded
+// in the delivery log.
+type WebhookNotifier struct {
+	cfg         *WebhookConfig
+	log         *logger.Logger
+	httpClient  *http.Client
+	deliveryLog DeliveryLog
+}
+
+func NewWebhookNotifier(cfg *WebhookConfig, log *logger.Logger, httpClient *http.Client, deliveryLog DeliveryLog) *WebhookNotifier {
+	return &WebhookNotifier{
+		cfg:         cfg,
+		log:         log,
+		httpClient:  httpClient,
+		deliveryLog: deliveryLog,
+	}
+}
+
+func (w *WebhookNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return w.deliver(ctx, &WebhookEvent{
+		ID:         webhookMessageID(text),
+		Type:       webhookEventMessage,
+		OccurredAt: time.Now().UTC(),
+		Text:       stripTelegramHTML(text),
+	})
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	id := event.IdempotencyKey
+	if id == "" {
+		id = NewIdempotencyKey(event)
+	}
+
+	return w.deliver(ctx, &WebhookEvent{
+		ID:         id,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Text:       stripTelegramHTML(event.Text),
+		Queue: &WebhookQueue{
+			ID:          event.QueueID,
+			Name:        event.QueueName,
+			Active:      event.Active,
+			Enabled:     event.Enabled,
+			TicketValue: event.TicketValue,
+			TicketsLeft: event.TicketsLeft,
+		},
+	})
+}
+
+// deliver sends the event to every target. Targets are independent: a failing target does not prevent delivery to the others.
+func (w *WebhookNotifier) deliver(ctx context.Context, event *WebhookEvent) error {
+	event.Version = WebhookEventVersion
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	var errs []error
+	for url, secret := range w.cfg.Targets {
+		if err := w.deliverWithRetries(ctx, url, secret, event.ID, event.Type, body); err != nil {
+			errs = append(errs, fmt.Errorf("delivery %s to %s failed: %w", event.ID, url, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w *WebhookNotifier) deliverWithRetries(ctx context.Context, url, secret, deliveryID string, eventType EventType, body []byte) error {
+	var attempt uint
+
+	deliveryCtx := ctx
+	if w.cfg.MaxDeliverySeconds > 0 {
+		var cancel context.CancelFunc
+		deliveryCtx, cancel = context.WithTimeout(ctx, time.Duration(w.cfg.MaxDeliverySeconds)*time.Second)
+		defer cancel()
+	}
+
+	err := retry.Do(
+		func() error {
+			attempt++
+			startedAt := time.Now()
+
+			statusCode, err := w.post(deliveryCtx, url, secret, deliveryID, eventType, body)
+
+			entry := &DeliveryAttempt{
+				DeliveryID: deliveryID,
+				Url:        logger.Redact(url),
+				Attempt:    attempt,
+				StatusCode: statusCode,
+				Duration:   time.Since(startedAt),
+				AttemptAt:  startedAt.UTC(),
+			}
+			if err != nil {
+				entry.Error = logger.Redact(err.Error())
+				w.log.Warn("Webhook delivery attempt failed", "deliveryId", deliveryID, "url", url, "attempt", attempt, "statusCode", statusCode, "error", err)
+			} else {
+				w.log.Info("Webhook delivered successfully", "deliveryId", deliveryID, "url", url, "attempt", attempt, "statusCode", statusCode)
+			}
+			// the log is for inspection only, the delivery doesn't depend on it
+			if logErr := w.deliveryLog.Add(ctx, entry); logErr != nil {
+				w.log.Warn("Failed to record webhook delivery attempt", "deliveryId", deliveryID, "error", logErr)
+			}
+
+			return err
+		},
+		retry.Attempts(w.cfg.MaxRetryAttempts),
+		retry.Delay(time.Duration(w.cfg.RetryDelayMs)*time.Millisecond),
+		retry.MaxDelay(time.Duration(w.cfg.MaxRetryDelayMs)*time.Millisecond),
+		retry.DelayType(retry.BackOffDelay),
+		retry.Context(deliveryCtx),
+		retry.LastErrorOnly(true),
+	)
+	if err != nil && ctx.Err() == nil && errors.Is(deliveryCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("delivery took longer than %ds, giving up: %w", w.cfg.MaxDeliverySeconds, err)
+	}
+	return err
+}
+
+func (w *WebhookNotifier) post(ctx context.Context, url, secret, deliveryID string, eventType EventType, body []byte) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(w.cfg.RequestTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(timeoutCtx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return 0, retry.Unrecoverable(fmt.Errorf("failed to create HTTP request: %w", err))
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, timestamp, body))
+	req.Header.Set(WebhookEventHeader, string(eventType))
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+
+	respTxt, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("got unsuccessful status code: %d, response: \"%s\"", resp.StatusCode, respTxt)
+
+	// client errors won't be fixed by retrying, except for timeouts and rate limiting
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return resp.StatusCode, retry.Unrecoverable(err)
+	}
+
+	return resp.StatusCode, err
+}
+
+// SignWebhookPayload returns the value of the signature header for the given secret, timestamp and body.
+// Receivers should compute the same value and compare it using a constant-time comparison.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookMessageID derives the ID of a free-form message from its text, as the message has nothing else identifying it.
+func webhookMessageID(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(hash[:16])
+}
@@ -0,0 +1,242 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type inMemoryDeliveryLog struct {
+	mu       sync.Mutex
+	attempts []*DeliveryAttempt
+}
+
+func (l *inMemoryDeliveryLog) Add(ctx context.Context, attempt *DeliveryAttempt) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts = append(l.attempts, attempt)
+	return nil
+}
+
+func (l *inMemoryDeliveryLog) Entries(ctx context.Context) ([]*DeliveryAttempt, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*DeliveryAttempt(nil), l.attempts...), nil
+}
+
+func newTestWebhookNotifier(targets map[string]string, maxAttempts uint) *WebhookNotifier {
+	cfg := &WebhookConfig{
+		Targets:               targets,
+		MaxRetryAttempts:      maxAttempts,
+		RetryDelayMs:          10,
+		MaxRetryDelayMs:       50,
+		RequestTimeoutSeconds: 2,
+	}
+	return NewWebhookNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{}, &inMemoryDeliveryLog{})
+}
+
+func TestWebhookNotify_WhenTargetsConfigured_PostsSignedVersionedEventToEachTargetWithItsSecret(t *testing.T) {
+	// Arrange
+	type received struct {
+		event     WebhookEvent
+		signature string
+		timestamp string
+		body      []byte
+	}
+	newTarget := func(captured *received) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			captured.body, _ = io.ReadAll(r.Body)
+			json.Unmarshal(captured.body, &captured.event)
+			captured.signature = r.Header.Get(WebhookSignatureHeader)
+			captured.timestamp = r.Header.Get(WebhookTimestampHeader)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	}
+
+	var first, second received
+	firstTarget, secondTarget := newTarget(&first), newTarget(&second)
+	defer firstTarget.Close()
+	defer secondTarget.Close()
+
+	sut := newTestWebhookNotifier(map[string]string{firstTarget.URL: "secret-1", secondTarget.URL: "secret-2"}, 1)
+
+	occurredAt := time.Date(2025, 3, 3, 9, 15, 0, 0, time.UTC)
+	event := &QueueEvent{
+		Type:        EventQueueOpened,
+		Text:        "🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!",
+		QueueID:     24,
+		QueueName:   "Odbiór karty",
+		Active:      true,
+		Enabled:     true,
+		TicketValue: "K80",
+		TicketsLeft: 10,
+		OccurredAt:  occurredAt,
+	}
+
+	// Act
+	err := sut.Notify(context.Background(), event)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected successful delivery, but got error: \"%v\"", err)
+	}
+
+	for name, tc := range map[string]struct {
+		captured *received
+		secret   string
+	}{"first": {&first, "secret-1"}, "second": {&second, "secret-2"}} {
+		expectedEvent := WebhookEvent{
+			Version:    WebhookEventVersion,
+			ID:         tc.captured.event.ID,
+			Type:       EventQueueOpened,
+			OccurredAt: occurredAt,
+			Text:       "🔔 Kolejka Odbiór karty jest teraz dostępna!",
+			Queue:      &WebhookQueue{ID: 24, Name: "Odbiór karty", Active: true, Enabled: true, TicketValue: "K80", TicketsLeft: 10},
+		}
+		if diff := cmp.Diff(expectedEvent, tc.captured.event); diff != "" {
+			t.Errorf("Event mismatch for %s target (-want +got):\n%s", name, diff)
+		}
+
+		if tc.captured.event.ID == "" {
+			t.Errorf("Expected delivery ID to be set for %s target", name)
+		}
+
+		expectedSignature := SignWebhookPayload(tc.secret, tc.captured.timestamp, tc.captured.body)
+		if tc.captured.timestamp == "" || tc.captured.signature != expectedSignature {
+			t.Errorf("Expected signature '%s' for %s target, but got '%s'", expectedSignature, name, tc.captured.signature)
+		}
+	}
+}
+
+func TestWebhookNotify_WhenTargetFailsTemporarily_RetriesAndRecordsAttemptsInDeliveryLog(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	sut := newTestWebhookNotifier(map[string]string{target.URL: "secret"}, 5)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected delivery to succeed after retries, but got error: \"%v\"", err)
+	}
+	entries, _ := sut.deliveryLog.Entries(context.Background())
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 delivery attempts in log, but got %d: %+v", len(entries), entries)
+	}
+
+	expectedCodes := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+	for i, entry := range entries {
+		if entry.Attempt != uint(i+1) || entry.StatusCode != expectedCodes[i] || entry.Url != target.URL || entry.DeliveryID != entries[0].DeliveryID {
+			t.Errorf("Unexpected delivery log entry %d: %+v", i, entry)
+		}
+	}
+
+	if entries[2].Error != "" || entries[0].Error == "" {
+		t.Errorf("Expected only failed attempts to have errors, but got: %+v", entries)
+	}
+}
+
+func TestWebhookNotify_WhenDeliveryTakesTooLong_GivesUp(t *testing.T) {
+	// Arrange
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer target.Close()
+
+	sut := newTestWebhookNotifier(map[string]string{target.URL: "secret"}, 1000)
+	sut.cfg.MaxDeliverySeconds = 1
+
+	// Act
+	startedAt := time.Now()
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged})
+	elapsed := time.Since(startedAt)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "giving up") {
+		t.Errorf("Expected the delivery to be given up, but got: %v", err)
+	}
+	if elapsed > 3*time.Second {
+		t.Errorf("Expected the delivery to be given up after about 1s, but it took %v", elapsed)
+	}
+}
+
+func TestWebhookNotify_WhenTargetRejectsRequest_DoesNotRetryAndReturnsError(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+	}))
+	defer target.Close()
+
+	sut := newTestWebhookNotifier(map[string]string{target.URL: "secret"}, 5)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueInactive})
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when target rejects the request, but got nil")
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected exactly 1 attempt for client error, but got %d", calls.Load())
+	}
+}
+
+func TestWebhookNotify_WhenEventIsSentAgain_KeepsItsIDForEveryTarget(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var ids []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		ids = append(ids, event.ID, r.Header.Get(WebhookDeliveryHeader))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	sut := newTestWebhookNotifier(map[string]string{target.URL: "secret-1", target.URL + "/other": "secret-2"}, 1)
+	event := &QueueEvent{Type: EventQueueOpened, QueueID: 24, Active: true, Enabled: true, TicketsLeft: 10}
+
+	// Act
+	firstErr := sut.Notify(context.Background(), event)
+	secondErr := sut.Notify(context.Background(), event)
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Expected successful deliveries, but got: %v, %v", firstErr, secondErr)
+	}
+	if len(ids) != 8 {
+		t.Fatalf("Expected 4 deliveries, but got IDs %v", ids)
+	}
+	for _, id := range ids {
+		if id != NewIdempotencyKey(event) {
+			t.Errorf("Expected every delivery to have the ID %s derived from the event, but got %v", NewIdempotencyKey(event), ids)
+			break
+		}
+	}
+}
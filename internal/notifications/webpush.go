@@ -0,0 +1,383 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/avast/retry-go/v4"
+)
+
+const (
+	webPushRecordSize      = 4096
+	webPushJwtValidity     = 12 * time.Hour // push services reject tokens valid for more than 24 hours
+	webPushPayloadTagShape = "duw-queue-%d"
+)
+
+// errWebPushSubscriptionGone is returned when the push service reports that the subscription expired or was unsubscribed.
+var errWebPushSubscriptionGone = errors.New("web push subscription is gone")
+
+// WebPushSubscription is a browser push subscription, in the format returned by PushSubscription.toJSON().
+type WebPushSubscription struct {
+	Endpoint string                  `json:"endpoint"`
+	Keys     WebPushSubscriptionKeys `json:"keys"`
+}
+
+type WebPushSubscriptionKeys struct {
+	P256dh string `json:"p256dh"` // base64url-encoded P-256 public key of the browser
+	Auth   string `json:"auth"`   // base64url-encoded authentication secret
+}
+
+// Validate checks that the subscription can be used to encrypt messages.
+func (s *WebPushSubscription) Validate() error {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("endpoint must be an absolute https URL")
+	}
+
+	p256dh, err := decodeBase64Url(s.Keys.P256dh)
+	if err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	if _, err := ecdh.P256().NewPublicKey(p256dh); err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	auth, err := decodeBase64Url(s.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("auth secret must be 16 bytes long")
+	}
+
+	return nil
+}
+
+// ErrWebPushSubscriptionLimit is returned when adding a new subscription would exceed the maximum number of subscriptions.
+var ErrWebPushSubscriptionLimit = errors.New("too many web push subscriptions")
+
+// WebPushSubscriptionStore stores browser subscriptions.
+type WebPushSubscriptionStore interface {
+	// Add saves the subscription, or replaces the one with the same endpoint. A new endpoint is rejected with
+	// ErrWebPushSubscriptionLimit if there are already limit subscriptions.
+	Add(ctx context.Context, sub *WebPushSubscription, limit int) error
+	Remove(ctx context.Context, endpoint string) error
+	List(ctx context.Context) ([]*WebPushSubscription, error)
+}
+
+// VapidKeys is the application server key pair used to identify the sender to push services (RFC 8292).
+type VapidKeys struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+// GenerateVapidKeys creates a new random key pair.
+func GenerateVapidKeys() (*VapidKeys, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &VapidKeys{privateKey: key}, nil
+}
+
+// ParseVapidKeys parses a base64url-encoded raw P-256 private key.
+func ParseVapidKeys(privateKey string) (*VapidKeys, error) {
+	raw, err := decodeBase64Url(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode VAPID private key: %w", err)
+	}
+
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse VAPID private key: %w", err)
+	}
+	return &VapidKeys{privateKey: key}, nil
+}
+
+// PrivateKey returns the base64url-encoded raw private key.
+func (k *VapidKeys) PrivateKey() string {
+	raw, _ := k.privateKey.Bytes() // the key is always valid: it's either generated or parsed
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// PublicKey returns the base64url-encoded uncompressed public key, which browsers use as applicationServerKey.
+func (k *VapidKeys) PublicKey() string {
+	raw, _ := k.privateKey.PublicKey.Bytes()
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// authorizationHeader creates the "vapid" Authorization header value with a JWT signed for the push service of the endpoint.
+func (k *VapidKeys) authorizationHeader(endpoint, subject string, expiresAt time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": expiresAt.Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`)) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+
+	r, s, err := ecdsa.Sign(rand.Reader, k.privateKey, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	signature := make([]byte, 64) // JWS uses fixed-size r || s instead of ASN.1
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", signingInput, base64.RawURLEncoding.EncodeToString(signature), k.PublicKey()), nil
+}
+
+// WebPushPayload is the JSON message delivered to the service worker, which displays it as a notification.
+type WebPushPayload struct {
+	Title string    `json:"title"`
+	Body  string    `json:"body"`
+	Url   string    `json:"url,omitempty"`
+	Type  EventType `json:"type,omitempty"`
+	Tag   string    `json:"tag,omitempty"` // notifications with the same tag replace each other
+}
+
+// WebPushNotifier sends encrypted (RFC 8291) push messages to all subscribed browsers.
+// Subscriptions reported by the push service as expired (404 or 410) are removed from the store.
+type WebPushNotifier struct {
+	cfg        *WebPushConfig
+	log        *logger.Logger
+	httpClient *http.Client
+	store      WebPushSubscriptionStore
+	keys       *VapidKeys
+}
+
+func NewWebPushNotifier(cfg *WebPushConfig, log *logger.Logger, httpClient *http.Client, store WebPushSubscriptionStore, keys *VapidKeys) *WebPushNotifier {
+	return &WebPushNotifier{
+		cfg:        cfg,
+		log:        log,
+		httpClient: httpClient,
+		store:      store,
+		keys:       keys,
+	}
+}
+
+func (w *WebPushNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	title, body := splitPushMessage(text)
+	return w.broadcast(ctx, &WebPushPayload{Title: title, Body: body, Url: w.cfg.ClickUrl}, pushPriorityDefault)
+}
+
+func (w *WebPushNotifier) Notify(ctx context.Context, event *QueueEvent) error {
+	title, body := splitPushMessage(event.Text)
+	payload := &WebPushPayload{
+		Title: title,
+		Body:  body,
+		Url:   w.cfg.ClickUrl,
+		Type:  event.Type,
+		Tag:   fmt.Sprintf(webPushPayloadTagShape, event.QueueID),
+	}
+	return w.broadcast(ctx, payload, eventPushPriority(event.Type))
+}
+
+func (w *WebPushNotifier) broadcast(ctx context.Context, payload *WebPushPayload, priority pushPriority) error {
+	subs, err := w.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list web push subscriptions: %w", err)
+	}
+
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal web push payload: %w", err)
+	}
+
+	var errs []error
+	delivered := 0
+	for _, sub := range subs {
+		err := w.sendWithRetries(ctx, sub, plaintext, priority)
+		switch {
+		case errors.Is(err, errWebPushSubscriptionGone):
+			w.log.Info("Web push subscription is gone, removing it")
+			if err := w.store.Remove(ctx, sub.Endpoint); err != nil {
+				w.log.Error("Failed to remove web push subscription", err)
+			}
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			delivered++
+		}
+	}
+
+	w.log.Info("Web push notifications sent.", "subscriptions", len(subs), "delivered", delivered, "failed", len(errs))
+	return errors.Join(errs...)
+}
+
+func (w *WebPushNotifier) sendWithRetries(ctx context.Context, sub *WebPushSubscription, plaintext []byte, priority pushPriority) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(w.cfg.RequestTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	body, err := encryptWebPushPayload(sub, plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt web push payload: %w", err)
+	}
+
+	authorization, err := w.keys.authorizationHeader(sub.Endpoint, w.cfg.VapidSubject, time.Now().Add(webPushJwtValidity))
+	if err != nil {
+		return err
+	}
+
+	return retry.Do(
+		func() error {
+			req, err := http.NewRequestWithContext(timeoutCtx, "POST", sub.Endpoint, bytes.NewReader(body))
+			if err != nil {
+				return retry.Unrecoverable(fmt.Errorf("failed to create HTTP request: %w", err))
+			}
+			req.Header.Set("Content-Type", "application/octet-stream")
+			req.Header.Set("Content-Encoding", "aes128gcm")
+			req.Header.Set("TTL", strconv.Itoa(w.cfg.MessageTtlSeconds))
+			req.Header.Set("Urgency", webPushUrgency(priority))
+			req.Header.Set("Authorization", authorization)
+
+			resp, err := w.httpClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to send web push message: %w", err)
+			}
+			defer resp.Body.Close()
+
+			switch {
+			case resp.StatusCode >= 200 && resp.StatusCode < 300:
+				return nil
+			case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+				return retry.Unrecoverable(errWebPushSubscriptionGone)
+			}
+
+			respTxt, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			err = fmt.Errorf("sending web push message failed. got unsuccessful status code: %d, response: \"%s\"", resp.StatusCode, respTxt)
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return retry.Unrecoverable(err)
+			}
+			return err
+		},
+		retry.Attempts(w.cfg.MaxRetryAttempts),
+		retry.Delay(time.Duration(w.cfg.RetryDelayMs)*time.Millisecond),
+		retry.DelayType(retry.FixedDelay),
+		retry.Context(timeoutCtx),
+		retry.LastErrorOnly(true),
+	)
+}
+
+func webPushUrgency(priority pushPriority) string {
+	switch priority {
+	case pushPriorityHigh:
+		return "high"
+	case pushPriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// encryptWebPushPayload encrypts the message for the subscription with a random salt and a new ephemeral key.
+func encryptWebPushPayload(sub *WebPushSubscription, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryptWebPushPayloadWithKey(sub, plaintext, salt, asPrivate)
+}
+
+// encryptWebPushPayloadWithKey implements the "aes128gcm" content encoding (RFC 8188) with keys derived according to RFC 8291.
+// The message is sent as a single record, so it must fit into the record size.
+func encryptWebPushPayloadWithKey(sub *WebPushSubscription, plaintext, salt []byte, asPrivate *ecdh.PrivateKey) ([]byte, error) {
+	uaPublicRaw, err := decodeBase64Url(sub.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64Url(sub.Keys.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublicRaw := asPrivate.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublicRaw...), asPublicRaw...)
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, authSecret, string(keyInfo), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	record := append(append([]byte{}, plaintext...), 0x02) // 0x02 delimits the last (and only) record
+	if len(record)+gcm.Overhead() > webPushRecordSize {
+		return nil, fmt.Errorf("payload is too large: %d bytes", len(plaintext))
+	}
+
+	// header: salt (16) || record size (4) || key id length (1) || key id (sender public key)
+	header := make([]byte, 0, 21+len(asPublicRaw))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublicRaw)))
+	header = append(header, asPublicRaw...)
+
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// decodeBase64Url decodes base64url with or without padding, as browsers and tools differ in that.
+func decodeBase64Url(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,443 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type inMemoryWebPushStore struct {
+	mu   sync.Mutex
+	subs map[string]*WebPushSubscription
+}
+
+func newInMemoryWebPushStore(subs ...*WebPushSubscription) *inMemoryWebPushStore {
+	s := &inMemoryWebPushStore{subs: map[string]*WebPushSubscription{}}
+	for _, sub := range subs {
+		s.subs[sub.Endpoint] = sub
+	}
+	return s
+}
+
+func (s *inMemoryWebPushStore) Add(ctx context.Context, sub *WebPushSubscription, limit int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[sub.Endpoint]; !ok && len(s.subs) >= limit {
+		return ErrWebPushSubscriptionLimit
+	}
+	s.subs[sub.Endpoint] = sub
+	return nil
+}
+
+func (s *inMemoryWebPushStore) Remove(ctx context.Context, endpoint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, endpoint)
+	return nil
+}
+
+func (s *inMemoryWebPushStore) List(ctx context.Context) ([]*WebPushSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var subs []*WebPushSubscription
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// testBrowser is the user agent side of a subscription: it holds the private key needed to decrypt messages.
+type testBrowser struct {
+	privateKey *ecdh.PrivateKey
+	authSecret []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate browser key: %v", err)
+	}
+	return &testBrowser{privateKey: key, authSecret: []byte("0123456789abcdef")}
+}
+
+func (b *testBrowser) subscription(endpoint string) *WebPushSubscription {
+	return &WebPushSubscription{
+		Endpoint: endpoint,
+		Keys: WebPushSubscriptionKeys{
+			P256dh: base64.RawURLEncoding.EncodeToString(b.privateKey.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(b.authSecret),
+		},
+	}
+}
+
+// decrypt reverses the aes128gcm encoding the way a browser does.
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+
+	salt, keyIDLen := body[:16], int(body[20])
+	asPublicRaw, ciphertext := body[21:21+keyIDLen], body[21+keyIDLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	if err != nil {
+		t.Fatalf("Invalid sender key in the header: %v", err)
+	}
+	ecdhSecret, _ := b.privateKey.ECDH(asPublic)
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), b.privateKey.PublicKey().Bytes()...), asPublicRaw...)
+	ikm, _ := hkdf.Key(sha256.New, ecdhSecret, b.authSecret, string(keyInfo), 32)
+	prk, _ := hkdf.Extract(sha256.New, ikm, salt)
+	cek, _ := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	record, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("Failed to decrypt push message: %v", err)
+	}
+
+	return bytes.TrimSuffix(record, []byte{0x02})
+}
+
+type receivedPushMessage struct {
+	path    string
+	urgency string
+	ttl     string
+	payload WebPushPayload
+}
+
+// verifyVapidAuthorization checks the JWT signature the way a push service does and returns the claims.
+func verifyVapidAuthorization(t *testing.T, header string) map[string]any {
+	t.Helper()
+
+	token, publicKey, found := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	if !found {
+		t.Fatalf("Unexpected Authorization header format: %s", header)
+	}
+
+	rawKey, _ := base64.RawURLEncoding.DecodeString(publicKey)
+	key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), rawKey)
+	if err != nil {
+		t.Fatalf("Invalid VAPID public key: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(key, hash[:], r, s) {
+		t.Fatal("VAPID token signature is invalid")
+	}
+
+	var claims map[string]any
+	rawClaims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	json.Unmarshal(rawClaims, &claims)
+	return claims
+}
+
+func newTestWebPushNotifier(t *testing.T, store WebPushSubscriptionStore) *WebPushNotifier {
+	keys, err := GenerateVapidKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+
+	cfg := &WebPushConfig{
+		VapidSubject:          "mailto:admin@example.com",
+		ClickUrl:              "https://rezerwacje.duw.pl/",
+		MessageTtlSeconds:     600,
+		MaxRetryAttempts:      1,
+		RetryDelayMs:          100,
+		RequestTimeoutSeconds: 2,
+	}
+	return NewWebPushNotifier(cfg, logger.NewLogger(&logger.Config{Level: "error"}), &http.Client{}, store, keys)
+}
+
+func TestEncryptWebPushPayload_WithRfc8291Example_ProducesExpectedBody(t *testing.T) {
+	// Arrange
+	decode := func(s string) []byte {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return b
+	}
+	asPrivate, err := ecdh.P256().NewPrivateKey(decode("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatalf("Failed to parse sender key: %v", err)
+	}
+	sub := &WebPushSubscription{
+		Endpoint: "https://push.example.net/push/JzLQ3raZJfFBR0aqvOMsLrt54w4rJUsV",
+		Keys: WebPushSubscriptionKeys{
+			P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+			Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+		},
+	}
+
+	// Act
+	body, err := encryptWebPushPayloadWithKey(sub, []byte("When I grow up, I want to be a watermelon"), decode("DGv6ra1nlYgDCS1FRnbzlw"), asPrivate)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected payload to be encrypted, but got error: %v", err)
+	}
+
+	expected := "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	if got := base64.RawURLEncoding.EncodeToString(body); got != expected {
+		t.Errorf("Encrypted body mismatch:\nwant %s\ngot  %s", expected, got)
+	}
+}
+
+func TestWebPushNotify_Always_DeliversEncryptedSignedMessageToPushService(t *testing.T) {
+	// Arrange
+	browser := newTestBrowser(t)
+
+	var received []receivedPushMessage
+	var claims map[string]any
+	var pushServiceURL string
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" {
+			http.Error(w, "unsupported encoding", http.StatusBadRequest)
+			return
+		}
+		claims = verifyVapidAuthorization(t, r.Header.Get("Authorization"))
+
+		body := new(bytes.Buffer)
+		body.ReadFrom(r.Body)
+
+		var payload WebPushPayload
+		json.Unmarshal(browser.decrypt(t, body.Bytes()), &payload)
+		received = append(received, receivedPushMessage{r.URL.Path, r.Header.Get("Urgency"), r.Header.Get("TTL"), payload})
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer pushService.Close()
+	pushServiceURL = pushService.URL
+
+	store := newInMemoryWebPushStore(browser.subscription(pushServiceURL + "/push/browser-1"))
+	sut := newTestWebPushNotifier(t, store)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventQueueOpened, QueueID: 24, Text: testPushText})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected successful delivery, but got error: %v", err)
+	}
+
+	expected := []receivedPushMessage{{
+		path:    "/push/browser-1",
+		urgency: "high",
+		ttl:     "600",
+		payload: WebPushPayload{
+			Title: "🔔 Kolejka Odbiór karty jest teraz dostępna!",
+			Body:  "🧾 Pozostało biletów: 10",
+			Url:   "https://rezerwacje.duw.pl/",
+			Type:  EventQueueOpened,
+			Tag:   "duw-queue-24",
+		},
+	}}
+	if diff := cmp.Diff(expected, received, cmp.AllowUnexported(receivedPushMessage{})); diff != "" {
+		t.Errorf("Received push messages mismatch (-want +got):\n%s", diff)
+	}
+
+	if claims["aud"] != pushServiceURL || claims["sub"] != "mailto:admin@example.com" {
+		t.Errorf("Unexpected VAPID claims: %v", claims)
+	}
+	if exp, _ := claims["exp"].(float64); time.Unix(int64(exp), 0).After(time.Now().Add(24 * time.Hour)) {
+		t.Errorf("VAPID token must expire within 24 hours, but expires at %v", time.Unix(int64(exp), 0))
+	}
+}
+
+func TestWebPushNotify_WhenSubscriptionIsGone_RemovesItFromStore(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+	}{
+		{"Not found", http.StatusNotFound},
+		{"Gone", http.StatusGone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			browser := newTestBrowser(t)
+			pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/push/expired" {
+					w.WriteHeader(tc.statusCode)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer pushService.Close()
+
+			store := newInMemoryWebPushStore(
+				browser.subscription(pushService.URL+"/push/expired"),
+				browser.subscription(pushService.URL+"/push/active"),
+			)
+			sut := newTestWebPushNotifier(t, store)
+
+			// Act
+			err := sut.SendMessage(context.Background(), "", testPushText)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected gone subscription not to be reported as error, but got: %v", err)
+			}
+
+			subs, _ := store.List(context.Background())
+			if len(subs) != 1 || subs[0].Endpoint != pushService.URL+"/push/active" {
+				t.Errorf("Expected only the active subscription to remain, but got %v", subs)
+			}
+		})
+	}
+}
+
+func TestWebPushNotify_WhenPushServiceFails_ReturnsErrorAndKeepsSubscription(t *testing.T) {
+	// Arrange
+	browser := newTestBrowser(t)
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer pushService.Close()
+
+	store := newInMemoryWebPushStore(browser.subscription(pushService.URL + "/push/browser-1"))
+	sut := newTestWebPushNotifier(t, store)
+
+	// Act
+	err := sut.Notify(context.Background(), &QueueEvent{Type: EventTicketsChanged, Text: testPushText})
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when push service fails, but got nil")
+	}
+
+	if subs, _ := store.List(context.Background()); len(subs) != 1 {
+		t.Errorf("Expected subscription to be kept, but got %v", subs)
+	}
+}
+
+func TestWebPushSubscriptionHandler_WhenSubscribing_ValidatesAndStoresSubscription(t *testing.T) {
+	browser := newTestBrowser(t)
+	valid, _ := json.Marshal(browser.subscription("https://fcm.googleapis.com/fcm/send/abc"))
+	subdomain, _ := json.Marshal(browser.subscription("https://wns2-par02p.notify.windows.com/w/?token=abc"))
+	insecure, _ := json.Marshal(browser.subscription("http://fcm.googleapis.com/fcm/send/abc"))
+	internal, _ := json.Marshal(browser.subscription("https://169.254.169.254/latest/meta-data"))
+	lookalike, _ := json.Marshal(browser.subscription("https://fcm.googleapis.com.example.net/fcm/send/abc"))
+	existing := browser.subscription("https://updates.push.services.mozilla.com/wpush/v2/existing")
+
+	testCases := []struct {
+		name           string
+		body           string
+		stored         []*WebPushSubscription
+		expectedStatus int
+		expectedStored int
+	}{
+		{"Valid subscription", string(valid), nil, http.StatusCreated, 1},
+		{"Subdomain of allowed push service", string(subdomain), nil, http.StatusCreated, 1},
+		{"Non-https endpoint", string(insecure), nil, http.StatusBadRequest, 0},
+		{"Push service not allowed", string(internal), nil, http.StatusBadRequest, 0},
+		{"Host ending like allowed push service", string(lookalike), nil, http.StatusBadRequest, 0},
+		{"Invalid keys", `{"endpoint":"https://fcm.googleapis.com/1","keys":{"p256dh":"AAAA","auth":"AAAA"}}`, nil, http.StatusBadRequest, 0},
+		{"Invalid JSON", `{`, nil, http.StatusBadRequest, 0},
+		{"Body too large", `{"endpoint":"` + strings.Repeat("a", webPushMaxSubscriptionBytes) + `"}`, nil, http.StatusRequestEntityTooLarge, 0},
+		{"Limit reached", string(valid), []*WebPushSubscription{existing}, http.StatusServiceUnavailable, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			store := newInMemoryWebPushStore(tc.stored...)
+			keys, _ := GenerateVapidKeys()
+			sut := NewWebPushSubscriptionHandler(newTestWebPushHandlerConfig(1), logger.NewLogger(&logger.Config{Level: "error"}), store, keys).Routes()
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/webpush/subscriptions", strings.NewReader(tc.body))
+
+			// Act
+			sut.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, but got %d", tc.expectedStatus, rec.Code)
+			}
+			if subs, _ := store.List(context.Background()); len(subs) != tc.expectedStored {
+				t.Errorf("Expected %d stored subscriptions, but got %d", tc.expectedStored, len(subs))
+			}
+		})
+	}
+}
+
+func TestWebPushSubscriptionHandler_WhenCalledFromBrowser_AllowsOnlyConfiguredOrigins(t *testing.T) {
+	testCases := []struct {
+		name           string
+		origin         string
+		expectedOrigin string
+	}{
+		{"Allowed origin", "https://duw.example.com", "https://duw.example.com"},
+		{"Other origin", "https://evil.example.net", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			keys, _ := GenerateVapidKeys()
+			sut := NewWebPushSubscriptionHandler(newTestWebPushHandlerConfig(10), logger.NewLogger(&logger.Config{Level: "error"}), newInMemoryWebPushStore(), keys).Routes()
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodOptions, "/webpush/subscriptions", nil)
+			req.Header.Set("Origin", tc.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			// Act
+			sut.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != http.StatusNoContent {
+				t.Errorf("Expected preflight to succeed, but got %d", rec.Code)
+			}
+			if actual := rec.Header().Get("Access-Control-Allow-Origin"); actual != tc.expectedOrigin {
+				t.Errorf("Expected allowed origin %q, but got %q", tc.expectedOrigin, actual)
+			}
+		})
+	}
+}
+
+func newTestWebPushHandlerConfig(maxSubscriptions int) *WebPushConfig {
+	return &WebPushConfig{
+		AllowedPushHosts: []string{"fcm.googleapis.com", "updates.push.services.mozilla.com", "*.notify.windows.com"},
+		AllowedOrigins:   []string{"https://duw.example.com"},
+		MaxSubscriptions: maxSubscriptions,
+	}
+}
+
+func TestParseVapidKeys_WithGeneratedPrivateKey_ReturnsSameKeys(t *testing.T) {
+	// Arrange
+	generated, _ := GenerateVapidKeys()
+
+	// Act
+	parsed, err := ParseVapidKeys(generated.PrivateKey())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected key to be parsed, but got error: %v", err)
+	}
+	if parsed.PublicKey() != generated.PublicKey() {
+		t.Errorf("Expected public key %s, but got %s", generated.PublicKey(), parsed.PublicKey())
+	}
+}
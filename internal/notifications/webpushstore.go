@@ -0,0 +1,256 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	webPushSubscriptionsRedisKey = "webpush:subscriptions"
+	webPushVapidKeyRedisKey      = "webpush:vapid_private_key"
+
+	webPushMaxSubscriptionBytes = 4096
+)
+
+// RedisWebPushSubscriptionStore keeps subscriptions in a Redis hash keyed by endpoint, so re-subscribing the same browser replaces its keys.
+type RedisWebPushSubscriptionStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisWebPushSubscriptionStore(redisClient *redis.Client) *RedisWebPushSubscriptionStore {
+	return &RedisWebPushSubscriptionStore{redisClient: redisClient}
+}
+
+func (s *RedisWebPushSubscriptionStore) Add(ctx context.Context, sub *WebPushSubscription, limit int) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("failed to marshal web push subscription: %w", err)
+	}
+
+	add := func(tx *redis.Tx) error {
+		exists, err := tx.HExists(ctx, webPushSubscriptionsRedisKey, sub.Endpoint).Result()
+		if err != nil {
+			return fmt.Errorf("failed to find web push subscription: %w", err)
+		}
+		count, err := tx.HLen(ctx, webPushSubscriptionsRedisKey).Result()
+		if err != nil {
+			return fmt.Errorf("failed to count web push subscriptions: %w", err)
+		}
+		if !exists && count >= int64(limit) {
+			return ErrWebPushSubscriptionLimit
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, webPushSubscriptionsRedisKey, sub.Endpoint, data)
+			return nil
+		})
+		return err
+	}
+
+	// WATCH makes sure concurrent subscriptions don't exceed the limit
+	if err := s.redisClient.Watch(ctx, add, webPushSubscriptionsRedisKey); err != nil {
+		if errors.Is(err, ErrWebPushSubscriptionLimit) {
+			return err
+		}
+		return fmt.Errorf("failed to save web push subscription to Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisWebPushSubscriptionStore) Remove(ctx context.Context, endpoint string) error {
+	if err := s.redisClient.HDel(ctx, webPushSubscriptionsRedisKey, endpoint).Err(); err != nil {
+		return fmt.Errorf("failed to remove web push subscription from Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisWebPushSubscriptionStore) List(ctx context.Context) ([]*WebPushSubscription, error) {
+	entries, err := s.redisClient.HGetAll(ctx, webPushSubscriptionsRedisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get web push subscriptions from Redis: %w", err)
+	}
+
+	subs := make([]*WebPushSubscription, 0, len(entries))
+	for _, data := range entries {
+		var sub WebPushSubscription
+		if err := json.Unmarshal([]byte(data), &sub); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal web push subscription: %w", err)
+		}
+		subs = append(subs, &sub)
+	}
+	return subs, nil
+}
+
+// LoadOrCreateVapidKeys returns the configured VAPID keys. If none are configured, a key pair is generated once and stored in Redis,
+// because browsers bind their subscriptions to the key: changing it invalidates all existing subscriptions.
+func LoadOrCreateVapidKeys(ctx context.Context, cfg *WebPushConfig, redisClient *redis.Client) (*VapidKeys, error) {
+	if cfg.VapidPrivateKey != "" {
+		return ParseVapidKeys(cfg.VapidPrivateKey)
+	}
+
+	generated, err := GenerateVapidKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate VAPID keys: %w", err)
+	}
+
+	// SETNX keeps the key of whichever instance stored it first
+	if err := redisClient.SetNX(ctx, webPushVapidKeyRedisKey, generated.PrivateKey(), 0).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save VAPID keys to Redis: %w", err)
+	}
+
+	stored, err := redisClient.Get(ctx, webPushVapidKeyRedisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VAPID keys from Redis: %w", err)
+	}
+	return ParseVapidKeys(stored)
+}
+
+// WebPushSubscriptionHandler exposes the endpoints used by the web page to subscribe browsers:
+//
+//	GET    /webpush/vapid-public-key  returns the applicationServerKey
+//	POST   /webpush/subscriptions     saves the PushSubscription JSON
+//	DELETE /webpush/subscriptions     removes the PushSubscription JSON
+//
+// The endpoints are public, so only subscriptions of the allowed push services are accepted, up to the configured number,
+// and only the allowed origins may call them from a browser.
+type WebPushSubscriptionHandler struct {
+	cfg   *WebPushConfig
+	log   *logger.Logger
+	store WebPushSubscriptionStore
+	keys  *VapidKeys
+}
+
+func NewWebPushSubscriptionHandler(cfg *WebPushConfig, log *logger.Logger, store WebPushSubscriptionStore, keys *VapidKeys) *WebPushSubscriptionHandler {
+	return &WebPushSubscriptionHandler{
+		cfg:   cfg,
+		log:   log,
+		store: store,
+		keys:  keys,
+	}
+}
+
+func (h *WebPushSubscriptionHandler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /webpush/vapid-public-key", h.handlePublicKey)
+	mux.HandleFunc("POST /webpush/subscriptions", h.handleSubscribe)
+	mux.HandleFunc("DELETE /webpush/subscriptions", h.handleUnsubscribe)
+	mux.HandleFunc("OPTIONS /webpush/", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	return h.cors(mux)
+}
+
+// cors lets the allowed origins call the endpoints, including the preflight requests sent for the JSON bodies.
+func (h *WebPushSubscriptionHandler) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		if origin := r.Header.Get("Origin"); origin != "" && slices.Contains(h.cfg.AllowedOrigins, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "3600")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *WebPushSubscriptionHandler) handlePublicKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, h.keys.PublicKey())
+}
+
+func (h *WebPushSubscriptionHandler) handleSubscribe(w http.ResponseWriter, r *http.Request) {
+	sub, err := decodeWebPushSubscription(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), webPushRequestErrorStatus(err))
+		return
+	}
+	if !isAllowedPushHost(sub.Endpoint, h.cfg.AllowedPushHosts) {
+		http.Error(w, "invalid subscription: push service is not supported", http.StatusBadRequest)
+		return
+	}
+
+	err = h.store.Add(r.Context(), sub, h.cfg.MaxSubscriptions)
+	if errors.Is(err, ErrWebPushSubscriptionLimit) {
+		h.log.Warn("Rejected web push subscription, the limit is reached", "limit", h.cfg.MaxSubscriptions)
+		http.Error(w, "too many subscriptions", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to save web push subscription", err)
+		http.Error(w, "failed to save subscription", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("Web push subscription added")
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *WebPushSubscriptionHandler) handleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	var sub WebPushSubscription
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, webPushMaxSubscriptionBytes)).Decode(&sub); err != nil || sub.Endpoint == "" {
+		http.Error(w, "invalid subscription", webPushRequestErrorStatus(err))
+		return
+	}
+
+	if err := h.store.Remove(r.Context(), sub.Endpoint); err != nil {
+		h.log.Error("Failed to remove web push subscription", err)
+		http.Error(w, "failed to remove subscription", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("Web push subscription removed")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeWebPushSubscription(w http.ResponseWriter, r *http.Request) (*WebPushSubscription, error) {
+	var sub WebPushSubscription
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, webPushMaxSubscriptionBytes)).Decode(&sub); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, errors.New("invalid subscription JSON")
+	}
+	if err := sub.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// webPushRequestErrorStatus tells a body above webPushMaxSubscriptionBytes apart from an invalid one.
+func webPushRequestErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// isAllowedPushHost reports whether the endpoint is on one of the hosts, where "*.example.com" matches any subdomain.
+func isAllowedPushHost(endpoint string, hosts []string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range hosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok && strings.HasSuffix(host, suffix) {
+			return true
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
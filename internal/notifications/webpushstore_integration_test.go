@@ -0,0 +1,56 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRedisWebPushSubscriptionStore_WhenRedisIsAvailable_AddsListsAndRemovesSubscriptions(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisWebPushSubscriptionStore(initRedisContainer(ctx, t))
+
+	first := &WebPushSubscription{Endpoint: "https://push.example.net/1", Keys: WebPushSubscriptionKeys{P256dh: "key-1", Auth: "auth-1"}}
+	second := &WebPushSubscription{Endpoint: "https://push.example.net/2", Keys: WebPushSubscriptionKeys{P256dh: "key-2", Auth: "auth-2"}}
+
+	// Act
+	addFirstErr := sut.Add(ctx, first, 1)
+	overLimitErr := sut.Add(ctx, second, 1)
+	replaceErr := sut.Add(ctx, first, 1)
+	addSecondErr := sut.Add(ctx, second, 2)
+	removeErr := sut.Remove(ctx, first.Endpoint)
+	subs, listErr := sut.List(ctx)
+
+	// Assert
+	if addFirstErr != nil || replaceErr != nil || addSecondErr != nil || removeErr != nil || listErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v, %v, %v", addFirstErr, replaceErr, addSecondErr, removeErr, listErr)
+	}
+	if !errors.Is(overLimitErr, ErrWebPushSubscriptionLimit) {
+		t.Errorf("Expected a new subscription above the limit to be rejected, but got %v", overLimitErr)
+	}
+
+	if len(subs) != 1 || *subs[0] != *second {
+		t.Errorf("Expected only the second subscription to remain, but got %v", subs)
+	}
+}
+
+func TestLoadOrCreateVapidKeys_WhenNotConfigured_GeneratesKeysOnceAndReusesThem(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisClient := initRedisContainer(ctx, t)
+	cfg := &WebPushConfig{}
+
+	// Act
+	first, firstErr := LoadOrCreateVapidKeys(ctx, cfg, redisClient)
+	second, secondErr := LoadOrCreateVapidKeys(ctx, cfg, redisClient)
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v", firstErr, secondErr)
+	}
+
+	if first.PublicKey() != second.PublicKey() {
+		t.Errorf("Expected the stored key to be reused, but got %s and %s", first.PublicKey(), second.PublicKey())
+	}
+}
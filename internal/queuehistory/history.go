@@ -0,0 +1,132 @@
+// Package queuehistory keeps the queue statuses observed by the queue monitor, day by day, for the daily summaries and statistics.
+package queuehistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	historyRedisKeyPrefix = "queue:history:"
+	dayLayout             = "2006-01-02"
+)
+
+type Config struct {
+	RetentionDays int `env:"HISTORY_RETENTION_DAYS" envDefault:"120"` // the statistics cover at most 90 days
+}
+
+// Observation is a queue status observed by the queue monitor. Only changes of the status are recorded.
+type Observation struct {
+	At          time.Time `json:"at"`
+	QueueName   string    `json:"queue_name"`
+	Active      bool      `json:"active"`
+	Enabled     bool      `json:"enabled"`
+	TicketValue string    `json:"ticket_value,omitempty"`
+	TicketsLeft int       `json:"tickets_left"`
+}
+
+// SameStatus reports whether the observations describe the same queue status, regardless of when it was observed.
+func (o *Observation) SameStatus(other *Observation) bool {
+	return other != nil &&
+		o.QueueName == other.QueueName &&
+		o.Active == other.Active &&
+		o.Enabled == other.Enabled &&
+		o.TicketValue == other.TicketValue &&
+		o.TicketsLeft == other.TicketsLeft
+}
+
+// Store keeps the observations of each queue by local day.
+type Store interface {
+	Append(ctx context.Context, queueID int, obs *Observation) error
+	Day(ctx context.Context, queueID int, day time.Time) ([]*Observation, error) // oldest first, in the local time of the store, empty if nothing was observed
+	Location() *time.Location                                                    // the local time of the store, which splits the days
+}
+
+// Day is the history of a single day, see Store.Day.
+type Day struct {
+	Date         time.Time
+	Observations []*Observation
+}
+
+// PreviousWeeks returns the history of the same weekday of the given number of weeks before the day, most recent first.
+// The days which can't be read are skipped, and their errors are returned joined, along with the days which were read.
+func PreviousWeeks(ctx context.Context, store Store, queueID int, day time.Time, weeks int) ([]*Day, error) {
+	days := make([]*Day, 0, weeks)
+	var errs []error
+	for week := 1; week <= weeks; week++ {
+		date := day.AddDate(0, 0, -7*week)
+		observations, err := store.Day(ctx, queueID, date)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get the queue history of %d weeks ago: %w", week, err))
+			continue
+		}
+		days = append(days, &Day{Date: date, Observations: observations})
+	}
+	return days, errors.Join(errs...)
+}
+
+// RedisStore keeps the observations of a day in a Redis list, which expires after the retention period.
+// The days are split in the time zone of the office, and the observations are returned in it.
+type RedisStore struct {
+	redisClient *redis.Client
+	location    *time.Location
+	retention   time.Duration
+}
+
+func NewRedisStore(cfg *Config, redisClient *redis.Client, location *time.Location) *RedisStore {
+	return &RedisStore{
+		redisClient: redisClient,
+		location:    location,
+		retention:   time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+	}
+}
+
+func (s *RedisStore) Append(ctx context.Context, queueID int, obs *Observation) error {
+	data, err := json.Marshal(obs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue observation: %w", err)
+	}
+
+	key := s.dayKey(queueID, obs.At)
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, data)
+		pipe.Expire(ctx, key, s.retention)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save queue observation to Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Day(ctx context.Context, queueID int, day time.Time) ([]*Observation, error) {
+	values, err := s.redisClient.LRange(ctx, s.dayKey(queueID, day), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue history from Redis: %w", err)
+	}
+
+	observations := make([]*Observation, 0, len(values))
+	for _, v := range values {
+		var obs Observation
+		if err := json.Unmarshal([]byte(v), &obs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal queue observation: %w", err)
+		}
+		obs.At = obs.At.In(s.location)
+		observations = append(observations, &obs)
+	}
+	return observations, nil
+}
+
+func (s *RedisStore) Location() *time.Location {
+	return s.location
+}
+
+func (s *RedisStore) dayKey(queueID int, t time.Time) string {
+	return historyRedisKeyPrefix + strconv.Itoa(queueID) + ":" + t.In(s.location).Format(dayLayout)
+}
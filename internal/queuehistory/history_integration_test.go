@@ -0,0 +1,80 @@
+package queuehistory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func initHistoryRedisContainer(ctx context.Context, t *testing.T) *redis.Client {
+	req := testcontainers.ContainerRequest{
+		Image:        "redis:latest",
+		Name:         "queuehistory-redis-integration-test",
+		ExposedPorts: []string{"6379/tcp"},
+		WaitingFor:   wait.ForLog("Ready to accept connections"),
+	}
+
+	redisC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start Redis container: \"%v\". Test cannot be executed", err)
+	}
+	t.Cleanup(func() { testcontainers.CleanupContainer(t, redisC) })
+
+	endpoint, err := redisC.Endpoint(ctx, "")
+	if err != nil {
+		t.Fatalf("Failed to get Redis endpoint: \"%v\". Test cannot be executed", err)
+	}
+
+	return redis.NewClient(&redis.Options{Addr: endpoint})
+}
+
+func TestRedisStore_WhenObservationsAreAppended_ReturnsThemByLocalDay(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisClient := initHistoryRedisContainer(ctx, t)
+	warsaw, _ := time.LoadLocation("Europe/Warsaw")
+	sut := NewRedisStore(&Config{RetentionDays: 1}, redisClient, warsaw)
+
+	evening := &Observation{At: time.Date(2026, 3, 2, 22, 30, 0, 0, time.UTC), QueueName: "Odbiór karty"} // 23:30 in Warsaw
+	night := &Observation{At: time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC), QueueName: "Odbiór karty"}  // 00:30 the next day
+	opening := &Observation{At: time.Date(2026, 3, 3, 7, 0, 0, 0, time.UTC), QueueName: "Odbiór karty", Active: true, Enabled: true, TicketValue: "K1", TicketsLeft: 120}
+
+	// Act
+	var appendErrs []error
+	for _, obs := range []*Observation{evening, night, opening} {
+		appendErrs = append(appendErrs, sut.Append(ctx, 24, obs))
+	}
+	day, dayErr := sut.Day(ctx, 24, time.Date(2026, 3, 3, 12, 0, 0, 0, warsaw))
+	otherQueue, otherErr := sut.Day(ctx, 25, time.Date(2026, 3, 3, 12, 0, 0, 0, warsaw))
+
+	// Assert
+	for _, err := range append(appendErrs, dayErr, otherErr) {
+		if err != nil {
+			t.Fatalf("Expected no errors, but got: %v", err)
+		}
+	}
+
+	expected := []*Observation{night, opening}
+	if diff := cmp.Diff(expected, day); diff != "" {
+		t.Errorf("Observations mismatch (-want +got):\n%s", diff)
+	}
+	if day[0].At.Location() != sut.Location() {
+		t.Errorf("Expected observations in the local time, got %v", day[0].At)
+	}
+	if len(otherQueue) != 0 {
+		t.Errorf("Expected no observations of another queue, got %d", len(otherQueue))
+	}
+
+	ttl := redisClient.TTL(ctx, "queue:history:24:2026-03-03").Val()
+	if ttl <= 0 || ttl > 24*time.Hour {
+		t.Errorf("Expected the day to expire after the retention period, got TTL %v", ttl)
+	}
+}
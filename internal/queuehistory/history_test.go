@@ -0,0 +1,50 @@
+package queuehistory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPreviousWeeks_Always_ReturnsSameWeekdayOfPreviousWeeks(t *testing.T) {
+	// Arrange
+	store := newTestStore()
+	today := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	expected := []*Day{
+		{Date: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Observations: store.days["2026-03-02"]},
+		{Date: time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)},
+	}
+
+	// Act
+	actual, err := PreviousWeeks(context.Background(), store, 24, today, 2)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("Unexpected days (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestPreviousWeeks_WhenStoreFails_SkipsDaysAndReturnsError(t *testing.T) {
+	// Arrange
+	store := &inMemoryStore{shouldFail: true}
+
+	// Act
+	actual, err := PreviousWeeks(context.Background(), store, 24, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), 3)
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error, but got nil")
+	}
+	if len(actual) != 0 {
+		t.Errorf("Expected no days, got %d", len(actual))
+	}
+	if store.reads != 3 {
+		t.Errorf("Expected every week to be read, got %d reads", store.reads)
+	}
+}
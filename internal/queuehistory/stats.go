@@ -0,0 +1,153 @@
+package queuehistory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// StatsPeriods are the periods of the statistics, in days.
+var StatsPeriods = []int{7, 30, 90}
+
+// Stats describes the queue over the days of a period, see LoadStats. Only the days the queue was observed are counted.
+type Stats struct {
+	Period             int // days in the period
+	Days               int // days the queue was observed
+	OpenedDays         int
+	SoldOutDays        int
+	MedianSoldOutAfter time.Duration   // how long the tickets of an opening usually last, zero if they never ran out
+	Weekdays           []*WeekdayStats // Monday first, only the weekdays the queue was observed
+}
+
+// OpenedShare returns the share of the observed days the queue opened.
+func (s *Stats) OpenedShare() float64 {
+	if s.Days == 0 {
+		return 0
+	}
+	return float64(s.OpenedDays) / float64(s.Days)
+}
+
+// WeekdayStats describes the queue on a day of the week.
+type WeekdayStats struct {
+	Weekday          time.Weekday
+	Days             int
+	OpenedDays       int
+	TypicalOpeningAt time.Duration // median time of the day of the opening, zero if the queue never opened
+}
+
+// OpeningsShare returns the share of all the openings of the period which happened on the weekday.
+func (w *WeekdayStats) OpeningsShare(stats *Stats) float64 {
+	if stats.OpenedDays == 0 {
+		return 0
+	}
+	return float64(w.OpenedDays) / float64(stats.OpenedDays)
+}
+
+// LoadStats computes the statistics of the period days before today, which must be in the local time of the store.
+// Today is not counted: it is not over yet.
+func LoadStats(ctx context.Context, store Store, queueID int, today time.Time, period int) (*Stats, error) {
+	stats := &Stats{Period: period}
+	weekdays := make(map[time.Weekday]*WeekdayStats)
+	openings := make(map[time.Weekday][]time.Duration)
+	var soldOutAfter []time.Duration
+
+	for i := 1; i <= period; i++ {
+		day := today.AddDate(0, 0, -i)
+		observations, err := store.Day(ctx, queueID, day)
+		if err != nil {
+			return nil, err
+		}
+		if len(observations) == 0 {
+			continue
+		}
+
+		weekday := weekdays[day.Weekday()]
+		if weekday == nil {
+			weekday = &WeekdayStats{Weekday: day.Weekday()}
+			weekdays[day.Weekday()] = weekday
+		}
+		stats.Days++
+		weekday.Days++
+
+		summary := Summarize(observations)
+		if !summary.Opened {
+			continue
+		}
+		stats.OpenedDays++
+		weekday.OpenedDays++
+		openings[day.Weekday()] = append(openings[day.Weekday()], Clock(summary.OpenedAt))
+		if after := summary.SoldOutAfter(); after > 0 {
+			stats.SoldOutDays++
+			soldOutAfter = append(soldOutAfter, after)
+		}
+	}
+
+	stats.MedianSoldOutAfter = median(soldOutAfter)
+	for _, weekday := range weekdays {
+		weekday.TypicalOpeningAt = median(openings[weekday.Weekday])
+		stats.Weekdays = append(stats.Weekdays, weekday)
+	}
+	slices.SortFunc(stats.Weekdays, func(a, b *WeekdayStats) int {
+		return mondayFirst(a.Weekday) - mondayFirst(b.Weekday)
+	})
+	return stats, nil
+}
+
+// median returns the median of the durations rounded to a minute, or zero if there are none.
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	slices.Sort(durations)
+	middle := len(durations) / 2
+	if len(durations)%2 == 1 {
+		return durations[middle].Round(time.Minute)
+	}
+	return ((durations[middle-1] + durations[middle]) / 2).Round(time.Minute)
+}
+
+func mondayFirst(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+// StatsCache keeps the statistics of each period until the day changes: the statistics don't count today, so they
+// can't change before. It's safe for concurrent use.
+type StatsCache struct {
+	store   Store
+	queueID int
+
+	mu      sync.Mutex
+	entries map[int]*cachedStats // by period
+}
+
+type cachedStats struct {
+	day   string
+	stats *Stats
+}
+
+func NewStatsCache(store Store, queueID int) *StatsCache {
+	return &StatsCache{
+		store:   store,
+		queueID: queueID,
+		entries: make(map[int]*cachedStats),
+	}
+}
+
+// Get returns the statistics of the period days before today, see LoadStats. Failures are not cached.
+func (c *StatsCache) Get(ctx context.Context, today time.Time, period int) (*Stats, error) {
+	day := today.Format(dayLayout)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[period]; ok && entry.day == day {
+		return entry.stats, nil
+	}
+
+	stats, err := LoadStats(ctx, c.store, c.queueID, today, period)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[period] = &cachedStats{day: day, stats: stats}
+	return stats, nil
+}
@@ -0,0 +1,193 @@
+package queuehistory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// inMemoryStore keeps the observations by day, in UTC.
+type inMemoryStore struct {
+	days       map[string][]*Observation
+	reads      int
+	shouldFail bool
+}
+
+func (s *inMemoryStore) Append(ctx context.Context, queueID int, obs *Observation) error {
+	day := obs.At.Format(dayLayout)
+	s.days[day] = append(s.days[day], obs)
+	return nil
+}
+
+func (s *inMemoryStore) Day(ctx context.Context, queueID int, day time.Time) ([]*Observation, error) {
+	s.reads++
+	if s.shouldFail {
+		return nil, fmt.Errorf("failed to get queue history")
+	}
+	return s.days[day.Format(dayLayout)], nil
+}
+
+func (s *inMemoryStore) Location() *time.Location {
+	return time.UTC
+}
+
+func observedOn(date, clock string, active, enabled bool, ticketsLeft int) *Observation {
+	at, _ := time.Parse("2006-01-02 15:04", date+" "+clock)
+	return &Observation{At: at, QueueName: "Odbiór karty", Active: active, Enabled: enabled, TicketsLeft: ticketsLeft}
+}
+
+// openedOn returns the observations of a day the queue opened at the given time, and the tickets ran out after soldOutAfter,
+// unless it's zero.
+func openedOn(date, openedAt string, soldOutAfter time.Duration) []*Observation {
+	opened := observedOn(date, openedAt, true, true, 100)
+	observations := []*Observation{observedOn(date, "07:00", true, false, 0), opened}
+	if soldOutAfter > 0 {
+		observations = append(observations, &Observation{At: opened.At.Add(soldOutAfter), QueueName: "Odbiór karty", Active: true})
+	}
+	return append(observations, observedOn(date, "16:00", false, false, 0))
+}
+
+func newTestStore() *inMemoryStore {
+	return &inMemoryStore{days: map[string][]*Observation{
+		"2026-03-02": openedOn("2026-03-02", "08:10", time.Hour),          // Monday
+		"2026-03-03": openedOn("2026-03-03", "08:30", 20*time.Minute),     // Tuesday
+		"2026-03-04": {observedOn("2026-03-04", "08:00", true, false, 0)}, // Wednesday, didn't open
+		"2026-03-06": openedOn("2026-03-06", "09:00", 0),                  // Friday, tickets didn't run out
+		"2026-03-09": openedOn("2026-03-09", "07:30", time.Minute),        // today
+	}}
+}
+
+func TestLoadStats_Always_DescribesObservedDaysOfThePeriod(t *testing.T) {
+	// Arrange
+	store := newTestStore()
+	today := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+
+	expected := &Stats{
+		Period:             7,
+		Days:               4,
+		OpenedDays:         3,
+		SoldOutDays:        2,
+		MedianSoldOutAfter: 40 * time.Minute,
+		Weekdays: []*WeekdayStats{
+			{Weekday: time.Monday, Days: 1, OpenedDays: 1, TypicalOpeningAt: 8*time.Hour + 10*time.Minute},
+			{Weekday: time.Tuesday, Days: 1, OpenedDays: 1, TypicalOpeningAt: 8*time.Hour + 30*time.Minute},
+			{Weekday: time.Wednesday, Days: 1},
+			{Weekday: time.Friday, Days: 1, OpenedDays: 1, TypicalOpeningAt: 9 * time.Hour},
+		},
+	}
+
+	// Act
+	actual, err := LoadStats(context.Background(), store, 24, today, 7)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("Unexpected stats (-expected +actual):\n%s", diff)
+	}
+	if share := actual.OpenedShare(); share != 0.75 {
+		t.Errorf("Expected the queue to open on 75%% of days, got %v", share)
+	}
+	if share := actual.Weekdays[0].OpeningsShare(actual); share != 1.0/3 {
+		t.Errorf("Expected a third of the openings on Monday, got %v", share)
+	}
+}
+
+func TestLoadStats_WhenNothingWasObserved_ReturnsEmptyStats(t *testing.T) {
+	// Arrange
+	store := &inMemoryStore{days: map[string][]*Observation{}}
+
+	// Act
+	actual, err := LoadStats(context.Background(), store, 24, time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC), 30)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if diff := cmp.Diff(&Stats{Period: 30}, actual); diff != "" {
+		t.Errorf("Unexpected stats (-expected +actual):\n%s", diff)
+	}
+	if actual.OpenedShare() != 0 {
+		t.Errorf("Expected no share without observed days, got %v", actual.OpenedShare())
+	}
+}
+
+func TestMedian_Always_ReturnsMiddleValueRoundedToMinute(t *testing.T) {
+	testCases := []struct {
+		name      string
+		durations []time.Duration
+		expected  time.Duration
+	}{
+		{"No values", nil, 0},
+		{"Odd number of values", []time.Duration{30 * time.Minute, 10 * time.Minute, 20*time.Minute + 20*time.Second}, 20 * time.Minute},
+		{"Even number of values", []time.Duration{10 * time.Minute, 40 * time.Minute, 15 * time.Minute, 5 * time.Minute}, 13 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := median(tc.durations)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStatsCacheGet_Always_LoadsStatsOncePerPeriodAndDay(t *testing.T) {
+	// Arrange
+	store := newTestStore()
+	sut := NewStatsCache(store, 24)
+	ctx := context.Background()
+	today := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+
+	// Act
+	first, _ := sut.Get(ctx, today, 7)
+	second, _ := sut.Get(ctx, today.Add(time.Hour), 7)
+	readsOfDay := store.reads
+	other, _ := sut.Get(ctx, today, 30)
+	readsOfPeriods := store.reads
+	tomorrow, _ := sut.Get(ctx, today.AddDate(0, 0, 1), 7)
+
+	// Assert
+	if first == nil || first != second {
+		t.Errorf("Expected the stats to be cached during the day, got %p and %p", first, second)
+	}
+	if readsOfDay != 7 {
+		t.Errorf("Expected the days of the period to be read once, got %d reads", readsOfDay)
+	}
+	if other == nil || other.Period != 30 || readsOfPeriods != 37 {
+		t.Errorf("Expected each period to be cached separately, got %+v after %d reads", other, readsOfPeriods)
+	}
+	if tomorrow == nil || tomorrow == first || tomorrow.Days != 4 {
+		t.Errorf("Expected the stats to be loaded again the next day, got %+v", tomorrow)
+	}
+}
+
+func TestStatsCacheGet_WhenStoreFails_DoesNotCacheFailure(t *testing.T) {
+	// Arrange
+	store := newTestStore()
+	store.shouldFail = true
+	sut := NewStatsCache(store, 24)
+	ctx := context.Background()
+	today := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+
+	// Act
+	_, failed := sut.Get(ctx, today, 7)
+	store.shouldFail = false
+	stats, err := sut.Get(ctx, today, 7)
+
+	// Assert
+	if failed == nil {
+		t.Error("Expected the failure of the store to be returned")
+	}
+	if err != nil || stats == nil || stats.Days != 4 {
+		t.Errorf("Expected the stats to be loaded after the store recovered, got %+v, %v", stats, err)
+	}
+}
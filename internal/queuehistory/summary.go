@@ -0,0 +1,87 @@
+package queuehistory
+
+import "time"
+
+// DaySummary describes how the queue behaved during a day, see Summarize.
+type DaySummary struct {
+	Opened           bool      // tickets could be taken during the day
+	OpenedAt         time.Time // when tickets could be taken for the first time
+	TicketsAtOpening int
+	SoldOutAt        time.Time     // when the tickets ran out for the first time while the queue was active, zero if they didn't
+	EnabledDuration  time.Duration // how long tickets could be taken, over all openings
+	Reopenings       int           // how many times tickets could be taken again after the first opening
+	Finished         bool          // the queue became inactive after it was active, e.g. at the end of the office hours
+}
+
+// Summarize summarizes the observations of a day, oldest first. The enabled period still going on at the last observation is not counted.
+func Summarize(observations []*Observation) *DaySummary {
+	summary := &DaySummary{}
+	wasActive, wasEnabled := false, false
+	var enabledSince time.Time
+
+	for _, obs := range observations {
+		enabled := obs.Active && obs.Enabled
+		switch {
+		case enabled && !wasEnabled && !summary.Opened:
+			summary.Opened = true
+			summary.OpenedAt = obs.At
+			summary.TicketsAtOpening = obs.TicketsLeft
+			enabledSince = obs.At
+		case enabled && !wasEnabled:
+			summary.Reopenings++
+			enabledSince = obs.At
+		case !enabled && wasEnabled:
+			summary.EnabledDuration += obs.At.Sub(enabledSince)
+			if obs.Active && summary.SoldOutAt.IsZero() {
+				summary.SoldOutAt = obs.At
+			}
+		}
+
+		summary.Finished = !obs.Active && (wasActive || summary.Finished)
+		wasActive, wasEnabled = obs.Active, enabled
+	}
+	return summary
+}
+
+// SoldOutAfter returns how long it took to take all the tickets of the first opening, or zero if they didn't run out.
+func (s *DaySummary) SoldOutAfter() time.Duration {
+	if !s.Opened || s.SoldOutAt.IsZero() {
+		return 0
+	}
+	return s.SoldOutAt.Sub(s.OpenedAt)
+}
+
+// Average is the average of the days the queue opened, e.g. of the same weekday in the previous weeks.
+type Average struct {
+	Days             int           // number of the averaged days
+	OpenedAt         time.Duration // time of the day, since midnight
+	TicketsAtOpening int
+	EnabledDuration  time.Duration
+}
+
+// AverageOf averages the summaries of the days the queue opened. Returns nil if it didn't open on any of them.
+func AverageOf(summaries []*DaySummary) *Average {
+	var avg Average
+	for _, s := range summaries {
+		if s == nil || !s.Opened {
+			continue
+		}
+		avg.Days++
+		avg.OpenedAt += Clock(s.OpenedAt)
+		avg.TicketsAtOpening += s.TicketsAtOpening
+		avg.EnabledDuration += s.EnabledDuration
+	}
+	if avg.Days == 0 {
+		return nil
+	}
+
+	avg.OpenedAt = (avg.OpenedAt / time.Duration(avg.Days)).Round(time.Minute)
+	avg.TicketsAtOpening = (avg.TicketsAtOpening + avg.Days/2) / avg.Days
+	avg.EnabledDuration = (avg.EnabledDuration / time.Duration(avg.Days)).Round(time.Minute)
+	return &avg
+}
+
+// Clock returns the time of the day of t, in its location.
+func Clock(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
@@ -0,0 +1,119 @@
+package queuehistory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func observedAt(clock string, active, enabled bool, ticketsLeft int) *Observation {
+	at, _ := time.Parse("2006-01-02 15:04", "2026-03-02 "+clock)
+	return &Observation{At: at, QueueName: "Odbiór karty", Active: active, Enabled: enabled, TicketsLeft: ticketsLeft}
+}
+
+func TestSummarize_Always_DescribesOpeningsOfTheDay(t *testing.T) {
+	testCases := []struct {
+		name         string
+		observations []*Observation
+		expected     *DaySummary
+	}{
+		{
+			"Nothing observed",
+			nil,
+			&DaySummary{},
+		},
+		{
+			"Queue opened and tickets ran out",
+			[]*Observation{
+				observedAt("07:00", false, false, 0),
+				observedAt("08:00", true, false, 0),
+				observedAt("08:10", true, true, 120),
+				observedAt("08:40", true, true, 30),
+				observedAt("09:10", true, false, 0),
+				observedAt("15:00", false, false, 0),
+			},
+			&DaySummary{
+				Opened:           true,
+				OpenedAt:         observedAt("08:10", true, true, 0).At,
+				TicketsAtOpening: 120,
+				SoldOutAt:        observedAt("09:10", true, false, 0).At,
+				EnabledDuration:  time.Hour,
+				Finished:         true,
+			},
+		},
+		{
+			"Queue reopened and closed while tickets were left",
+			[]*Observation{
+				observedAt("08:00", true, true, 50),
+				observedAt("08:30", true, false, 0),
+				observedAt("10:00", true, true, 5),
+				observedAt("10:15", false, false, 0),
+			},
+			&DaySummary{
+				Opened:           true,
+				OpenedAt:         observedAt("08:00", true, true, 0).At,
+				TicketsAtOpening: 50,
+				SoldOutAt:        observedAt("08:30", true, false, 0).At,
+				EnabledDuration:  45 * time.Minute,
+				Reopenings:       1,
+				Finished:         true,
+			},
+		},
+		{
+			"Queue is still open",
+			[]*Observation{
+				observedAt("07:00", false, false, 0),
+				observedAt("08:00", true, true, 50),
+			},
+			&DaySummary{
+				Opened:           true,
+				OpenedAt:         observedAt("08:00", true, true, 0).At,
+				TicketsAtOpening: 50,
+			},
+		},
+		{
+			"Queue was active without tickets",
+			[]*Observation{
+				observedAt("08:00", true, false, 0),
+				observedAt("15:00", false, false, 0),
+			},
+			&DaySummary{Finished: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := Summarize(tc.observations)
+
+			// Assert
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Summary mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAverageOf_Always_AveragesDaysTheQueueOpened(t *testing.T) {
+	// Arrange
+	summaries := []*DaySummary{
+		{Opened: true, OpenedAt: observedAt("08:00", true, true, 0).At, TicketsAtOpening: 100, EnabledDuration: time.Hour},
+		{Opened: false},
+		nil,
+		{Opened: true, OpenedAt: observedAt("08:20", true, true, 0).At, TicketsAtOpening: 121, EnabledDuration: 2 * time.Hour},
+	}
+
+	// Act
+	actual := AverageOf(summaries)
+	none := AverageOf(summaries[1:3])
+
+	// Assert
+	expected := &Average{Days: 2, OpenedAt: 8*time.Hour + 10*time.Minute, TicketsAtOpening: 111, EnabledDuration: 90 * time.Minute}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("Average mismatch (-want +got):\n%s", diff)
+	}
+	if none != nil {
+		t.Errorf("Expected no average without openings, got %+v", none)
+	}
+}
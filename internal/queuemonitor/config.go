@@ -1,12 +1,32 @@
 package queuemonitor
 
-import "github.com/UladzK/duw-queue-monitor/internal/notifications"
+import (
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+)
 
 type Config struct {
 	StatusCheckInternalSeconds int    `env:"STATUS_CHECK_INTERVAL_SECONDS" envDefault:"10"`
 	BroadcastChannelName       string `env:"NOTIFICATION_TELEGRAM_BROADCAST_CHANNEL_NAME,required"`
 	QueueMonitor               QueueMonitorConfig
 	NotificationTelegram       notifications.TelegramConfig
+	NotificationLiveMessage    notifications.TelegramLiveMessageConfig
+	NotificationCleanup        notifications.TelegramCleanupConfig
+	NotificationSubscriptions  notifications.TelegramSubscriptionsConfig
+	NotificationTicketTracker  notifications.TicketTrackerConfig
+	NotificationDailySummary   notifications.DailySummaryConfig
+	NotificationSlack          notifications.SlackConfig
+	NotificationWebhook        notifications.WebhookConfig
+	NotificationEmail          notifications.EmailConfig
+	NotificationMqtt           notifications.MqttConfig
+	NotificationNtfy           notifications.NtfyConfig
+	NotificationGotify         notifications.GotifyConfig
+	NotificationWebPush        notifications.WebPushConfig
+	NotificationOutbox         notifications.OutboxConfig
+	NotificationIdempotency    notifications.IdempotencyConfig
+	Templates                  templates.Config
+	History                    queuehistory.Config
 }
 
 type QueueMonitorConfig struct {
@@ -17,6 +37,10 @@ type QueueMonitorConfig struct {
 	StatusCheckMaxAttempts    uint   `env:"STATUS_CHECK_MAX_ATTEMPTS" envDefault:"3"`
 	StatusCheckAttemptDelayMs uint   `env:"STATUS_CHECK_ATTEMPT_DELAY_MS" envDefault:"500"`
 	HttpClientTimeoutSeconds  int    `env:"MONITOR_HTTP_CLIENT_TIMEOUT_SECONDS" envDefault:"5"`
-	RedisConString            string `env:"STATE_REDIS_CONNECTION_STRING,required"`
+	RedisConString            string `env:"STATE_REDIS_CONNECTION_STRING,required" secret:"url"`
 	StateTtlSeconds           int    `env:"STATE_TTL_SECONDS" envDefault:"60"`
+	// comma-separated counts of tickets left, e.g. "10", or percentages of the tickets at opening, e.g. "20%", which trigger
+	// the low tickets alert (tickets_low event) when the number of tickets left drops to them, or when the queue opens below them.
+	// No alerts if empty
+	LowTicketsThresholds []LowTicketsThreshold `env:"LOW_TICKETS_THRESHOLDS" envDefault:"10"`
 }
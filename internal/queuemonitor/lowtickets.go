@@ -0,0 +1,65 @@
+package queuemonitor
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LowTicketsThreshold is a number of tickets left which triggers the low tickets alert, written as a count, e.g. "10",
+// or as a percentage of the tickets at the opening of the queue, e.g. "20%".
+type LowTicketsThreshold struct {
+	Value   int
+	Percent bool
+}
+
+func (t *LowTicketsThreshold) UnmarshalText(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	number, percent := strings.CutSuffix(text, "%")
+
+	value, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil || value < 0 || percent && value > 100 {
+		return fmt.Errorf("invalid low tickets threshold %q, expected a count, e.g. \"10\", or a percentage, e.g. \"20%%\"", data)
+	}
+	*t = LowTicketsThreshold{Value: value, Percent: percent}
+	return nil
+}
+
+func (t LowTicketsThreshold) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t LowTicketsThreshold) String() string {
+	if t.Percent {
+		return strconv.Itoa(t.Value) + "%"
+	}
+	return strconv.Itoa(t.Value)
+}
+
+// Limit returns the number of tickets left at which the threshold is reached. Percentages are unknown without the tickets at opening.
+func (t LowTicketsThreshold) Limit(ticketsAtOpening int) (int, bool) {
+	if !t.Percent {
+		return t.Value, true
+	}
+	if ticketsAtOpening <= 0 {
+		return 0, false
+	}
+	return ticketsAtOpening * t.Value / 100, true
+}
+
+// crossedLowTickets returns the limits of the thresholds reached by the tickets left, which were not alerted yet during
+// the opening, including the ones the queue opened already below.
+func crossedLowTickets(thresholds []LowTicketsThreshold, opening *queueOpening, ticketsLeft int) []int {
+	var crossed []int
+	for _, threshold := range thresholds {
+		limit, ok := threshold.Limit(opening.ticketsLeft)
+		if !ok || ticketsLeft > limit {
+			continue
+		}
+		if !slices.Contains(opening.lowTicketsAlerted, limit) && !slices.Contains(crossed, limit) {
+			crossed = append(crossed, limit)
+		}
+	}
+	return crossed
+}
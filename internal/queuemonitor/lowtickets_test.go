@@ -0,0 +1,62 @@
+package queuemonitor
+
+import (
+	"testing"
+)
+
+func TestLowTicketsThresholdUnmarshalText_Always_ParsesCountsAndPercentages(t *testing.T) {
+	testCases := []struct {
+		text          string
+		expected      LowTicketsThreshold
+		expectedError bool
+	}{
+		{"10", LowTicketsThreshold{Value: 10}, false},
+		{" 20% ", LowTicketsThreshold{Value: 20, Percent: true}, false},
+		{"0", LowTicketsThreshold{Value: 0}, false},
+		{"-5", LowTicketsThreshold{}, true},
+		{"150%", LowTicketsThreshold{}, true},
+		{"few", LowTicketsThreshold{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			// Act
+			var actual LowTicketsThreshold
+			err := actual.UnmarshalText([]byte(tc.text))
+
+			// Assert
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected error: %v, got %v", tc.expectedError, err)
+			}
+			if !tc.expectedError && actual != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLowTicketsThresholdLimit_Always_ResolvesPercentagesOfTicketsAtOpening(t *testing.T) {
+	testCases := []struct {
+		name             string
+		threshold        LowTicketsThreshold
+		ticketsAtOpening int
+		expectedLimit    int
+		expectedOk       bool
+	}{
+		{"Count", LowTicketsThreshold{Value: 10}, 0, 10, true},
+		{"Percentage is rounded down", LowTicketsThreshold{Value: 20, Percent: true}, 57, 11, true},
+		{"Percentage without opening", LowTicketsThreshold{Value: 20, Percent: true}, 0, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			limit, ok := tc.threshold.Limit(tc.ticketsAtOpening)
+
+			// Assert
+			if limit != tc.expectedLimit || ok != tc.expectedOk {
+				t.Errorf("Expected %d, %v, got %d, %v", tc.expectedLimit, tc.expectedOk, limit, ok)
+			}
+		})
+	}
+}
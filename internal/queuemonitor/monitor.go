@@ -3,7 +3,10 @@ package queuemonitor
 import (
 	"context"
 	"fmt"
+	"time"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/queuestatus"
 )
 
 // DefaultQueueMonitor is responsible for collecting queue status and sending notifications about changes in queue availability.
@@ -15,6 +18,22 @@ type DefaultQueueMonitor struct {
 	notifier  Notifier
 	state     QueueState
 	lastQueue *Queue
+
+	outbox          *outboxNotifier // set when notifications are delivered through the outbox
+	transitionStore TransitionStore
+
+	statusStore queuestatus.Store // optional, see PublishStatus
+	lastStatus  *queuestatus.Snapshot
+
+	ticketObserver TicketObserver // optional, see ObserveTickets
+
+	history      queuehistory.Store // optional, see RecordHistory
+	lastObserved *queuehistory.Observation
+}
+
+// TicketObserver is told the last called ticket on every check, e.g. to notify the users waiting for their turn.
+type TicketObserver interface {
+	ObserveTicket(ctx context.Context, ticketValue string, checkedAt time.Time)
 }
 
 func NewQueueMonitor(cfg *Config, log *logger.Logger, collector *StatusCollector, notifier Notifier) *DefaultQueueMonitor {
@@ -24,16 +43,41 @@ func NewQueueMonitor(cfg *Config, log *logger.Logger, collector *StatusCollector
 		collector: collector,
 		notifier:  notifier,
 	}
-	m.state = &UninitializedState{notifier: notifier, channelName: cfg.BroadcastChannelName}
+	m.state = &UninitializedState{notifier: notifier, channelName: cfg.BroadcastChannelName, lowTickets: cfg.QueueMonitor.LowTicketsThresholds}
 	return m
 }
 
+// NewOutboxQueueMonitor creates a monitor which doesn't send notifications by itself: they are saved to the outbox
+// together with the new state, and delivered by notifications.OutboxWorker.
+func NewOutboxQueueMonitor(cfg *Config, log *logger.Logger, collector *StatusCollector, transitionStore TransitionStore) *DefaultQueueMonitor {
+	outbox := &outboxNotifier{}
+	m := NewQueueMonitor(cfg, log, collector, outbox)
+	m.outbox = outbox
+	m.transitionStore = transitionStore
+	return m
+}
+
+// PublishStatus makes the monitor save the queue status observed by every check to the store, e.g. for the /status command of the bot.
+func (h *DefaultQueueMonitor) PublishStatus(store queuestatus.Store) {
+	h.statusStore = store
+}
+
+// ObserveTickets makes the monitor pass the last called ticket of every check to the observer.
+func (h *DefaultQueueMonitor) ObserveTickets(observer TicketObserver) {
+	h.ticketObserver = observer
+}
+
+// RecordHistory makes the monitor save every change of the queue status to the history, e.g. for the daily summary.
+func (h *DefaultQueueMonitor) RecordHistory(store queuehistory.Store) {
+	h.history = store
+}
+
 func (h *DefaultQueueMonitor) Init(initState *MonitorState) {
 	if initState == nil {
 		panic("QueueMonitor.Init called with nil state. This should not happen")
 	}
 
-	h.state = StateFromPersistence(initState, h.notifier, h.cfg.BroadcastChannelName)
+	h.state = StateFromPersistence(initState, h.notifier, h.cfg.BroadcastChannelName, h.cfg.QueueMonitor.LowTicketsThresholds)
 	h.log.Info("QueueMonitor initialized with state:", "stateName", h.state.Name(), "initState", initState)
 }
 
@@ -46,6 +90,11 @@ func (h *DefaultQueueMonitor) CheckAndProcessStatus(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("error getting queue status: %w", err)
 	}
+	h.publishStatus(ctx, queue)
+	h.recordHistory(ctx, queue)
+	if h.ticketObserver != nil {
+		h.ticketObserver.ObserveTicket(ctx, queue.TicketValue, time.Now().UTC())
+	}
 
 	prevStateName := h.state.Name()
 	newState, err := h.state.Handle(ctx, queue)
@@ -53,6 +102,15 @@ func (h *DefaultQueueMonitor) CheckAndProcessStatus(ctx context.Context) error {
 		return err
 	}
 
+	if h.outbox != nil {
+		if msgs := h.outbox.drain(); len(msgs) > 0 {
+			// if saving fails, the monitor stays in the previous state and the transition is repeated on the next check
+			if err := h.transitionStore.SaveTransition(ctx, StateToPersistence(newState, queue), msgs); err != nil {
+				return err
+			}
+		}
+	}
+
 	if newState.Name() != prevStateName {
 		h.log.Info("State transition", "from", prevStateName, "to", newState.Name())
 	}
@@ -63,3 +121,82 @@ func (h *DefaultQueueMonitor) CheckAndProcessStatus(ctx context.Context) error {
 
 	return nil
 }
+
+// publishStatus saves the observed queue status. The update time is kept until the status changes, also across restarts.
+// Failures are only logged: the status is informational and must not block the notifications.
+func (h *DefaultQueueMonitor) publishStatus(ctx context.Context, queue *Queue) {
+	if h.statusStore == nil {
+		return
+	}
+
+	if h.lastStatus == nil {
+		last, err := h.statusStore.Get(ctx)
+		if err != nil {
+			h.log.Error("Failed to get the last published queue status", err)
+		}
+		h.lastStatus = last
+	}
+
+	now := time.Now().UTC()
+	status := &queuestatus.Snapshot{
+		QueueID:     queue.ID,
+		QueueName:   queue.Name,
+		Active:      queue.Active,
+		Enabled:     queue.Enabled,
+		TicketValue: queue.TicketValue,
+		TicketsLeft: queue.TicketsLeft,
+		CheckedAt:   now,
+		UpdatedAt:   now,
+	}
+	if status.SameStatus(h.lastStatus) {
+		status.UpdatedAt = h.lastStatus.UpdatedAt
+	}
+
+	if err := h.statusStore.Save(ctx, status); err != nil {
+		h.log.Error("Failed to publish queue status", err)
+		return
+	}
+	h.lastStatus = status
+}
+
+// recordHistory appends the observed queue status to the history if it changed, also across restarts.
+// Failures are only logged: the history is informational and must not block the notifications.
+func (h *DefaultQueueMonitor) recordHistory(ctx context.Context, queue *Queue) {
+	if h.history == nil {
+		return
+	}
+
+	obs := &queuehistory.Observation{
+		At:          time.Now().UTC(),
+		QueueName:   queue.Name,
+		Active:      queue.Active,
+		Enabled:     queue.Enabled,
+		TicketValue: queue.TicketValue,
+		TicketsLeft: queue.TicketsLeft,
+	}
+	if h.lastObserved == nil || !sameDay(h.lastObserved.At, obs.At, h.history.Location()) {
+		observations, err := h.history.Day(ctx, queue.ID, obs.At)
+		if err != nil {
+			h.log.Error("Failed to get the queue history", err)
+		}
+		h.lastObserved = nil
+		if len(observations) > 0 {
+			h.lastObserved = observations[len(observations)-1]
+		}
+	}
+	if obs.SameStatus(h.lastObserved) {
+		return
+	}
+
+	if err := h.history.Append(ctx, queue.ID, obs); err != nil {
+		h.log.Error("Failed to record queue history", err)
+		return
+	}
+	h.lastObserved = obs
+}
+
+// sameDay reports whether the times fall on the same day in the location: the history is kept by local day.
+func sameDay(a, b time.Time, location *time.Location) bool {
+	a, b = a.In(location), b.In(location)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
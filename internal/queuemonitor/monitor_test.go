@@ -6,9 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/queuestatus"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 type mockNotifier struct {
@@ -143,7 +148,8 @@ func TestCheckAndProcessStatus_WhenStateIsNotInitialized_CorrectlyHandlesStateTr
 				t.Errorf("Expected notification sending: %v, but it was: %v", tc.notificationShouldBeSent, notifier.sendMessageCalled)
 			}
 
-			if stateDiff := cmp.Diff(sut.GetState(), expectedFinalState); stateDiff != "" {
+			// the opening is covered by TestCheckAndProcessStatus_WhenQueueOpens_TracksOpeningForBurnRate
+			if stateDiff := cmp.Diff(sut.GetState(), expectedFinalState, cmpopts.IgnoreFields(MonitorState{}, "OpenedAt", "TicketsAtOpening", "Since")); stateDiff != "" {
 				t.Errorf("State mismatch between currently set state of monitor and latest state (-want +got):\n%s", stateDiff)
 			}
 		})
@@ -280,7 +286,7 @@ func TestCheckAndProcessStatus_WhenStateIsInitialized_CorrectlyHandlesStrateTran
 				t.Errorf("Expected notification sending: %v, but it was: %v", tc.notificationShouldBeSent, notifier.sendMessageCalled)
 			}
 
-			if diffState := cmp.Diff(sut.GetState(), expectedFinalState); diffState != "" {
+			if diffState := cmp.Diff(sut.GetState(), expectedFinalState, cmpopts.IgnoreFields(MonitorState{}, "Since")); diffState != "" {
 				t.Errorf("State mismatch between currently set state of monitor and latest state (-want +got):\n%s", diffState)
 			}
 		})
@@ -456,7 +462,29 @@ func TestCheckAndProcessStatus_MessageFormat_CorrectlyFormatsMessages(t *testing
 			"test-queue",
 			"K80",
 			10,
-			"🔔 Kolejka <b>test-queue</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>K80</b>\n🧾 Pozostało biletów: <b>10</b>",
+			"🔔 Kolejka <b>test-queue</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>K80</b>\n🧾 Pozostało <b>10</b> biletów",
+			"@test-channel",
+			nil,
+		},
+		{
+			"Available queue with few tickets",
+			true,
+			true,
+			"test-queue",
+			"K80",
+			22,
+			"🔔 Kolejka <b>test-queue</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>K80</b>\n🧾 Pozostały <b>22</b> bilety",
+			"@test-channel",
+			nil,
+		},
+		{
+			"Available queue with one ticket",
+			true,
+			true,
+			"test-queue",
+			"",
+			1,
+			"🔔 Kolejka <b>test-queue</b> jest teraz dostępna!\n🧾 Pozostał <b>1</b> bilet",
 			"@test-channel",
 			nil,
 		},
@@ -478,7 +506,18 @@ func TestCheckAndProcessStatus_MessageFormat_CorrectlyFormatsMessages(t *testing
 			"Odbiór karty",
 			"",
 			5,
-			"🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!\n🧾 Pozostało biletów: <b>5</b>",
+			"🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!\n🧾 Pozostało <b>5</b> biletów",
+			"@test-channel",
+			nil,
+		},
+		{
+			"Queue name and ticket with HTML characters",
+			true,
+			true,
+			"Karty <pobytu> & wizy",
+			"K<80>",
+			7,
+			"🔔 Kolejka <b>Karty &lt;pobytu&gt; &amp; wizy</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>K&lt;80&gt;</b>\n🧾 Pozostało <b>7</b> biletów",
 			"@test-channel",
 			nil,
 		},
@@ -559,3 +598,563 @@ func TestCheckAndProcessStatus_MessageFormat_CorrectlyFormatsMessages(t *testing
 		})
 	}
 }
+
+type mockEventNotifier struct {
+	mockNotifier
+	lastEvent *notifications.QueueEvent
+}
+
+func (f *mockEventNotifier) Notify(ctx context.Context, event *notifications.QueueEvent) error {
+	f.lastEvent = event
+	return nil
+}
+
+func TestCheckAndProcessStatus_WhenNotifierSupportsEvents_SendsEventWithTransitionType(t *testing.T) {
+	// Arrange
+	testConditions := []struct {
+		name         string
+		initialState *MonitorState
+		newState     Queue
+		expectedType notifications.EventType
+	}{
+		{
+			"Inactive queue becomes enabled",
+			&MonitorState{StateName: "Inactive"},
+			Queue{Active: true, Enabled: true, TicketValue: "K1", TicketsLeft: 10},
+			notifications.EventQueueOpened,
+		},
+		{
+			"Inactive queue becomes active but disabled",
+			&MonitorState{StateName: "Inactive"},
+			Queue{Active: true, Enabled: false},
+			notifications.EventQueueUnavailable,
+		},
+		{
+			"Disabled queue becomes enabled",
+			&MonitorState{StateName: "ActiveDisabled", QueueActive: true},
+			Queue{Active: true, Enabled: true, TicketsLeft: 5},
+			notifications.EventQueueOpened,
+		},
+		{
+			"Enabled queue tickets changed",
+			&MonitorState{StateName: "ActiveEnabled", QueueActive: true, QueueEnabled: true, TicketsLeft: 10},
+			Queue{Active: true, Enabled: true, TicketsLeft: 9},
+			notifications.EventTicketsChanged,
+		},
+		{
+			"Enabled queue becomes disabled",
+			&MonitorState{StateName: "ActiveEnabled", QueueActive: true, QueueEnabled: true, TicketsLeft: 10},
+			Queue{Active: true, Enabled: false},
+			notifications.EventQueueUnavailable,
+		},
+		{
+			"Enabled queue becomes inactive",
+			&MonitorState{StateName: "ActiveEnabled", QueueActive: true, QueueEnabled: true, TicketsLeft: 10},
+			Queue{Active: false, Enabled: false},
+			notifications.EventQueueInactive,
+		},
+	}
+
+	for _, tc := range testConditions {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "%v", "tickets_left": %v, "active": %v, "enabled": %v}]}}`,
+					tc.newState.TicketValue, tc.newState.TicketsLeft, tc.newState.Active, tc.newState.Enabled)
+			}))
+			defer mockDuwApi.Close()
+
+			cfg := &Config{
+				BroadcastChannelName: "test-channel",
+				QueueMonitor: QueueMonitorConfig{
+					StatusApiUrl:              mockDuwApi.URL,
+					StatusCheckTimeoutMs:      4000,
+					StatusCheckMaxAttempts:    3,
+					StatusCheckAttemptDelayMs: 500,
+					StatusMonitoredQueueId:    24,
+					StatusMonitoredQueueCity:  "Wrocław",
+				},
+			}
+
+			logger := logger.NewLogger(&logger.Config{Level: "error"})
+			collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+			notifier := &mockEventNotifier{}
+			sut := NewQueueMonitor(cfg, logger, collector, notifier)
+			sut.Init(tc.initialState)
+
+			// Act
+			err := sut.CheckAndProcessStatus(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+			}
+
+			if notifier.lastEvent == nil {
+				t.Fatal("Expected Notify to be called, but it wasn't")
+			}
+
+			if notifier.sendMessageCalled {
+				t.Error("Expected SendMessage not to be called for event notifier, but it was")
+			}
+
+			if notifier.lastEvent.Type != tc.expectedType {
+				t.Errorf("Expected event type '%s', but got '%s'", tc.expectedType, notifier.lastEvent.Type)
+			}
+
+			if notifier.lastEvent.ChatID != "@test-channel" || notifier.lastEvent.QueueID != 24 || notifier.lastEvent.TicketsLeft != tc.newState.TicketsLeft {
+				t.Errorf("Expected event to carry chat ID and queue data, but got %+v", notifier.lastEvent)
+			}
+		})
+	}
+}
+
+type mockTransitionStore struct {
+	shouldFail    bool
+	savedState    *MonitorState
+	savedMessages []*notifications.OutboxMessage
+}
+
+func (s *mockTransitionStore) SaveTransition(ctx context.Context, state *MonitorState, msgs []*notifications.OutboxMessage) error {
+	if s.shouldFail {
+		return fmt.Errorf("failed to save transition")
+	}
+	s.savedState = state
+	s.savedMessages = msgs
+	return nil
+}
+
+func newOutboxTestMonitor(t *testing.T, store TransitionStore) *DefaultQueueMonitor {
+	mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "K1", "tickets_left": 10, "active": true, "enabled": true}]}}`)
+	}))
+	t.Cleanup(mockDuwApi.Close)
+
+	cfg := &Config{
+		BroadcastChannelName: "test-channel",
+		QueueMonitor: QueueMonitorConfig{
+			StatusApiUrl:              mockDuwApi.URL,
+			StatusCheckTimeoutMs:      4000,
+			StatusCheckMaxAttempts:    1,
+			StatusCheckAttemptDelayMs: 500,
+			StatusMonitoredQueueId:    24,
+			StatusMonitoredQueueCity:  "Wrocław",
+		},
+	}
+
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+	sut := NewOutboxQueueMonitor(cfg, logger, collector, store)
+	sut.Init(&MonitorState{StateName: "Inactive"})
+	return sut
+}
+
+func TestCheckAndProcessStatus_WhenOutboxIsUsed_SavesNewStateTogetherWithNotification(t *testing.T) {
+	// Arrange
+	store := &mockTransitionStore{}
+	sut := newOutboxTestMonitor(t, store)
+
+	// Act
+	err := sut.CheckAndProcessStatus(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+	}
+
+	if store.savedState == nil || store.savedState.StateName != "ActiveEnabled" || store.savedState.TicketsLeft != 10 {
+		t.Errorf("Expected ActiveEnabled state to be saved, but got %+v", store.savedState)
+	}
+
+	if len(store.savedMessages) != 1 {
+		t.Fatalf("Expected one notification to be saved, but got %d", len(store.savedMessages))
+	}
+	msg := store.savedMessages[0]
+	if msg.ChatID != "@test-channel" || msg.Event == nil || msg.Event.Type != notifications.EventQueueOpened || msg.ID == "" {
+		t.Errorf("Expected queue opened notification for the channel, but got %+v", msg)
+	}
+
+	if sut.GetState().StateName != "ActiveEnabled" {
+		t.Errorf("Expected monitor to move to ActiveEnabled, but it's in %s", sut.GetState().StateName)
+	}
+}
+
+func TestCheckAndProcessStatus_WhenSavingTransitionFails_StaysInPreviousStateAndRetriesOnNextCheck(t *testing.T) {
+	// Arrange
+	store := &mockTransitionStore{shouldFail: true}
+	sut := newOutboxTestMonitor(t, store)
+
+	// Act
+	firstErr := sut.CheckAndProcessStatus(context.Background())
+	store.shouldFail = false
+	secondErr := sut.CheckAndProcessStatus(context.Background())
+
+	// Assert
+	if firstErr == nil {
+		t.Error("Expected error when the transition can't be saved, but got nil")
+	}
+	if secondErr != nil {
+		t.Fatalf("Expected the transition to be saved on the next check, but got error: %v", secondErr)
+	}
+
+	if len(store.savedMessages) != 1 {
+		t.Errorf("Expected exactly one notification from the repeated transition, but got %d", len(store.savedMessages))
+	}
+}
+
+func TestCheckAndProcessStatus_WhenQueueOpens_TracksOpeningForBurnRate(t *testing.T) {
+	// Arrange
+	ticketsLeft := 20
+	mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "K1", "tickets_left": %v, "active": true, "enabled": true}]}}`, ticketsLeft)
+	}))
+	defer mockDuwApi.Close()
+
+	cfg := &Config{
+		BroadcastChannelName: "test-channel",
+		QueueMonitor: QueueMonitorConfig{
+			StatusApiUrl:              mockDuwApi.URL,
+			StatusCheckTimeoutMs:      4000,
+			StatusCheckMaxAttempts:    3,
+			StatusCheckAttemptDelayMs: 500,
+			StatusMonitoredQueueId:    24,
+			StatusMonitoredQueueCity:  "Wrocław",
+		},
+	}
+
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+	notifier := &mockEventNotifier{}
+	sut := NewQueueMonitor(cfg, logger, collector, notifier)
+	sut.Init(&MonitorState{StateName: "Inactive"})
+
+	// Act
+	if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+		t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+	}
+	opened := sut.GetState()
+
+	// restart the monitor from the persisted state: the opening must survive it
+	sut = NewQueueMonitor(cfg, logger, collector, notifier)
+	sut.Init(opened)
+	ticketsLeft = 15
+	if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+		t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+	}
+
+	// Assert
+	if opened.OpenedAt.IsZero() || opened.TicketsAtOpening != 20 {
+		t.Errorf("Expected the opening to be persisted, got opened at %v with %d tickets", opened.OpenedAt, opened.TicketsAtOpening)
+	}
+
+	event := notifier.lastEvent
+	if event.Type != notifications.EventTicketsChanged || event.State != "ActiveEnabled" {
+		t.Errorf("Expected tickets changed event in ActiveEnabled state, got %s in %s", event.Type, event.State)
+	}
+	if !event.OpenedAt.Equal(opened.OpenedAt) || event.TicketsAtOpening != 20 {
+		t.Errorf("Expected the event to carry the opening, got opened at %v with %d tickets", event.OpenedAt, event.TicketsAtOpening)
+	}
+}
+
+type mockStatusStore struct {
+	saved *queuestatus.Snapshot
+}
+
+func (s *mockStatusStore) Get(ctx context.Context) (*queuestatus.Snapshot, error) {
+	return s.saved, nil
+}
+
+func (s *mockStatusStore) Save(ctx context.Context, status *queuestatus.Snapshot) error {
+	s.saved = status
+	return nil
+}
+
+func TestCheckAndProcessStatus_WhenStatusIsPublished_KeepsUpdateTimeUntilStatusChanges(t *testing.T) {
+	// Arrange
+	ticketsLeft := 20
+	mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "K1", "tickets_left": %v, "active": true, "enabled": true}]}}`, ticketsLeft)
+	}))
+	defer mockDuwApi.Close()
+
+	cfg := &Config{
+		BroadcastChannelName: "test-channel",
+		QueueMonitor: QueueMonitorConfig{
+			StatusApiUrl:              mockDuwApi.URL,
+			StatusCheckTimeoutMs:      4000,
+			StatusCheckMaxAttempts:    3,
+			StatusCheckAttemptDelayMs: 500,
+			StatusMonitoredQueueId:    24,
+			StatusMonitoredQueueCity:  "Wrocław",
+		},
+	}
+
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+	updatedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	store := &mockStatusStore{saved: &queuestatus.Snapshot{
+		QueueID: 24, QueueName: "test-queue", Active: true, Enabled: true, TicketValue: "K1", TicketsLeft: 20, UpdatedAt: updatedAt,
+	}}
+	sut := NewQueueMonitor(cfg, logger, collector, &mockEventNotifier{})
+	sut.PublishStatus(store)
+	sut.Init(&MonitorState{StateName: "ActiveEnabled", TicketsLeft: 20})
+
+	// Act
+	if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+		t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+	}
+	unchanged := *store.saved
+	ticketsLeft = 15
+	if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+		t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+	}
+	changed := *store.saved
+
+	// Assert
+	if !unchanged.UpdatedAt.Equal(updatedAt) || !unchanged.CheckedAt.After(updatedAt) {
+		t.Errorf("Expected only the check time to move for the same status, got checked at %v, updated at %v", unchanged.CheckedAt, unchanged.UpdatedAt)
+	}
+	if changed.TicketsLeft != 15 || !changed.UpdatedAt.Equal(changed.CheckedAt) {
+		t.Errorf("Expected the update time to move with the status, got %+v", changed)
+	}
+}
+
+type mockTicketObserver struct {
+	observed []string
+}
+
+func (o *mockTicketObserver) ObserveTicket(ctx context.Context, ticketValue string, checkedAt time.Time) {
+	o.observed = append(o.observed, ticketValue)
+}
+
+func TestCheckAndProcessStatus_WhenTicketsAreObserved_PassesCalledTicketOfEveryCheck(t *testing.T) {
+	// Arrange
+	ticketValue := "K1"
+	mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "%s", "tickets_left": 10, "active": true, "enabled": true}]}}`, ticketValue)
+	}))
+	defer mockDuwApi.Close()
+
+	cfg := &Config{
+		BroadcastChannelName: "test-channel",
+		QueueMonitor: QueueMonitorConfig{
+			StatusApiUrl:              mockDuwApi.URL,
+			StatusCheckTimeoutMs:      4000,
+			StatusCheckMaxAttempts:    3,
+			StatusCheckAttemptDelayMs: 500,
+			StatusMonitoredQueueId:    24,
+			StatusMonitoredQueueCity:  "Wrocław",
+		},
+	}
+
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+	observer := &mockTicketObserver{}
+	sut := NewQueueMonitor(cfg, logger, collector, &mockEventNotifier{})
+	sut.ObserveTickets(observer)
+	sut.Init(&MonitorState{StateName: "ActiveEnabled", TicketsLeft: 10})
+
+	// Act
+	for _, value := range []string{"K1", "K2"} {
+		ticketValue = value
+		if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+			t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+		}
+	}
+
+	// Assert
+	if diff := cmp.Diff([]string{"K1", "K2"}, observer.observed); diff != "" {
+		t.Errorf("Observed tickets mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckAndProcessStatus_WhenTicketsDropToThresholds_AlertsEachThresholdOncePerOpening(t *testing.T) {
+	// Arrange
+	ticketsLeft, enabled := 20, true
+	mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "K1", "tickets_left": %v, "active": true, "enabled": %v}]}}`, ticketsLeft, enabled)
+	}))
+	defer mockDuwApi.Close()
+
+	cfg := &Config{
+		BroadcastChannelName: "test-channel",
+		QueueMonitor: QueueMonitorConfig{
+			StatusApiUrl:              mockDuwApi.URL,
+			StatusCheckTimeoutMs:      4000,
+			StatusCheckMaxAttempts:    3,
+			StatusCheckAttemptDelayMs: 500,
+			StatusMonitoredQueueId:    24,
+			StatusMonitoredQueueCity:  "Wrocław",
+			LowTicketsThresholds:      []LowTicketsThreshold{{Value: 10}, {Value: 50, Percent: true}},
+		},
+	}
+
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+	notifier := &mockEventNotifier{}
+	sut := NewQueueMonitor(cfg, logger, collector, notifier)
+	sut.Init(&MonitorState{StateName: "ActiveEnabled", TicketsLeft: 20, OpenedAt: time.Now().UTC().Add(-time.Hour), TicketsAtOpening: 30})
+
+	checks := []struct {
+		ticketsLeft int
+		enabled     bool
+		restart     bool
+	}{
+		{16, true, false},
+		{15, true, false}, // 50% of 30
+		{12, true, false},
+		{10, true, true},
+		{12, true, false},
+		{9, true, false}, // 10 was alerted already
+		{0, false, false},
+		{30, true, false},
+		{10, true, false}, // both thresholds of the new opening at once
+	}
+
+	// Act
+	var events []notifications.EventType
+	for _, check := range checks {
+		if check.restart {
+			state := sut.GetState()
+			sut = NewQueueMonitor(cfg, logger, collector, notifier)
+			sut.Init(state)
+		}
+
+		ticketsLeft, enabled = check.ticketsLeft, check.enabled
+		if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+			t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+		}
+		events = append(events, notifier.lastEvent.Type)
+	}
+
+	// Assert
+	expected := []notifications.EventType{
+		notifications.EventTicketsChanged,
+		notifications.EventTicketsLow,
+		notifications.EventTicketsChanged,
+		notifications.EventTicketsLow,
+		notifications.EventTicketsChanged,
+		notifications.EventTicketsChanged,
+		notifications.EventQueueUnavailable,
+		notifications.EventQueueOpened,
+		notifications.EventTicketsLow,
+	}
+	if diff := cmp.Diff(expected, events); diff != "" {
+		t.Errorf("Events mismatch (-want +got):\n%s", diff)
+	}
+	if alerted := sut.GetState().LowTicketsAlerted; !cmp.Equal(alerted, []int{10, 15}) {
+		t.Errorf("Expected both thresholds to be alerted, got %v", alerted)
+	}
+}
+
+func TestCheckAndProcessStatus_WhenQueueOpensBelowThreshold_AlertsOnNextCheck(t *testing.T) {
+	// Arrange
+	mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "K1", "tickets_left": 5, "active": true, "enabled": true}]}}`)
+	}))
+	defer mockDuwApi.Close()
+
+	cfg := &Config{
+		BroadcastChannelName: "test-channel",
+		QueueMonitor: QueueMonitorConfig{
+			StatusApiUrl:              mockDuwApi.URL,
+			StatusCheckTimeoutMs:      4000,
+			StatusCheckMaxAttempts:    3,
+			StatusCheckAttemptDelayMs: 500,
+			StatusMonitoredQueueId:    24,
+			StatusMonitoredQueueCity:  "Wrocław",
+			LowTicketsThresholds:      []LowTicketsThreshold{{Value: 10}},
+		},
+	}
+
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+	notifier := &mockEventNotifier{}
+	sut := NewQueueMonitor(cfg, logger, collector, notifier)
+	sut.Init(&MonitorState{StateName: "Inactive"})
+
+	// Act
+	var events []notifications.EventType
+	for range 3 {
+		notifier.lastEvent = nil
+		if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+			t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+		}
+		if notifier.lastEvent != nil {
+			events = append(events, notifier.lastEvent.Type)
+		}
+	}
+
+	// Assert
+	expected := []notifications.EventType{notifications.EventQueueOpened, notifications.EventTicketsLow}
+	if diff := cmp.Diff(expected, events); diff != "" {
+		t.Errorf("Events mismatch (-want +got):\n%s", diff)
+	}
+	if alerted := sut.GetState().LowTicketsAlerted; !cmp.Equal(alerted, []int{10}) {
+		t.Errorf("Expected the threshold to be alerted, got %v", alerted)
+	}
+}
+
+type mockHistoryStore struct {
+	appended []*queuehistory.Observation
+}
+
+func (s *mockHistoryStore) Append(ctx context.Context, queueID int, obs *queuehistory.Observation) error {
+	s.appended = append(s.appended, obs)
+	return nil
+}
+
+func (s *mockHistoryStore) Day(ctx context.Context, queueID int, day time.Time) ([]*queuehistory.Observation, error) {
+	return s.appended, nil
+}
+
+func (s *mockHistoryStore) Location() *time.Location {
+	return time.UTC
+}
+
+func TestCheckAndProcessStatus_WhenHistoryIsRecorded_AppendsOnlyStatusChanges(t *testing.T) {
+	// Arrange
+	ticketsLeft := 10
+	mockDuwApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result": {"Wrocław": [{"id": 24, "name": "test-queue", "ticket_value": "K1", "tickets_left": %v, "active": true, "enabled": true}]}}`, ticketsLeft)
+	}))
+	defer mockDuwApi.Close()
+
+	cfg := &Config{
+		BroadcastChannelName: "test-channel",
+		QueueMonitor: QueueMonitorConfig{
+			StatusApiUrl:              mockDuwApi.URL,
+			StatusCheckTimeoutMs:      4000,
+			StatusCheckMaxAttempts:    3,
+			StatusCheckAttemptDelayMs: 500,
+			StatusMonitoredQueueId:    24,
+			StatusMonitoredQueueCity:  "Wrocław",
+		},
+	}
+
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	collector := NewStatusCollector(&cfg.QueueMonitor, &http.Client{}, logger)
+	// the monitor was restarted after the status was recorded
+	store := &mockHistoryStore{appended: []*queuehistory.Observation{
+		{At: time.Now().UTC(), QueueName: "test-queue", Active: true, Enabled: true, TicketValue: "K1", TicketsLeft: 10},
+	}}
+	sut := NewQueueMonitor(cfg, logger, collector, &mockEventNotifier{})
+	sut.RecordHistory(store)
+	sut.Init(&MonitorState{StateName: "ActiveEnabled", TicketsLeft: 10})
+
+	// Act
+	for _, tickets := range []int{10, 9, 9, 8} {
+		ticketsLeft = tickets
+		if err := sut.CheckAndProcessStatus(context.Background()); err != nil {
+			t.Fatalf("Expected successful execution, but execution returned error: %v", err)
+		}
+	}
+
+	// Assert
+	var recorded []int
+	for _, obs := range store.appended {
+		recorded = append(recorded, obs.TicketsLeft)
+	}
+	if diff := cmp.Diff([]int{10, 9, 8}, recorded); diff != "" {
+		t.Errorf("Recorded tickets mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -15,6 +15,11 @@ type MonitorState struct {
 	QueueEnabled        bool   `json:"queue_enabled"`         // indicates if the queue is enabled
 	LastTicketProcessed string `json:"last_ticket_processed"` // last ticket processed in the queue
 	TicketsLeft         int    `json:"tickets_left"`          // number of tickets left in the queue
+
+	OpenedAt          time.Time `json:"opened_at,omitzero"`            // when the queue started to accept tickets (ActiveEnabled only)
+	TicketsAtOpening  int       `json:"tickets_at_opening,omitempty"`  // number of tickets left when the queue opened (ActiveEnabled only)
+	LowTicketsAlerted []int     `json:"low_tickets_alerted,omitempty"` // limits of the low tickets thresholds alerted since the queue opened (ActiveEnabled only)
+	Since             time.Time `json:"since,omitzero"`                // when the monitor entered the state (Inactive and ActiveDisabled only)
 }
 
 // MonitorStateRepository is responsible for storing and retrieving the queue monitor state in Redis.
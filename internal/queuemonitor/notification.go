@@ -3,14 +3,10 @@ package queuemonitor
 import (
 	"context"
 	"fmt"
-)
+	"time"
 
-// Message constants for queue status notifications
-const (
-	msgQueueAvailableGeneral = "🔔 Kolejka <b>%s</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>%s</b>\n🧾 Pozostało biletów: <b>%d</b>"
-	msgQueueAvailableShort   = "🔔 Kolejka <b>%s</b> jest teraz dostępna!\n🧾 Pozostało biletów: <b>%d</b>"
-	msgQueueUnavailable      = "💤 Kolejka <b>%s</b> jest obecnie niedostępna."
-	msgQueueInactive         = "🌙 Kolejka <b>%s</b> jest nieaktywna — prawdopodobnie koniec godzin pracy DUW."
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
 )
 
 // Notifier defines the interface for sending notifications about queue status updates.
@@ -19,33 +15,67 @@ type Notifier interface {
 	SendMessage(ctx context.Context, chatID, text string) error
 }
 
-// buildQueueAvailableMsg creates a formatted message based on queue status
-func buildQueueAvailableMsg(queueName string, queueEnabled bool, actualTicket string, numberOfTicketsLeft int) string {
-	if !queueEnabled {
-		return fmt.Sprintf(msgQueueUnavailable, queueName)
-	}
+// sendNotification sends a notification about the queue status during state transitions.
+// Notifiers implementing notifications.EventNotifier receive the structured event, the rest receive the formatted message only.
+// The channel is in the default language. The opening is nil if it's not known when the queue started to accept tickets.
+// The previous state is the state the monitor leaves with the event, or stays in for the ticket changes.
+func sendNotification(ctx context.Context, notifier Notifier, channelName string, previous QueueState, queue *Queue, eventType notifications.EventType, opening *queueOpening) error {
+	event := buildQueueEvent(eventType, fmt.Sprintf("@%s", channelName), previous, queue, opening)
 
-	if actualTicket == "" {
-		return fmt.Sprintf(msgQueueAvailableShort, queueName, numberOfTicketsLeft)
+	var err error
+	if eventNotifier, ok := notifier.(notifications.EventNotifier); ok {
+		err = eventNotifier.Notify(ctx, event)
+	} else {
+		err = notifier.SendMessage(ctx, event.ChatID, event.Text)
 	}
-	return fmt.Sprintf(msgQueueAvailableGeneral, queueName, actualTicket, numberOfTicketsLeft)
+	if err != nil {
+		return fmt.Errorf("error sending queue notification: %w", err)
+	}
+	return nil
 }
 
-func buildQueueInactiveMsg(queueName string) string {
-	return fmt.Sprintf(msgQueueInactive, queueName)
+func buildQueueEvent(eventType notifications.EventType, chatID string, previous QueueState, queue *Queue, opening *queueOpening) *notifications.QueueEvent {
+	event := &notifications.QueueEvent{
+		Type:        eventType,
+		ChatID:      chatID,
+		Language:    i18n.DefaultLanguage,
+		QueueID:     queue.ID,
+		QueueName:   queue.Name,
+		Active:      queue.Active,
+		Enabled:     queue.Enabled,
+		TicketValue: queue.TicketValue,
+		TicketsLeft: queue.TicketsLeft,
+		OccurredAt:  time.Now().UTC(),
+		State:       stateNameAfter(eventType),
+
+		PreviousState:      previous.Name(),
+		PreviousStateSince: stateSince(previous),
+	}
+	if opening != nil {
+		event.OpenedAt = opening.openedAt
+		event.TicketsAtOpening = opening.ticketsLeft
+	}
+	event.Text = notifications.RenderQueueMessage(event)
+	event.IdempotencyKey = notifications.NewIdempotencyKey(event)
+	return event
 }
 
-// sendNotification sends a notification about the queue status during state transitions.
-func sendNotification(ctx context.Context, notifier Notifier, channelName string, queue *Queue, isInactive bool) error {
-	chatID := fmt.Sprintf("@%s", channelName)
-	var message string
-	if isInactive {
-		message = buildQueueInactiveMsg(queue.Name)
-	} else {
-		message = buildQueueAvailableMsg(queue.Name, queue.Enabled, queue.TicketValue, queue.TicketsLeft)
+// becameActiveEvent returns the event type for the transition from a state where the queue did not accept tickets.
+func becameActiveEvent(queue *Queue) notifications.EventType {
+	if queue.Enabled {
+		return notifications.EventQueueOpened
 	}
-	if err := notifier.SendMessage(ctx, chatID, message); err != nil {
-		return fmt.Errorf("error sending queue notification: %w", err)
+	return notifications.EventQueueUnavailable
+}
+
+// stateNameAfter returns the name of the state the monitor moves to with the event.
+func stateNameAfter(eventType notifications.EventType) string {
+	switch eventType {
+	case notifications.EventQueueOpened, notifications.EventTicketsChanged, notifications.EventTicketsLow:
+		return "ActiveEnabled"
+	case notifications.EventQueueUnavailable:
+		return "ActiveDisabled"
+	default:
+		return "Inactive"
 	}
-	return nil
 }
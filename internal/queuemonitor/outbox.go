@@ -0,0 +1,76 @@
+package queuemonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TransitionStore durably stores the monitor state after a transition together with the notifications produced by it.
+type TransitionStore interface {
+	SaveTransition(ctx context.Context, state *MonitorState, msgs []*notifications.OutboxMessage) error
+}
+
+// OutboxTransitionStore saves the state and enqueues the notifications in a single Redis transaction,
+// so that a notification can't be lost after the state has moved on, and it can't be sent twice after a restart.
+type OutboxTransitionStore struct {
+	stateRepo *MonitorStateRepository
+	outbox    *notifications.RedisOutbox
+}
+
+func NewOutboxTransitionStore(stateRepo *MonitorStateRepository, outbox *notifications.RedisOutbox) *OutboxTransitionStore {
+	return &OutboxTransitionStore{
+		stateRepo: stateRepo,
+		outbox:    outbox,
+	}
+}
+
+func (s *OutboxTransitionStore) SaveTransition(ctx context.Context, state *MonitorState, msgs []*notifications.OutboxMessage) error {
+	stateData, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal monitor state: %w", err)
+	}
+
+	_, err = s.stateRepo.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, queueStateRedisKey, stateData, s.stateRepo.stateTtl)
+		return s.outbox.EnqueueTx(ctx, pipe, msgs...)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save state transition to Redis: %w", err)
+	}
+	return nil
+}
+
+// outboxNotifier collects the notifications produced while handling a queue status instead of sending them,
+// so that the monitor can store them together with the new state.
+type outboxNotifier struct {
+	pending []*notifications.OutboxMessage
+}
+
+func (n *outboxNotifier) SendMessage(ctx context.Context, chatID, text string) error {
+	return n.add(chatID, text, nil)
+}
+
+func (n *outboxNotifier) Notify(ctx context.Context, event *notifications.QueueEvent) error {
+	return n.add(event.ChatID, event.Text, event)
+}
+
+func (n *outboxNotifier) add(chatID, text string, event *notifications.QueueEvent) error {
+	msg, err := notifications.NewOutboxMessage(chatID, text, event)
+	if err != nil {
+		return err
+	}
+	n.pending = append(n.pending, msg)
+	return nil
+}
+
+// drain returns the collected notifications and forgets them.
+func (n *outboxNotifier) drain() []*notifications.OutboxMessage {
+	pending := n.pending
+	n.pending = nil
+	return pending
+}
@@ -2,6 +2,7 @@ package queuemonitor
 
 import (
 	"context"
+	"time"
 )
 
 // QueueState represents a state in the queue monitor state machine.
@@ -18,32 +19,49 @@ type QueueState interface {
 }
 
 // StateFromPersistence reconstructs a QueueState from persisted MonitorState.
-func StateFromPersistence(ms *MonitorState, notifier Notifier, channelName string) QueueState {
+// The thresholds of the low tickets alerts are passed to every state, see LowTicketsThreshold.
+func StateFromPersistence(ms *MonitorState, notifier Notifier, channelName string, lowTickets []LowTicketsThreshold) QueueState {
 	if ms == nil {
-		return &UninitializedState{notifier: notifier, channelName: channelName}
+		return &UninitializedState{notifier: notifier, channelName: channelName, lowTickets: lowTickets}
 	}
 
 	if ms.StateName != "" {
 		switch ms.StateName {
 		case "Inactive":
-			return &InactiveState{notifier: notifier, channelName: channelName}
+			return &InactiveState{notifier: notifier, channelName: channelName, lowTickets: lowTickets, since: ms.Since}
 		case "ActiveDisabled":
-			return &ActiveDisabledState{notifier: notifier, channelName: channelName}
+			return &ActiveDisabledState{notifier: notifier, channelName: channelName, lowTickets: lowTickets, since: ms.Since}
 		case "ActiveEnabled":
-			return &ActiveEnabledState{notifier: notifier, channelName: channelName, ticketsLeft: ms.TicketsLeft}
+			opening := queueOpening{openedAt: ms.OpenedAt, ticketsLeft: ms.TicketsAtOpening, lowTicketsAlerted: ms.LowTicketsAlerted}
+			return &ActiveEnabledState{notifier: notifier, channelName: channelName, lowTickets: lowTickets, ticketsLeft: ms.TicketsLeft, opening: opening}
 		case "Uninitialized":
-			return &UninitializedState{notifier: notifier, channelName: channelName}
+			return &UninitializedState{notifier: notifier, channelName: channelName, lowTickets: lowTickets}
 		}
 	}
 
 	// For backwards compatibility: derive state from boolean flags
 	if !ms.QueueActive {
-		return &InactiveState{notifier: notifier, channelName: channelName}
+		return &InactiveState{notifier: notifier, channelName: channelName, lowTickets: lowTickets}
 	}
 	if ms.QueueEnabled {
-		return &ActiveEnabledState{notifier: notifier, channelName: channelName, ticketsLeft: ms.TicketsLeft}
+		return &ActiveEnabledState{notifier: notifier, channelName: channelName, lowTickets: lowTickets, ticketsLeft: ms.TicketsLeft}
+	}
+	return &ActiveDisabledState{notifier: notifier, channelName: channelName, lowTickets: lowTickets}
+}
+
+// stateSince returns when the monitor entered the state: the opening of the queue for ActiveEnabledState, so every
+// opening is a separate cycle of notifications. Zero if unknown.
+func stateSince(state QueueState) time.Time {
+	switch s := state.(type) {
+	case *InactiveState:
+		return s.since
+	case *ActiveDisabledState:
+		return s.since
+	case *ActiveEnabledState:
+		return s.opening.openedAt
+	default:
+		return time.Time{}
 	}
-	return &ActiveDisabledState{notifier: notifier, channelName: channelName}
 }
 
 // StateToPersistence converts a QueueState to MonitorState for persistence.
@@ -57,13 +75,20 @@ func StateToPersistence(state QueueState, queue *Queue) *MonitorState {
 	case "Inactive":
 		ms.QueueActive = false
 		ms.QueueEnabled = false
+		ms.Since = stateSince(state)
 	case "ActiveDisabled":
 		ms.QueueActive = true
 		ms.QueueEnabled = false
+		ms.Since = stateSince(state)
 	case "ActiveEnabled":
 		ms.QueueActive = true
 		ms.QueueEnabled = true
 		ms.TicketsLeft = state.TicketsLeft()
+		if enabled, ok := state.(*ActiveEnabledState); ok {
+			ms.OpenedAt = enabled.opening.openedAt
+			ms.TicketsAtOpening = enabled.opening.ticketsLeft
+			ms.LowTicketsAlerted = enabled.opening.lowTicketsAlerted
+		}
 	case "Uninitialized":
 		ms.QueueActive = false
 		ms.QueueEnabled = false
@@ -1,11 +1,18 @@
 package queuemonitor
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+)
 
 // ActiveDisabledState represents the state when queue is active but no tickets left.
 type ActiveDisabledState struct {
 	notifier    Notifier
 	channelName string
+	lowTickets  []LowTicketsThreshold
+	since       time.Time // when the queue stopped accepting tickets, zero if unknown
 }
 
 func (s *ActiveDisabledState) Name() string     { return "ActiveDisabled" }
@@ -13,17 +20,18 @@ func (s *ActiveDisabledState) TicketsLeft() int { return 0 }
 
 func (s *ActiveDisabledState) Handle(ctx context.Context, queue *Queue) (QueueState, error) {
 	if !queue.Active {
-		if err := sendNotification(ctx, s.notifier, s.channelName, queue, true); err != nil {
+		if err := sendNotification(ctx, s.notifier, s.channelName, s, queue, notifications.EventQueueInactive, nil); err != nil {
 			return s, err
 		}
-		return &InactiveState{notifier: s.notifier, channelName: s.channelName}, nil
+		return &InactiveState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, since: time.Now().UTC()}, nil
 	}
 
 	if queue.Enabled {
-		if err := sendNotification(ctx, s.notifier, s.channelName, queue, false); err != nil {
+		opening := newQueueOpening(queue)
+		if err := sendNotification(ctx, s.notifier, s.channelName, s, queue, notifications.EventQueueOpened, &opening); err != nil {
 			return s, err
 		}
-		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, ticketsLeft: queue.TicketsLeft}, nil
+		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, ticketsLeft: queue.TicketsLeft, opening: opening}, nil
 	}
 
 	return s, nil
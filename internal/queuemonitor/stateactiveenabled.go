@@ -1,12 +1,32 @@
 package queuemonitor
 
-import "context"
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+)
 
 // ActiveEnabledState represents the state when queue is active (DUW working hours) and there are tickets available.
 type ActiveEnabledState struct {
 	notifier    Notifier
 	channelName string
+	lowTickets  []LowTicketsThreshold
 	ticketsLeft int
+	opening     queueOpening
+}
+
+// queueOpening describes when the queue started to accept tickets, so the notifications can tell how fast the tickets are taken.
+// Every low tickets threshold is alerted once per opening.
+type queueOpening struct {
+	openedAt          time.Time
+	ticketsLeft       int
+	lowTicketsAlerted []int // limits of the alerted low tickets thresholds
+}
+
+func newQueueOpening(queue *Queue) queueOpening {
+	return queueOpening{openedAt: time.Now().UTC(), ticketsLeft: queue.TicketsLeft}
 }
 
 func (s *ActiveEnabledState) Name() string     { return "ActiveEnabled" }
@@ -14,25 +34,34 @@ func (s *ActiveEnabledState) TicketsLeft() int { return s.ticketsLeft }
 
 func (s *ActiveEnabledState) Handle(ctx context.Context, queue *Queue) (QueueState, error) {
 	if !queue.Active {
-		if err := sendNotification(ctx, s.notifier, s.channelName, queue, true); err != nil {
+		if err := sendNotification(ctx, s.notifier, s.channelName, s, queue, notifications.EventQueueInactive, &s.opening); err != nil {
 			return s, err
 		}
-		return &InactiveState{notifier: s.notifier, channelName: s.channelName}, nil
+		return &InactiveState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, since: time.Now().UTC()}, nil
 	}
 
 	if !queue.Enabled {
-		if err := sendNotification(ctx, s.notifier, s.channelName, queue, false); err != nil {
+		if err := sendNotification(ctx, s.notifier, s.channelName, s, queue, notifications.EventQueueUnavailable, &s.opening); err != nil {
 			return s, err
 		}
-		return &ActiveDisabledState{notifier: s.notifier, channelName: s.channelName}, nil
+		return &ActiveDisabledState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, since: time.Now().UTC()}, nil
 	}
 
-	// Still enabled - check if tickets changed
-	if queue.TicketsLeft != s.ticketsLeft {
-		if err := sendNotification(ctx, s.notifier, s.channelName, queue, false); err != nil {
+	// Still enabled - check if tickets changed. A queue which opened below a low tickets threshold is alerted on the
+	// next check, as the opening is notified already
+	crossed := crossedLowTickets(s.lowTickets, &s.opening, queue.TicketsLeft)
+	if queue.TicketsLeft != s.ticketsLeft || len(crossed) > 0 {
+		eventType := notifications.EventTicketsChanged
+		opening := s.opening
+		if len(crossed) > 0 {
+			eventType = notifications.EventTicketsLow
+			opening.lowTicketsAlerted = append(slices.Clone(s.opening.lowTicketsAlerted), crossed...)
+		}
+
+		if err := sendNotification(ctx, s.notifier, s.channelName, s, queue, eventType, &opening); err != nil {
 			return s, err
 		}
-		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, ticketsLeft: queue.TicketsLeft}, nil
+		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, ticketsLeft: queue.TicketsLeft, opening: opening}, nil
 	}
 
 	return s, nil
@@ -1,11 +1,16 @@
 package queuemonitor
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // InactiveState represents the state when queue is not active (DUW off hours)
 type InactiveState struct {
 	notifier    Notifier
 	channelName string
+	lowTickets  []LowTicketsThreshold
+	since       time.Time // when the queue became inactive, zero if unknown
 }
 
 func (s *InactiveState) Name() string     { return "Inactive" }
@@ -17,12 +22,13 @@ func (s *InactiveState) Handle(ctx context.Context, queue *Queue) (QueueState, e
 	}
 
 	// Queue has become active
-	if err := sendNotification(ctx, s.notifier, s.channelName, queue, false); err != nil {
+	opening := newQueueOpening(queue)
+	if err := sendNotification(ctx, s.notifier, s.channelName, s, queue, becameActiveEvent(queue), &opening); err != nil {
 		return s, err
 	}
 
 	if queue.Enabled {
-		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, ticketsLeft: queue.TicketsLeft}, nil
+		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, ticketsLeft: queue.TicketsLeft, opening: opening}, nil
 	}
-	return &ActiveDisabledState{notifier: s.notifier, channelName: s.channelName}, nil
+	return &ActiveDisabledState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, since: time.Now().UTC()}, nil
 }
@@ -1,11 +1,15 @@
 package queuemonitor
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // UninitializedState represents the initial state before first check.
 type UninitializedState struct {
 	notifier    Notifier
 	channelName string
+	lowTickets  []LowTicketsThreshold
 }
 
 func (s *UninitializedState) Name() string     { return "Uninitialized" }
@@ -13,16 +17,17 @@ func (s *UninitializedState) TicketsLeft() int { return 0 }
 
 func (s *UninitializedState) Handle(ctx context.Context, queue *Queue) (QueueState, error) {
 	if !queue.Active {
-		return &InactiveState{notifier: s.notifier, channelName: s.channelName}, nil
+		return &InactiveState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, since: time.Now().UTC()}, nil
 	}
 
 	// Queue has become active - always notify
-	if err := sendNotification(ctx, s.notifier, s.channelName, queue, false); err != nil {
+	opening := newQueueOpening(queue)
+	if err := sendNotification(ctx, s.notifier, s.channelName, s, queue, becameActiveEvent(queue), &opening); err != nil {
 		return s, err
 	}
 
 	if queue.Enabled {
-		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, ticketsLeft: queue.TicketsLeft}, nil
+		return &ActiveEnabledState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, ticketsLeft: queue.TicketsLeft, opening: opening}, nil
 	}
-	return &ActiveDisabledState{notifier: s.notifier, channelName: s.channelName}, nil
+	return &ActiveDisabledState{notifier: s.notifier, channelName: s.channelName, lowTickets: s.lowTickets, since: time.Now().UTC()}, nil
 }
@@ -0,0 +1,79 @@
+// Package queuestatus shares the latest queue status observed by the queue monitor with the Telegram bot.
+package queuestatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const statusRedisKey = "queue:status"
+
+// Snapshot is the queue status observed by the last check of the queue monitor.
+type Snapshot struct {
+	QueueID     int       `json:"queue_id"`
+	QueueName   string    `json:"queue_name"`
+	Active      bool      `json:"active"`
+	Enabled     bool      `json:"enabled"`
+	TicketValue string    `json:"ticket_value"`
+	TicketsLeft int       `json:"tickets_left"`
+	CheckedAt   time.Time `json:"checked_at"` // time of the last successful check
+	UpdatedAt   time.Time `json:"updated_at"` // time when the status last changed
+}
+
+// SameStatus reports whether the snapshots describe the same queue status, regardless of when it was observed.
+func (s *Snapshot) SameStatus(other *Snapshot) bool {
+	return other != nil &&
+		s.QueueID == other.QueueID &&
+		s.QueueName == other.QueueName &&
+		s.Active == other.Active &&
+		s.Enabled == other.Enabled &&
+		s.TicketValue == other.TicketValue &&
+		s.TicketsLeft == other.TicketsLeft
+}
+
+// Store keeps the latest snapshot.
+type Store interface {
+	Get(ctx context.Context) (*Snapshot, error) // returns nil if the queue was never checked
+	Save(ctx context.Context, snapshot *Snapshot) error
+}
+
+// RedisStore keeps the snapshot without expiration: the readers show how old it is.
+type RedisStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisStore(redisClient *redis.Client) *RedisStore {
+	return &RedisStore{redisClient: redisClient}
+}
+
+func (s *RedisStore) Get(ctx context.Context) (*Snapshot, error) {
+	data, err := s.redisClient.Get(ctx, statusRedisKey).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get queue status from Redis: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal queue status: %w", err)
+	}
+	return &snapshot, nil
+}
+
+func (s *RedisStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue status: %w", err)
+	}
+
+	if err := s.redisClient.Set(ctx, statusRedisKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save queue status to Redis: %w", err)
+	}
+	return nil
+}
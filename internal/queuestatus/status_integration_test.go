@@ -0,0 +1,68 @@
+package queuestatus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func initStatusRedisContainer(ctx context.Context, t *testing.T) *redis.Client {
+	req := testcontainers.ContainerRequest{
+		Image:        "redis:latest",
+		Name:         "queuestatus-redis-integration-test",
+		ExposedPorts: []string{"6379/tcp"},
+		WaitingFor:   wait.ForLog("Ready to accept connections"),
+	}
+
+	redisC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start Redis container: \"%v\". Test cannot be executed", err)
+	}
+	t.Cleanup(func() { testcontainers.CleanupContainer(t, redisC) })
+
+	endpoint, err := redisC.Endpoint(ctx, "")
+	if err != nil {
+		t.Fatalf("Failed to get Redis endpoint: \"%v\". Test cannot be executed", err)
+	}
+
+	return redis.NewClient(&redis.Options{Addr: endpoint})
+}
+
+func TestRedisStore_WhenSnapshotIsSaved_ReturnsLatestSnapshot(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sut := NewRedisStore(initStatusRedisContainer(ctx, t))
+	snapshot := &Snapshot{
+		QueueID:     24,
+		QueueName:   "Odbiór karty",
+		Active:      true,
+		Enabled:     true,
+		TicketValue: "K80",
+		TicketsLeft: 10,
+		CheckedAt:   time.Date(2026, 3, 2, 8, 0, 10, 0, time.UTC),
+		UpdatedAt:   time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	empty, emptyErr := sut.Get(ctx)
+	saveErr := sut.Save(ctx, snapshot)
+	saved, getErr := sut.Get(ctx)
+
+	// Assert
+	if emptyErr != nil || saveErr != nil || getErr != nil {
+		t.Fatalf("Expected no errors, but got: %v, %v, %v", emptyErr, saveErr, getErr)
+	}
+	if empty != nil {
+		t.Errorf("Expected no snapshot before saving, but got %+v", empty)
+	}
+	if saved == nil || *saved != *snapshot {
+		t.Errorf("Expected saved snapshot %+v, but got %+v", snapshot, saved)
+	}
+}
@@ -1,8 +1,17 @@
 package telegrambot
 
-import "github.com/UladzK/duw-queue-monitor/internal/notifications"
+import (
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+)
 
 type Config struct {
 	FeedbackChatID       string `env:"NOTIFICATION_TELEGRAM_FEEDBACK_CHAT_ID,required"`
+	FallbackLanguage     string `env:"TELEGRAM_BOT_FALLBACK_LANGUAGE" envDefault:"pl"`      // language of the users whose language is not supported
+	RedisConString       string `env:"STATE_REDIS_CONNECTION_STRING,required" secret:"url"` // shared with queuemonitor, which publishes the queue status
+	MonitoredQueueID     int    `env:"STATUS_MONITORED_QUEUE_ID" envDefault:"24"`           // shared with queuemonitor, the queue of /stats
 	NotificationTelegram notifications.TelegramConfig
+	History              queuehistory.Config
+	Templates            templates.Config
 }
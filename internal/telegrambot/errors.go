@@ -0,0 +1,15 @@
+package telegrambot
+
+import (
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+
+	"github.com/go-telegram/bot"
+)
+
+// NewErrorsHandler logs the errors of the bot library, e.g. of polling for updates, with the logger. The default handler of
+// the library prints them as they are, while the URLs in them contain the bot token, which the logger redacts.
+func NewErrorsHandler(log *logger.Logger) bot.ErrorsHandler {
+	return func(err error) {
+		log.Error("Telegram bot error", err)
+	}
+}
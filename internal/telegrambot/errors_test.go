@@ -0,0 +1,40 @@
+package telegrambot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+func TestNewErrorsHandler_WhenPollingFails_LogsErrorWithoutBotToken(t *testing.T) {
+	// Arrange
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w // the logger writes to the standard output it was created with
+	log := logger.NewLogger(&logger.Config{Level: "error"})
+	os.Stdout = stdout
+
+	sut := NewErrorsHandler(log)
+	// the library wraps the failed request like this
+	err := fmt.Errorf("error do request for method %s, %w", "getUpdates",
+		&url.Error{Op: "Post", URL: "https://api.telegram.org/bot123456:AAHsecretToken/getUpdates", Err: context.DeadlineExceeded})
+
+	// Act
+	sut(err)
+	w.Close()
+	output, _ := io.ReadAll(r)
+
+	// Assert
+	if !strings.Contains(string(output), "getUpdates") {
+		t.Errorf("Expected the error to be logged, but got: %s", output)
+	}
+	if strings.Contains(string(output), "AAHsecretToken") {
+		t.Errorf("Expected the bot token to be redacted, but got: %s", output)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"github.com/UladzK/duw-queue-monitor/internal/charts"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	chartTemplate        = "bot_chart"
+	chartNoDataTemplate  = "chart_no_data"
+	chartInvalidTemplate = "chart_invalid"
+	chartComparedWeeks   = 3
+	chartDayLayout       = "02.01"
+)
+
+// ChartHandler replies to /chart with a chart of the tickets left in the monitored queue during a day, compared with
+// the same weekday of the previous weeks. The day is today, or given as the argument, e.g. "/chart 02.03".
+type ChartHandler struct {
+	log     *logger.Logger
+	history queuehistory.Store
+	queueID int
+}
+
+func NewChartHandler(log *logger.Logger, history queuehistory.Store, queueID int) *ChartHandler {
+	return &ChartHandler{
+		log:     log,
+		history: history,
+		queueID: queueID,
+	}
+}
+
+func (c *ChartHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
+	b.RegisterHandler(bot.HandlerTypeMessageText, "chart", bot.MatchTypeCommand, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		c.HandleUpdate(ctx, b, update)
+	})
+}
+
+func (c *ChartHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	lang := userLanguage(update)
+	chatID := update.Message.Chat.ID
+	day := time.Now().In(c.history.Location())
+	if args := commandArgs(update.Message.Text); len(args) > 0 {
+		parsed, ok := parseChartDay(args[0], day)
+		if !ok {
+			data := templates.ChartData{Invalid: notifications.EscapeHTML(args[0])}
+			sendReply(ctx, b, c.log, chatID, templates.Default.Execute(chartInvalidTemplate, lang, data))
+			return
+		}
+		day = parsed
+	}
+
+	chart, text := c.buildChart(ctx, lang, day)
+	if chart == nil {
+		sendReply(ctx, b, c.log, chatID, text)
+		return
+	}
+	if _, err := b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:    chatID,
+		Photo:     &models.InputFileUpload{Filename: "chart.png", Data: bytes.NewReader(chart)},
+		Caption:   text,
+		ParseMode: models.ParseModeHTML,
+	}); err != nil {
+		c.log.Error("Failed to send chart: ", err)
+	}
+}
+
+// buildChart returns the chart of the day with its caption, or no chart with the reply explaining why.
+func (c *ChartHandler) buildChart(ctx context.Context, lang string, day time.Time) ([]byte, string) {
+	observations, err := c.history.Day(ctx, c.queueID, day)
+	if err != nil {
+		c.log.Error("Failed to get queue history: ", err)
+		return nil, templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	if !queuehistory.Summarize(observations).Opened {
+		return nil, templates.Default.Execute(chartNoDataTemplate, lang, templates.ChartData{Date: day})
+	}
+
+	// the comparison is a nice-to-have, so the days which can't be read are skipped
+	previous, err := queuehistory.PreviousWeeks(ctx, c.history, c.queueID, day, chartComparedWeeks)
+	if err != nil {
+		c.log.Error("Failed to get the queue history of the compared weeks: ", err)
+	}
+	series := charts.ComparedSeries(chartDayLayout, &queuehistory.Day{Date: day, Observations: observations}, previous)
+
+	chart, err := charts.RenderPNG(series)
+	if err != nil {
+		c.log.Error("Failed to draw chart: ", err)
+		return nil, templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	return chart, templates.Default.Execute(chartTemplate, lang, templates.ChartData{
+		QueueName:    notifications.EscapeHTML(observations[len(observations)-1].QueueName),
+		Date:         day,
+		ComparedDays: len(series) - 1,
+	})
+}
+
+// parseChartDay parses the day given as "DD.MM": the last such day until today, in the past year.
+func parseChartDay(arg string, today time.Time) (time.Time, bool) {
+	parsed, err := time.Parse(chartDayLayout, arg)
+	if err != nil {
+		return time.Time{}, false
+	}
+	for year := today.Year(); year >= today.Year()-1; year-- {
+		day := time.Date(year, parsed.Month(), parsed.Day(), 0, 0, 0, 0, today.Location())
+		// 29.02 becomes 01.03 in the years which are not leap
+		if day.Month() == parsed.Month() && !day.After(today) {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+func TestChartHandler_BuildChart_WhenQueueOpened_RendersChartComparedWithPreviousWeeks(t *testing.T) {
+	// Arrange
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	sut := NewChartHandler(logger, &mockHistoryStore{}, 24)
+	day := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	chart, caption := sut.buildChart(context.Background(), "en", day)
+
+	// Assert
+	if !bytes.HasPrefix(chart, []byte("\x89PNG")) {
+		t.Errorf("Expected a PNG chart, got %d bytes", len(chart))
+	}
+	expected := "📉 Tickets left — <b>Odbiór karty</b>, 09.03.2026\nGrey: the same weekday of 3 previous weeks"
+	if caption != expected {
+		t.Errorf("Expected caption %q, got %q", expected, caption)
+	}
+}
+
+func TestChartHandler_BuildChart_WhenThereIsNothingToDraw_RepliesWithoutChart(t *testing.T) {
+	testCases := []struct {
+		name     string
+		store    *mockHistoryStore
+		expected string
+	}{
+		{"Queue didn't open", &mockHistoryStore{empty: true}, "ℹ️ No tickets were available on 09.03.2026."},
+		{"Store fails", &mockHistoryStore{shouldFail: true}, "⚠️ Something went wrong, please try again later."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			logger := logger.NewLogger(&logger.Config{Level: "error"})
+			sut := NewChartHandler(logger, tc.store, 24)
+
+			// Act
+			chart, reply := sut.buildChart(context.Background(), "en", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC))
+
+			// Assert
+			if chart != nil {
+				t.Errorf("Expected no chart, got %d bytes", len(chart))
+			}
+			if reply != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, reply)
+			}
+		})
+	}
+}
+
+func TestParseChartDay_Always_ReturnsLastSuchDayUntilToday(t *testing.T) {
+	today := time.Date(2026, 3, 9, 15, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		arg        string
+		expected   time.Time
+		expectedOk bool
+	}{
+		{"09.03", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), true},
+		{"02.03", time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), true},
+		{"24.12", time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC), true},
+		{"29.02", time.Time{}, false},
+		{"32.01", time.Time{}, false},
+		{"2026-03-02", time.Time{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.arg, func(t *testing.T) {
+			// Act
+			actual, ok := parseChartDay(tc.arg, today)
+
+			// Assert
+			if ok != tc.expectedOk || !actual.Equal(tc.expected) {
+				t.Errorf("Expected %v, %v, got %v, %v", tc.expected, tc.expectedOk, actual, ok)
+			}
+		})
+	}
+}
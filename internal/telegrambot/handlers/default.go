@@ -4,27 +4,30 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
 const (
-	menuTemplate = "Witaj!\n\n<b>Dostępne komendy</b>\n%s\n\nUżyj /start aby zobaczyć to menu ponownie\n"
+	menuTemplate = "bot_menu"
 )
 
-func buildMenuMessage(handlerRegistry HandlerRegistry) string {
+func buildMenuMessage(handlerRegistry HandlerRegistry, lang string) string {
 	commands := handlerRegistry.GetAvailableCommands()
 	commandStrings := make([]string, 0, len(commands))
+	data := templates.MenuData{Commands: make([]templates.CommandData, 0, len(commands))}
 
 	for _, cmd := range commands {
 		commandStrings = append(commandStrings, fmt.Sprintf("/%s - %s", cmd.Command, cmd.Description))
+		data.Commands = append(data.Commands, templates.CommandData{Command: cmd.Command, Description: cmd.Description})
 	}
 
-	commandsText := strings.Join(commandStrings, "\n")
-	menuMessage := fmt.Sprintf(menuTemplate, commandsText)
-	return menuMessage
+	data.CommandList = strings.Join(commandStrings, "\n")
+	return templates.Default.Execute(menuTemplate, lang, data)
 }
 
 type HandlerRegistry interface {
@@ -35,15 +38,20 @@ type DefaultHandler struct {
 	replyRegistry   ReplyRegistry
 	log             *logger.Logger
 	handlerRegistry HandlerRegistry
-	menuMessage     string
+	menuMessages    map[string]string // menu of each language
 }
 
 func NewDefaultHandler(log *logger.Logger, replyRegistry ReplyRegistry, handlerRegistry HandlerRegistry) *DefaultHandler {
+	menuMessages := make(map[string]string)
+	for _, lang := range i18n.Default.Languages() {
+		menuMessages[lang] = buildMenuMessage(handlerRegistry, lang)
+	}
+
 	return &DefaultHandler{
 		log:             log,
 		replyRegistry:   replyRegistry,
 		handlerRegistry: handlerRegistry,
-		menuMessage:     buildMenuMessage(handlerRegistry),
+		menuMessages:    menuMessages,
 	}
 }
 
@@ -64,7 +72,7 @@ func (d *DefaultHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *m
 		return
 	}
 
-	d.sendDefaultMenu(ctx, b, update.Message.Chat.ID)
+	d.sendDefaultMenu(ctx, b, update.Message.Chat.ID, userLanguage(update))
 }
 
 func (d *DefaultHandler) handleReplyMessage(ctx context.Context, b *bot.Bot, update *models.Update) bool {
@@ -83,14 +91,14 @@ func (d *DefaultHandler) handleReplyMessage(ctx context.Context, b *bot.Bot, upd
 	return true
 }
 
-func (d *DefaultHandler) sendDefaultMenu(ctx context.Context, b *bot.Bot, chatID int64) {
+func (d *DefaultHandler) sendDefaultMenu(ctx context.Context, b *bot.Bot, chatID int64, lang string) {
 	if b == nil {
 		return
 	}
 
 	msg, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:    chatID,
-		Text:      d.menuMessage,
+		Text:      d.menuMessages[lang],
 		ParseMode: models.ParseModeHTML,
 	})
 
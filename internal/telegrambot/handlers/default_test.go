@@ -45,7 +45,7 @@ func TestBuildMenuMessage_WithMultipleCommands_BuildsCorrectMenuMessage(t *testi
 	expectedMessage := "Witaj!\n\n<b>Dostępne komendy</b>\n/feedback - Send feedback\n/status - Check status\n/help - Show help\n\nUżyj /start aby zobaczyć to menu ponownie\n"
 
 	// Act
-	success := buildMenuMessage(mockHandlerRegistry)
+	success := buildMenuMessage(mockHandlerRegistry, "pl")
 
 	// Assert
 	if success != expectedMessage {
@@ -207,3 +207,46 @@ func TestHandleReplyMessage_WhenHandlerFound_CallsHandlerAndReturnsTrue(t *testi
 		t.Errorf("Reply handler called with wrong update (-want +got):\n%s", diff)
 	}
 }
+
+func TestUserLanguage_Always_ReturnsSupportedLanguageOfUser(t *testing.T) {
+	testCases := []struct {
+		name     string
+		update   *models.Update
+		expected string
+	}{
+		{"English user", &models.Update{Message: &models.Message{From: &models.User{LanguageCode: "en"}}}, "en"},
+		{"Ukrainian user", &models.Update{Message: &models.Message{From: &models.User{LanguageCode: "uk"}}}, "uk"},
+		{"Unsupported language", &models.Update{Message: &models.Message{From: &models.User{LanguageCode: "de"}}}, "pl"},
+		{"Unknown user", &models.Update{Message: &models.Message{}}, "pl"},
+		{"Button pressed", &models.Update{CallbackQuery: &models.CallbackQuery{From: models.User{LanguageCode: "be"}}}, "be"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := userLanguage(tc.update)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBuildMenuMessage_WhenLanguageIsEnglish_BuildsEnglishMenuMessage(t *testing.T) {
+	// Arrange
+	mockHandlerRegistry := &mockHandlerRegistry{
+		commands: []models.BotCommand{{Command: "feedback", Description: "feedback"}},
+	}
+
+	expectedMessage := "Welcome!\n\n<b>Available commands</b>\n/feedback - feedback\n\nUse /start to see this menu again\n"
+
+	// Act
+	actual := buildMenuMessage(mockHandlerRegistry, "en")
+
+	// Assert
+	if actual != expectedMessage {
+		t.Errorf("Expected menu message:\n%s\nGot:\n%s", expectedMessage, actual)
+	}
+}
@@ -2,19 +2,21 @@ package handlers
 
 import (
 	"context"
-	"fmt"
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
 	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
 const (
-	thankYouText          = "Dziękujemy za Twoją opinię! Twoja wiadomość została wysłana do nas."
-	feedbackInfoText      = "Możesz wysłać swoją opinię na temat działania bota. Twoja wiadomość będzie anonimowa i nie będzie publikowana."
-	feedbackReplyText     = "Aby wysłać opinię, proszę odpowiedz na tę wiadomość swoją opinią:"
-	feedbackAdminTemplate = "💬 <b>Nowa opinia od użytkownika</b>\n\n📝 Treść:\n%s"
+	thankYouTemplate            = "feedback_thank_you"
+	feedbackInfoTemplate        = "feedback_info"
+	feedbackReplyTemplate       = "feedback_prompt"
+	feedbackPlaceholderTemplate = "feedback_placeholder"
+	feedbackAdminTemplate       = "feedback_admin"
 )
 
 type FeedbackHandler struct {
@@ -31,8 +33,14 @@ func NewFeedbackHandler(log *logger.Logger, telegramNotifier *notifications.Tele
 	}
 }
 
+// GetReplyPatterns returns the reply prompt in every language: the user replies to the prompt in their language.
 func (f *FeedbackHandler) GetReplyPatterns() []string {
-	return []string{feedbackReplyText}
+	languages := i18n.Default.Languages()
+	patterns := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		patterns = append(patterns, templates.Default.Execute(feedbackReplyTemplate, lang, nil))
+	}
+	return patterns
 }
 
 func (f *FeedbackHandler) HandleReply(ctx context.Context, b *bot.Bot, update *models.Update) {
@@ -40,13 +48,13 @@ func (f *FeedbackHandler) HandleReply(ctx context.Context, b *bot.Bot, update *m
 
 	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:    update.Message.Chat.ID,
-		Text:      thankYouText,
+		Text:      templates.Default.Execute(thankYouTemplate, userLanguage(update), nil),
 		ParseMode: models.ParseModeHTML,
 	}); err != nil {
 		f.log.Error("Failed to send thank you message for feedback: ", err)
 	}
 
-	adminMessage := fmt.Sprintf(feedbackAdminTemplate, feedbackText)
+	adminMessage := buildFeedbackAdminMessage(feedbackText)
 	if err := f.telegramNotifier.SendMessage(ctx, f.adminChatID, adminMessage); err != nil {
 		f.log.Error("Failed to forward feedback to admin: ", err)
 	} else {
@@ -54,6 +62,13 @@ func (f *FeedbackHandler) HandleReply(ctx context.Context, b *bot.Bot, update *m
 	}
 }
 
+// buildFeedbackAdminMessage escapes the feedback, which is arbitrary user text. If it's too long, the notifier splits it.
+// The admin chat is in the default language, whatever the language of the user.
+func buildFeedbackAdminMessage(feedbackText string) string {
+	data := templates.FeedbackData{Feedback: notifications.EscapeHTML(feedbackText)}
+	return templates.Default.Execute(feedbackAdminTemplate, i18n.DefaultLanguage, data)
+}
+
 func (f *FeedbackHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
 	b.RegisterHandler(bot.HandlerTypeMessageText, "feedback", bot.MatchTypeCommand, func(ctx context.Context, b *bot.Bot, update *models.Update) {
 		f.HandleUpdate(ctx, b, update)
@@ -63,9 +78,10 @@ func (f *FeedbackHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
 }
 
 func (f *FeedbackHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	lang := userLanguage(update)
 	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:    update.Message.Chat.ID,
-		Text:      feedbackInfoText,
+		Text:      templates.Default.Execute(feedbackInfoTemplate, lang, nil),
 		ParseMode: models.ParseModeHTML,
 	}); err != nil {
 		f.log.Error("Failed to send feedback info message: ", err)
@@ -74,10 +90,10 @@ func (f *FeedbackHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *
 
 	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: update.Message.Chat.ID,
-		Text:   feedbackReplyText,
+		Text:   templates.Default.Execute(feedbackReplyTemplate, lang, nil),
 		ReplyMarkup: &models.ForceReply{
 			ForceReply:            true,
-			InputFieldPlaceholder: "Napisz swoją opinię tutaj...",
+			InputFieldPlaceholder: templates.Default.Execute(feedbackPlaceholderTemplate, lang, nil),
 			Selective:             true,
 		},
 	}); err != nil {
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
 	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
 )
 
 func createMockTelegramNotifier(shouldFail bool) *notifications.TelegramNotifier {
@@ -44,8 +47,14 @@ func TestFeedbackHandler_GetReplyPatterns_ReturnsCorrectPattern(t *testing.T) {
 	patterns := sut.GetReplyPatterns()
 
 	// Assert
-	if len(patterns) != 1 || patterns[0] != feedbackReplyText {
-		t.Errorf("Expected patterns [%s], got %v", feedbackReplyText, patterns)
+	expectedPatterns := []string{
+		"Каб адправіць водгук, адкажыце на гэтае паведамленне сваім водгукам:",
+		"To send feedback, please reply to this message with your feedback:",
+		"Aby wysłać opinię, proszę odpowiedz na tę wiadomość swoją opinią:",
+		"Щоб надіслати відгук, дайте відповідь на це повідомлення своїм відгуком:",
+	}
+	if !slices.Equal(patterns, expectedPatterns) {
+		t.Errorf("Expected patterns %v, got %v", expectedPatterns, patterns)
 	}
 }
 
@@ -78,7 +87,7 @@ func TestFeedbackHandler_HandleReply_WhenCalled_ProcessesUserFeedbackCorrectly(t
 	feedbackText := "This is user feedback about the bot"
 
 	// Act
-	adminMessage := fmt.Sprintf(feedbackAdminTemplate, feedbackText)
+	adminMessage := fmt.Sprintf(i18n.Default.Message(i18n.DefaultLanguage, "feedback.admin"), feedbackText)
 	err := mockNotifier.SendMessage(context.Background(), adminChatID, adminMessage)
 
 	// Assert
@@ -100,7 +109,7 @@ func TestFeedbackHandler_HandleReply_WhenCalled_ProcessesUserFeedbackCorrectly(t
 			t.Errorf("Expected POST request for admin notification, got %s", capturedAdminRequest.Method)
 		}
 	}
-	actualAdminMessage := fmt.Sprintf(feedbackAdminTemplate, feedbackText)
+	actualAdminMessage := fmt.Sprintf(i18n.Default.Message(i18n.DefaultLanguage, "feedback.admin"), feedbackText)
 	if actualAdminMessage != expectedAdminMessage {
 		t.Errorf("Expected admin message:\n%s\nGot:\n%s", expectedAdminMessage, actualAdminMessage)
 	}
@@ -112,7 +121,7 @@ func TestFeedbackHandler_HandleReply_WhenAdminNotificationFails_HandlesError(t *
 	adminChatID := "admin123"
 
 	feedbackText := "This is user feedback"
-	adminMessage := fmt.Sprintf(feedbackAdminTemplate, feedbackText)
+	adminMessage := fmt.Sprintf(i18n.Default.Message(i18n.DefaultLanguage, "feedback.admin"), feedbackText)
 
 	// Act
 	err := mockNotifier.SendMessage(context.Background(), adminChatID, adminMessage)
@@ -132,17 +141,17 @@ func TestFeedbackHandler_HandleUpdate_MessageFormat_VerifyCorrectTextsAndFormats
 		{
 			"Info message text",
 			"Możesz wysłać swoją opinię na temat działania bota. Twoja wiadomość będzie anonimowa i nie będzie publikowana.",
-			feedbackInfoText,
+			templates.Default.Execute(feedbackInfoTemplate, i18n.DefaultLanguage, nil),
 		},
 		{
 			"Reply prompt text",
 			"Aby wysłać opinię, proszę odpowiedz na tę wiadomość swoją opinią:",
-			feedbackReplyText,
+			templates.Default.Execute(feedbackReplyTemplate, i18n.DefaultLanguage, nil),
 		},
 		{
 			"Thank you text",
 			"Dziękujemy za Twoją opinię! Twoja wiadomość została wysłana do nas.",
-			thankYouText,
+			templates.Default.Execute(thankYouTemplate, i18n.DefaultLanguage, nil),
 		},
 	}
 
@@ -182,7 +191,7 @@ func TestFeedbackHandler_AdminMessageTemplate_FormatsCorrectly(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Act
-			actualMessage := fmt.Sprintf(feedbackAdminTemplate, tc.feedbackText)
+			actualMessage := fmt.Sprintf(i18n.Default.Message(i18n.DefaultLanguage, "feedback.admin"), tc.feedbackText)
 
 			// Assert
 			if actualMessage != tc.expectedMessage {
@@ -191,3 +200,17 @@ func TestFeedbackHandler_AdminMessageTemplate_FormatsCorrectly(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildFeedbackAdminMessage_WhenFeedbackContainsMarkup_EscapesIt(t *testing.T) {
+	// Arrange
+	feedbackText := "Bot works well! 👍 <good> & <b>fast</b>"
+
+	// Act
+	actualMessage := buildFeedbackAdminMessage(feedbackText)
+
+	// Assert
+	expectedMessage := "💬 <b>Nowa opinia od użytkownika</b>\n\n📝 Treść:\nBot works well! 👍 &lt;good&gt; &amp; &lt;b&gt;fast&lt;/b&gt;"
+	if actualMessage != expectedMessage {
+		t.Errorf("Expected admin message:\n%s\nGot:\n%s", expectedMessage, actualMessage)
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// userLanguage returns the supported language of the user who sent the message or pressed the button,
+// based on the language of their Telegram client.
+func userLanguage(update *models.Update) string {
+	switch {
+	case update == nil:
+		return i18n.Default.Language("")
+	case update.CallbackQuery != nil:
+		return i18n.Default.Language(update.CallbackQuery.From.LanguageCode)
+	case update.Message == nil || update.Message.From == nil:
+		return i18n.Default.Language("")
+	default:
+		return i18n.Default.Language(update.Message.From.LanguageCode)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	ticketTrackedTemplate = "ticket_tracked"
+	ticketInvalidTemplate = "ticket_invalid"
+	ticketNoneTemplate    = "ticket_none"
+	ticketStoppedTemplate = "ticket_stopped"
+
+	stopTrackingArg = "stop"
+)
+
+// MyTicketHandler tracks the paper ticket of the user until the end of the day: "/myticket A123".
+// The queue monitor tells the user when the ticket approaches, see notifications.TicketTracker.
+// "/myticket" shows the tracked ticket and "/myticket stop" stops tracking it.
+type MyTicketHandler struct {
+	log   *logger.Logger
+	store notifications.TrackedTicketStore
+}
+
+func NewMyTicketHandler(log *logger.Logger, store notifications.TrackedTicketStore) *MyTicketHandler {
+	return &MyTicketHandler{
+		log:   log,
+		store: store,
+	}
+}
+
+func (m *MyTicketHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
+	b.RegisterHandler(bot.HandlerTypeMessageText, "myticket", bot.MatchTypeCommand, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		m.HandleUpdate(ctx, b, update)
+	})
+}
+
+func (m *MyTicketHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	lang := userLanguage(update)
+	sendReply(ctx, b, m.log, update.Message.Chat.ID, m.reply(ctx, update.Message.Chat.ID, lang, commandArgs(update.Message.Text)))
+}
+
+func (m *MyTicketHandler) reply(ctx context.Context, chatID int64, lang string, args []string) string {
+	switch {
+	case len(args) == 0:
+		return m.show(ctx, chatID, lang)
+	case len(args) == 1 && strings.EqualFold(args[0], stopTrackingArg):
+		return m.stop(ctx, chatID, lang)
+	default:
+		return m.track(ctx, chatID, lang, strings.Join(args, " "))
+	}
+}
+
+func (m *MyTicketHandler) show(ctx context.Context, chatID int64, lang string) string {
+	tracked, err := m.store.Get(ctx, chatID)
+	if err != nil {
+		m.log.Error("Failed to get tracked ticket: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	if tracked == nil {
+		return templates.Default.Execute(ticketNoneTemplate, lang, nil)
+	}
+	return templates.Default.Execute(ticketTrackedTemplate, lang, templates.TicketData{Ticket: tracked.Ticket.Value})
+}
+
+func (m *MyTicketHandler) stop(ctx context.Context, chatID int64, lang string) string {
+	tracked, err := m.store.Get(ctx, chatID)
+	if err != nil {
+		m.log.Error("Failed to get tracked ticket: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	if tracked == nil {
+		return templates.Default.Execute(ticketNoneTemplate, lang, nil)
+	}
+
+	if err := m.store.Remove(ctx, chatID); err != nil {
+		m.log.Error("Failed to remove tracked ticket: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	return templates.Default.Execute(ticketStoppedTemplate, lang, nil)
+}
+
+func (m *MyTicketHandler) track(ctx context.Context, chatID int64, lang, value string) string {
+	ticket, err := notifications.ParseTicket(value)
+	if err != nil {
+		data := templates.TicketData{Ticket: notifications.EscapeHTML(value)}
+		return templates.Default.Execute(ticketInvalidTemplate, lang, data)
+	}
+
+	tracked := &notifications.TrackedTicket{
+		ChatID:    chatID,
+		Language:  lang,
+		Ticket:    ticket,
+		ExpiresAt: notifications.EndOfDay(time.Now()),
+	}
+	if err := m.store.Save(ctx, tracked); err != nil {
+		m.log.Error("Failed to save tracked ticket: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	m.log.Info("User tracks a ticket", "chatId", chatID, "ticket", ticket.Value)
+
+	return templates.Default.Execute(ticketTrackedTemplate, lang, templates.TicketData{Ticket: ticket.Value})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+)
+
+type mockTrackedTicketStore struct {
+	tickets map[int64]*notifications.TrackedTicket
+}
+
+func newMockTrackedTicketStore(tickets ...*notifications.TrackedTicket) *mockTrackedTicketStore {
+	store := &mockTrackedTicketStore{tickets: make(map[int64]*notifications.TrackedTicket)}
+	for _, ticket := range tickets {
+		store.tickets[ticket.ChatID] = ticket
+	}
+	return store
+}
+
+func (s *mockTrackedTicketStore) Get(ctx context.Context, chatID int64) (*notifications.TrackedTicket, error) {
+	return s.tickets[chatID], nil
+}
+
+func (s *mockTrackedTicketStore) Save(ctx context.Context, ticket *notifications.TrackedTicket) error {
+	s.tickets[ticket.ChatID] = ticket
+	return nil
+}
+
+func (s *mockTrackedTicketStore) Remove(ctx context.Context, chatID int64) error {
+	delete(s.tickets, chatID)
+	return nil
+}
+
+func (s *mockTrackedTicketStore) List(ctx context.Context) ([]*notifications.TrackedTicket, error) {
+	return nil, nil
+}
+
+func (s *mockTrackedTicketStore) MarkApproachingSent(ctx context.Context, ticket *notifications.TrackedTicket) error {
+	return nil
+}
+
+func (s *mockTrackedTicketStore) Untrack(ctx context.Context, ticket *notifications.TrackedTicket) error {
+	return nil
+}
+
+func TestMyTicketHandler_Reply_WhenTicketIsValid_TracksItUntilEndOfDay(t *testing.T) {
+	// Arrange
+	store := newMockTrackedTicketStore()
+	sut := NewMyTicketHandler(logger.NewLogger(&logger.Config{Level: "error"}), store)
+
+	// Act
+	reply := sut.reply(context.Background(), 42, "en", commandArgs("/myticket a 123"))
+
+	// Assert
+	expectedReply := "🎫 Tracking ticket <b>A123</b> until the end of the day. I'll message you when it's close and when it's called."
+	if reply != expectedReply {
+		t.Errorf("Expected %q, got %q", expectedReply, reply)
+	}
+
+	tracked := store.tickets[42]
+	if tracked == nil || tracked.Ticket.Value != "A123" || tracked.Ticket.Number != 123 || tracked.Language != "en" {
+		t.Fatalf("Expected the ticket to be tracked, got %+v", tracked)
+	}
+	if !tracked.ExpiresAt.After(time.Now()) || tracked.ExpiresAt.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("Expected the ticket to expire at the end of the day, got %v", tracked.ExpiresAt)
+	}
+}
+
+func TestMyTicketHandler_Reply_Always_RepliesToEveryCommandForm(t *testing.T) {
+	tracked := &notifications.TrackedTicket{ChatID: 42, Ticket: notifications.Ticket{Value: "K7", Prefix: "K", Number: 7}}
+
+	testCases := []struct {
+		name          string
+		store         *mockTrackedTicketStore
+		command       string
+		expectedReply string
+		expectTracked bool
+	}{
+		{"Show tracked ticket", newMockTrackedTicketStore(tracked), "/myticket", "🎫 Tracking ticket <b>K7</b> until the end of the day. I'll message you when it's close and when it's called.", true},
+		{"Show without ticket", newMockTrackedTicketStore(), "/myticket", "You're not tracking any ticket.\n\nSend your ticket, e.g. <code>/myticket A123</code>\nStop tracking: <code>/myticket stop</code>", false},
+		{"Stop tracking", newMockTrackedTicketStore(tracked), "/myticket STOP", "🗑 Ticket tracking stopped.", false},
+		{"Invalid ticket", newMockTrackedTicketStore(), "/myticket <123>", "⚠️ <code>&lt;123&gt;</code> is not a ticket\n\nSend your ticket, e.g. <code>/myticket A123</code>\nStop tracking: <code>/myticket stop</code>", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			sut := NewMyTicketHandler(logger.NewLogger(&logger.Config{Level: "error"}), tc.store)
+
+			// Act
+			reply := sut.reply(context.Background(), 42, "en", commandArgs(tc.command))
+
+			// Assert
+			if reply != tc.expectedReply {
+				t.Errorf("Expected %q, got %q", tc.expectedReply, reply)
+			}
+			if (tc.store.tickets[42] != nil) != tc.expectTracked {
+				t.Errorf("Expected ticket to be tracked: %v, got %+v", tc.expectTracked, tc.store.tickets[42])
+			}
+		})
+	}
+}
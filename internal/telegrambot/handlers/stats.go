@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	statsTemplate       = "bot_stats"
+	statsPeriodTemplate = "stats_period"
+	statsCallbackPrefix = "stats:"
+	defaultStatsPeriod  = 30
+)
+
+// StatsHandler replies to /stats with the statistics of the monitored queue over the last days, computed from the queue
+// history: how often and when it usually opens, and how long the tickets last. The period is given as the argument,
+// e.g. "/stats 7", and can be switched with the buttons of the reply, which update the message in place.
+type StatsHandler struct {
+	log     *logger.Logger
+	history queuehistory.Store
+	cache   *queuehistory.StatsCache
+}
+
+func NewStatsHandler(log *logger.Logger, history queuehistory.Store, queueID int) *StatsHandler {
+	return &StatsHandler{
+		log:     log,
+		history: history,
+		cache:   queuehistory.NewStatsCache(history, queueID),
+	}
+}
+
+func (s *StatsHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
+	b.RegisterHandler(bot.HandlerTypeMessageText, "stats", bot.MatchTypeCommand, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		s.HandleUpdate(ctx, b, update)
+	})
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, statsCallbackPrefix, bot.MatchTypePrefix, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		s.HandlePeriod(ctx, b, update)
+	})
+}
+
+func (s *StatsHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	lang := userLanguage(update)
+	period := defaultStatsPeriod
+	if args := commandArgs(update.Message.Text); len(args) > 0 {
+		period = parseStatsPeriod(args[0])
+	}
+	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        s.buildStatsMessage(ctx, lang, period),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: statsKeyboard(lang, period),
+	}); err != nil {
+		s.log.Error("Failed to send stats message: ", err)
+	}
+}
+
+// HandlePeriod edits the stats message with the statistics of the period of the pressed button.
+func (s *StatsHandler) HandlePeriod(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	// the button must be answered, otherwise the client shows a loading indicator
+	defer func() {
+		if _, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{CallbackQueryID: query.ID}); err != nil {
+			s.log.Error("Failed to answer stats period: ", err)
+		}
+	}()
+
+	msg := query.Message.Message
+	if msg == nil {
+		s.log.Warn("Stats message to update is not accessible anymore")
+		return
+	}
+
+	lang := userLanguage(update)
+	period := parseStatsPeriod(strings.TrimPrefix(query.Data, statsCallbackPrefix))
+	_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        s.buildStatsMessage(ctx, lang, period),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: statsKeyboard(lang, period),
+	})
+	// pressing the button of the shown period is fine
+	if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		s.log.Error("Failed to update stats message: ", err)
+	}
+}
+
+func (s *StatsHandler) buildStatsMessage(ctx context.Context, lang string, period int) string {
+	today := time.Now().In(s.history.Location())
+	stats, err := s.cache.Get(ctx, today, period)
+	if err != nil {
+		s.log.Error("Failed to get queue stats: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+
+	data := templates.StatsData{
+		Period:               stats.Period,
+		Days:                 stats.Days,
+		OpenedDays:           stats.OpenedDays,
+		OpenedPercent:        percent(stats.OpenedShare()),
+		SoldOutDays:          stats.SoldOutDays,
+		MedianSoldOutMinutes: int(stats.MedianSoldOutAfter.Minutes()),
+	}
+	midnight := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	for _, weekday := range stats.Weekdays {
+		weekdayData := templates.WeekdayStatsData{
+			Weekday:         strings.ToLower(weekday.Weekday.String()),
+			Days:            weekday.Days,
+			OpenedDays:      weekday.OpenedDays,
+			OpeningsPercent: percent(weekday.OpeningsShare(stats)),
+		}
+		if weekday.OpenedDays > 0 {
+			weekdayData.TypicalOpeningAt = midnight.Add(weekday.TypicalOpeningAt)
+		}
+		data.Weekdays = append(data.Weekdays, weekdayData)
+	}
+	return templates.Default.Execute(statsTemplate, lang, data)
+}
+
+// parseStatsPeriod returns the period given as the argument, or the default period if it's not one of queuehistory.StatsPeriods.
+func parseStatsPeriod(arg string) int {
+	period, err := strconv.Atoi(strings.TrimSpace(arg))
+	if err != nil || !slices.Contains(queuehistory.StatsPeriods, period) {
+		return defaultStatsPeriod
+	}
+	return period
+}
+
+func percent(share float64) int {
+	return int(math.Round(share * 100))
+}
+
+// statsKeyboard has a button for each period, the shown one is marked.
+func statsKeyboard(lang string, shown int) *models.InlineKeyboardMarkup {
+	buttons := make([]models.InlineKeyboardButton, 0, len(queuehistory.StatsPeriods))
+	for _, period := range queuehistory.StatsPeriods {
+		text := templates.Default.Execute(statsPeriodTemplate, lang, period)
+		if period == shown {
+			text = "• " + text + " •"
+		}
+		buttons = append(buttons, models.InlineKeyboardButton{Text: text, CallbackData: statsCallbackPrefix + strconv.Itoa(period)})
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{buttons}}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+)
+
+// mockHistoryStore returns the same day for every date: the queue opens at 08:10 with 100 tickets, which run out
+// after an hour, and closes at 16:00.
+type mockHistoryStore struct {
+	shouldFail bool
+	empty      bool // nothing was observed on any day
+}
+
+func (s *mockHistoryStore) Append(ctx context.Context, queueID int, obs *queuehistory.Observation) error {
+	return nil
+}
+
+func (s *mockHistoryStore) Day(ctx context.Context, queueID int, day time.Time) ([]*queuehistory.Observation, error) {
+	if s.shouldFail {
+		return nil, fmt.Errorf("failed to get queue history")
+	}
+	if s.empty {
+		return nil, nil
+	}
+	at := func(hour, min int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, min, 0, 0, day.Location())
+	}
+	return []*queuehistory.Observation{
+		{At: at(8, 10), QueueName: "Odbiór karty", Active: true, Enabled: true, TicketsLeft: 100},
+		{At: at(9, 10), QueueName: "Odbiór karty", Active: true},
+		{At: at(16, 0), QueueName: "Odbiór karty"},
+	}, nil
+}
+
+func (s *mockHistoryStore) Location() *time.Location {
+	return time.UTC
+}
+
+func TestStatsHandler_BuildStatsMessage_WhenHistoryIsKnown_RendersStatsOfPeriod(t *testing.T) {
+	// Arrange
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	sut := NewStatsHandler(logger, &mockHistoryStore{}, 24)
+
+	expectedMessage := "📈 Queue statistics — last 7 days\n" +
+		"🔔 Opened on <b>100%</b> of days (7 of 7)\n" +
+		"⏱ Median time until tickets run out: <b>60 minutes</b>\n" +
+		"\n" +
+		"📅 By day of the week:\n" +
+		"Monday: usually at <b>08:10</b> · 14% of openings · 1/1 days\n" +
+		"Tuesday: usually at <b>08:10</b> · 14% of openings · 1/1 days\n" +
+		"Wednesday: usually at <b>08:10</b> · 14% of openings · 1/1 days\n" +
+		"Thursday: usually at <b>08:10</b> · 14% of openings · 1/1 days\n" +
+		"Friday: usually at <b>08:10</b> · 14% of openings · 1/1 days\n" +
+		"Saturday: usually at <b>08:10</b> · 14% of openings · 1/1 days\n" +
+		"Sunday: usually at <b>08:10</b> · 14% of openings · 1/1 days"
+
+	// Act
+	actual := sut.buildStatsMessage(context.Background(), "en", 7)
+
+	// Assert
+	if actual != expectedMessage {
+		t.Errorf("Expected stats message:\n%s\nGot:\n%s", expectedMessage, actual)
+	}
+}
+
+func TestStatsHandler_BuildStatsMessage_WhenStoreFails_RendersError(t *testing.T) {
+	// Arrange
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	sut := NewStatsHandler(logger, &mockHistoryStore{shouldFail: true}, 24)
+
+	// Act
+	actual := sut.buildStatsMessage(context.Background(), "en", 30)
+
+	// Assert
+	expected := "⚠️ Something went wrong, please try again later."
+	if actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestParseStatsPeriod_Always_ReturnsSupportedPeriodOrDefault(t *testing.T) {
+	testCases := []struct {
+		arg      string
+		expected int
+	}{
+		{"", 30},
+		{"7", 7},
+		{" 90 ", 90},
+		{"14", 30},
+		{"week", 30},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%q", tc.arg), func(t *testing.T) {
+			// Act
+			actual := parseStatsPeriod(tc.arg)
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected period %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStatsKeyboard_Always_HasButtonForEachPeriodAndMarksShownOne(t *testing.T) {
+	// Act
+	keyboard := statsKeyboard("en", 30)
+
+	// Assert
+	if len(keyboard.InlineKeyboard) != 1 {
+		t.Fatalf("Expected a single row of buttons, got %+v", keyboard.InlineKeyboard)
+	}
+	var actual []string
+	for _, button := range keyboard.InlineKeyboard[0] {
+		actual = append(actual, button.Text+"="+button.CallbackData)
+	}
+	expected := []string{"7 days=stats:7", "• 30 days •=stats:30", "90 days=stats:90"}
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("Expected buttons %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuestatus"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	statusTemplate        = "bot_status"
+	statusRefreshTemplate = "status_refresh"
+	statusRefreshCallback = "status:refresh"
+)
+
+// StatusHandler replies to /status with the latest queue status published by the queue monitor.
+// The reply has a "Refresh" button, which updates the message in place.
+type StatusHandler struct {
+	log   *logger.Logger
+	store queuestatus.Store
+}
+
+func NewStatusHandler(log *logger.Logger, store queuestatus.Store) *StatusHandler {
+	return &StatusHandler{
+		log:   log,
+		store: store,
+	}
+}
+
+func (s *StatusHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
+	b.RegisterHandler(bot.HandlerTypeMessageText, "status", bot.MatchTypeCommand, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		s.HandleUpdate(ctx, b, update)
+	})
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, statusRefreshCallback, bot.MatchTypeExact, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		s.HandleRefresh(ctx, b, update)
+	})
+}
+
+func (s *StatusHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	lang := userLanguage(update)
+	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        s.buildStatusMessage(ctx, lang),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: refreshKeyboard(lang),
+	}); err != nil {
+		s.log.Error("Failed to send status message: ", err)
+	}
+}
+
+// HandleRefresh edits the status message with the latest queue status.
+func (s *StatusHandler) HandleRefresh(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	// the button must be answered, otherwise the client shows a loading indicator
+	defer func() {
+		if _, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{CallbackQueryID: query.ID}); err != nil {
+			s.log.Error("Failed to answer status refresh: ", err)
+		}
+	}()
+
+	msg := query.Message.Message
+	if msg == nil {
+		s.log.Warn("Status message to refresh is not accessible anymore")
+		return
+	}
+
+	lang := userLanguage(update)
+	_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        s.buildStatusMessage(ctx, lang),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: refreshKeyboard(lang),
+	})
+	// refreshing an unchanged status is fine
+	if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		s.log.Error("Failed to refresh status message: ", err)
+	}
+}
+
+func (s *StatusHandler) buildStatusMessage(ctx context.Context, lang string) string {
+	snapshot, err := s.store.Get(ctx)
+	if err != nil {
+		s.log.Error("Failed to get queue status: ", err)
+	}
+	if snapshot == nil {
+		return templates.Default.Execute(statusTemplate, lang, templates.StatusData{})
+	}
+
+	return templates.Default.Execute(statusTemplate, lang, templates.StatusData{
+		Known: true,
+		Queue: templates.QueueData{
+			ID:          snapshot.QueueID,
+			Name:        notifications.EscapeHTML(snapshot.QueueName),
+			Active:      snapshot.Active,
+			Enabled:     snapshot.Enabled,
+			TicketValue: notifications.EscapeHTML(snapshot.TicketValue),
+			TicketsLeft: snapshot.TicketsLeft,
+		},
+		CheckedAt: templates.Default.LocalTime(snapshot.CheckedAt),
+		UpdatedAt: templates.Default.LocalTime(snapshot.UpdatedAt),
+	})
+}
+
+func refreshKeyboard(lang string) *models.InlineKeyboardMarkup {
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{Text: templates.Default.Execute(statusRefreshTemplate, lang, nil), CallbackData: statusRefreshCallback},
+		}},
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/queuestatus"
+
+	"github.com/go-telegram/bot/models"
+)
+
+type mockStatusStore struct {
+	snapshot   *queuestatus.Snapshot
+	shouldFail bool
+}
+
+func (s *mockStatusStore) Get(ctx context.Context) (*queuestatus.Snapshot, error) {
+	if s.shouldFail {
+		return nil, fmt.Errorf("failed to get status")
+	}
+	return s.snapshot, nil
+}
+
+func (s *mockStatusStore) Save(ctx context.Context, snapshot *queuestatus.Snapshot) error {
+	s.snapshot = snapshot
+	return nil
+}
+
+func TestStatusHandler_BuildStatusMessage_WhenStatusIsKnown_RendersLocalTimesAndEscapesValues(t *testing.T) {
+	// Arrange
+	logger := logger.NewLogger(&logger.Config{Level: "error"})
+	store := &mockStatusStore{snapshot: &queuestatus.Snapshot{
+		QueueID:     24,
+		QueueName:   "Odbiór <karty>",
+		Active:      true,
+		Enabled:     true,
+		TicketValue: "K12",
+		TicketsLeft: 5,
+		CheckedAt:   time.Date(2026, 7, 1, 8, 30, 15, 0, time.UTC),
+		UpdatedAt:   time.Date(2026, 7, 1, 8, 29, 0, 0, time.UTC),
+	}}
+	sut := NewStatusHandler(logger, store)
+
+	expectedMessage := "📊 Queue <b>Odbiór &lt;karty&gt;</b>\n" +
+		"✅ Active, tickets available\n" +
+		"🎟️ Last called ticket: <b>K12</b>\n" +
+		"🧾 <b>5</b> tickets left\n" +
+		"🔍 Last check: <i>01.07 10:30:15</i>\n" +
+		"🕒 Last change: <i>01.07 10:29:00</i>"
+
+	// Act
+	actual := sut.buildStatusMessage(context.Background(), "en")
+
+	// Assert
+	if actual != expectedMessage {
+		t.Errorf("Expected status message:\n%s\nGot:\n%s", expectedMessage, actual)
+	}
+}
+
+func TestStatusHandler_BuildStatusMessage_WhenStatusIsNotAvailable_RendersUnknownStatus(t *testing.T) {
+	testCases := []struct {
+		name  string
+		store *mockStatusStore
+	}{
+		{"Queue was never checked", &mockStatusStore{}},
+		{"Store fails", &mockStatusStore{shouldFail: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			logger := logger.NewLogger(&logger.Config{Level: "error"})
+			sut := NewStatusHandler(logger, tc.store)
+
+			// Act
+			actual := sut.buildStatusMessage(context.Background(), "en")
+
+			// Assert
+			expected := "ℹ️ No queue data yet — the monitor hasn't checked it."
+			if actual != expected {
+				t.Errorf("Expected %q, got %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestRefreshKeyboard_Always_HasRefreshButtonInUserLanguage(t *testing.T) {
+	// Act
+	keyboard := refreshKeyboard("en")
+
+	// Assert
+	if len(keyboard.InlineKeyboard) != 1 || len(keyboard.InlineKeyboard[0]) != 1 {
+		t.Fatalf("Expected a single button, got %+v", keyboard.InlineKeyboard)
+	}
+	button := keyboard.InlineKeyboard[0][0]
+	expected := models.InlineKeyboardButton{Text: "🔄 Refresh", CallbackData: statusRefreshCallback}
+	if button.Text != expected.Text || button.CallbackData != expected.CallbackData {
+		t.Errorf("Expected button %+v, got %+v", expected, button)
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/templates"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	subscriptionTemplate        = "subscription"
+	subscriptionInvalidTemplate = "subscription_invalid"
+	unsubscribedTemplate        = "unsubscribed"
+	notSubscribedTemplate       = "not_subscribed"
+	privateOnlyTemplate         = "subscription_private_only"
+	errorTemplate               = "bot_error"
+)
+
+// SubscribeHandler subscribes the user to direct messages about the queues:
+// "/subscribe [opening|low|all] [queue IDs] [HH:MM-HH:MM]". Omitted settings take their defaults: only openings,
+// of all queues, without quiet hours. Without arguments, the current subscription is shown, or created with the defaults.
+// Only private chats can subscribe, so the messages don't go to a group by whoever of its members sent the command.
+type SubscribeHandler struct {
+	log   *logger.Logger
+	store notifications.TelegramSubscriptionStore
+}
+
+func NewSubscribeHandler(log *logger.Logger, store notifications.TelegramSubscriptionStore) *SubscribeHandler {
+	return &SubscribeHandler{
+		log:   log,
+		store: store,
+	}
+}
+
+func (s *SubscribeHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
+	b.RegisterHandler(bot.HandlerTypeMessageText, "subscribe", bot.MatchTypeCommand, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		s.HandleUpdate(ctx, b, update)
+	})
+}
+
+func (s *SubscribeHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	lang := userLanguage(update)
+	sendReply(ctx, b, s.log, update.Message.Chat.ID, s.subscribe(ctx, update.Message.Chat, lang, commandArgs(update.Message.Text)))
+}
+
+// subscribe saves the subscription described by the command arguments and returns the reply.
+func (s *SubscribeHandler) subscribe(ctx context.Context, chat models.Chat, lang string, args []string) string {
+	if chat.Type != models.ChatTypePrivate {
+		return templates.Default.Execute(privateOnlyTemplate, lang, nil)
+	}
+	chatID := chat.ID
+
+	sub, invalid := parseSubscription(args)
+	if invalid != "" {
+		data := templates.SubscriptionData{Invalid: notifications.EscapeHTML(invalid)}
+		return templates.Default.Execute(subscriptionInvalidTemplate, lang, data)
+	}
+
+	if len(args) == 0 {
+		existing, err := s.store.Get(ctx, chatID)
+		if err != nil {
+			s.log.Error("Failed to get subscription: ", err)
+			return templates.Default.Execute(errorTemplate, lang, nil)
+		}
+		if existing != nil {
+			sub = existing
+		}
+	}
+
+	sub.ChatID = chatID
+	sub.Language = lang
+	if err := s.store.Save(ctx, sub); err != nil {
+		s.log.Error("Failed to save subscription: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	s.log.Info("User subscribed", "chatId", chatID, "events", sub.Events)
+
+	return templates.Default.Execute(subscriptionTemplate, lang, buildSubscriptionData(sub))
+}
+
+// parseSubscription returns the subscription described by the command arguments, or the first argument which can't be understood.
+func parseSubscription(args []string) (*notifications.TelegramSubscription, string) {
+	sub := &notifications.TelegramSubscription{Events: notifications.SubscriptionEventsOpening}
+	for _, arg := range args {
+		if events, err := notifications.ParseSubscriptionEvents(arg); err == nil {
+			sub.Events = events
+			continue
+		}
+
+		if strings.Contains(arg, ":") {
+			var quietHours notifications.QuietHours
+			if err := quietHours.UnmarshalText([]byte(arg)); err != nil {
+				return nil, arg
+			}
+			sub.QuietHours = &quietHours
+			continue
+		}
+
+		queueID, err := strconv.Atoi(arg)
+		if err != nil || queueID <= 0 {
+			return nil, arg
+		}
+		if !slices.Contains(sub.Queues, queueID) {
+			sub.Queues = append(sub.Queues, queueID)
+		}
+	}
+	return sub, ""
+}
+
+func buildSubscriptionData(sub *notifications.TelegramSubscription) templates.SubscriptionData {
+	queues := make([]string, 0, len(sub.Queues))
+	for _, queueID := range sub.Queues {
+		queues = append(queues, strconv.Itoa(queueID))
+	}
+
+	data := templates.SubscriptionData{
+		Events:    string(sub.Events),
+		QueueList: strings.Join(queues, ", "),
+	}
+	if sub.QuietHours != nil {
+		data.QuietHours = sub.QuietHours.String()
+	}
+	return data
+}
+
+// UnsubscribeHandler removes the subscription of the user. Like subscribing, it works only in private chats.
+type UnsubscribeHandler struct {
+	log   *logger.Logger
+	store notifications.TelegramSubscriptionStore
+}
+
+func NewUnsubscribeHandler(log *logger.Logger, store notifications.TelegramSubscriptionStore) *UnsubscribeHandler {
+	return &UnsubscribeHandler{
+		log:   log,
+		store: store,
+	}
+}
+
+func (u *UnsubscribeHandler) Register(b *bot.Bot, replyRegistry ReplyRegistry) {
+	b.RegisterHandler(bot.HandlerTypeMessageText, "unsubscribe", bot.MatchTypeCommand, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		u.HandleUpdate(ctx, b, update)
+	})
+}
+
+func (u *UnsubscribeHandler) HandleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	lang := userLanguage(update)
+	sendReply(ctx, b, u.log, update.Message.Chat.ID, u.unsubscribe(ctx, update.Message.Chat, lang))
+}
+
+func (u *UnsubscribeHandler) unsubscribe(ctx context.Context, chat models.Chat, lang string) string {
+	if chat.Type != models.ChatTypePrivate {
+		return templates.Default.Execute(privateOnlyTemplate, lang, nil)
+	}
+	chatID := chat.ID
+
+	existing, err := u.store.Get(ctx, chatID)
+	if err != nil {
+		u.log.Error("Failed to get subscription: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	if existing == nil {
+		return templates.Default.Execute(notSubscribedTemplate, lang, nil)
+	}
+
+	if err := u.store.Remove(ctx, chatID); err != nil {
+		u.log.Error("Failed to remove subscription: ", err)
+		return templates.Default.Execute(errorTemplate, lang, nil)
+	}
+	u.log.Info("User unsubscribed", "chatId", chatID)
+
+	return templates.Default.Execute(unsubscribedTemplate, lang, nil)
+}
+
+// commandArgs returns the arguments of the command, e.g. ["low", "24"] of "/subscribe low 24".
+func commandArgs(text string) []string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields[1:]
+}
+
+func sendReply(ctx context.Context, b *bot.Bot, log *logger.Logger, chatID int64, text string) {
+	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	}); err != nil {
+		log.Error("Failed to send reply: ", err)
+	}
+}
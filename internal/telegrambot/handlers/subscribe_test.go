@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/google/go-cmp/cmp"
+)
+
+var privateChat = models.Chat{ID: 42, Type: models.ChatTypePrivate}
+
+type mockSubscriptionStore struct {
+	subs       map[int64]*notifications.TelegramSubscription
+	shouldFail bool
+}
+
+func newMockSubscriptionStore(subs ...*notifications.TelegramSubscription) *mockSubscriptionStore {
+	store := &mockSubscriptionStore{subs: make(map[int64]*notifications.TelegramSubscription)}
+	for _, sub := range subs {
+		store.subs[sub.ChatID] = sub
+	}
+	return store
+}
+
+func (s *mockSubscriptionStore) Get(ctx context.Context, chatID int64) (*notifications.TelegramSubscription, error) {
+	if s.shouldFail {
+		return nil, fmt.Errorf("failed to get subscription")
+	}
+	return s.subs[chatID], nil
+}
+
+func (s *mockSubscriptionStore) Save(ctx context.Context, sub *notifications.TelegramSubscription) error {
+	if s.shouldFail {
+		return fmt.Errorf("failed to save subscription")
+	}
+	s.subs[sub.ChatID] = sub
+	return nil
+}
+
+func (s *mockSubscriptionStore) Remove(ctx context.Context, chatID int64) error {
+	delete(s.subs, chatID)
+	return nil
+}
+
+func (s *mockSubscriptionStore) List(ctx context.Context) ([]*notifications.TelegramSubscription, error) {
+	return nil, nil
+}
+
+func TestParseSubscription_Always_ParsesEventsQueuesAndQuietHours(t *testing.T) {
+	quietHours := notifications.QuietHours{From: 22 * time.Hour, To: 7 * time.Hour}
+
+	testCases := []struct {
+		name            string
+		args            []string
+		expected        *notifications.TelegramSubscription
+		expectedInvalid string
+	}{
+		{"Defaults", nil, &notifications.TelegramSubscription{Events: notifications.SubscriptionEventsOpening}, ""},
+		{
+			"All settings",
+			[]string{"LOW", "24", "25", "24", "22:00-07:00"},
+			&notifications.TelegramSubscription{Events: notifications.SubscriptionEventsLow, Queues: []int{24, 25}, QuietHours: &quietHours},
+			"",
+		},
+		{"Unknown events", []string{"sometimes"}, nil, "sometimes"},
+		{"Invalid quiet hours", []string{"all", "22:00"}, nil, "22:00"},
+		{"Invalid queue", []string{"-1"}, nil, "-1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual, invalid := parseSubscription(tc.args)
+
+			// Assert
+			if invalid != tc.expectedInvalid {
+				t.Errorf("Expected invalid argument %q, got %q", tc.expectedInvalid, invalid)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Subscription mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubscribeHandler_Subscribe_WhenArgumentsAreValid_SavesSubscriptionAndDescribesIt(t *testing.T) {
+	// Arrange
+	store := newMockSubscriptionStore()
+	sut := NewSubscribeHandler(logger.NewLogger(&logger.Config{Level: "error"}), store)
+
+	expectedReply := "🔔 You're subscribed to direct messages:\n" +
+		"• when the queue opens and when few tickets are left\n" +
+		"• queues: 24, 25\n" +
+		"• quiet hours, without sound: 22:00-07:00\n\n" +
+		"Change: <code>/subscribe [opening|low|all] [queue IDs] [HH:MM-HH:MM]</code>, e.g. <code>/subscribe low 24 22:00-07:00</code>\n" +
+		"Stop: /unsubscribe"
+
+	// Act
+	reply := sut.subscribe(context.Background(), privateChat, "en", commandArgs("/subscribe low 24 25 22:00-07:00"))
+
+	// Assert
+	if reply != expectedReply {
+		t.Errorf("Expected reply:\n%s\nGot:\n%s", expectedReply, reply)
+	}
+
+	saved := store.subs[42]
+	if saved == nil || saved.ChatID != 42 || saved.Language != "en" || saved.Events != notifications.SubscriptionEventsLow {
+		t.Errorf("Expected the subscription to be saved with the chat and language, got %+v", saved)
+	}
+}
+
+func TestSubscribeHandler_Subscribe_WithoutArguments_KeepsExistingSettings(t *testing.T) {
+	// Arrange
+	existing := &notifications.TelegramSubscription{ChatID: 42, Language: "pl", Events: notifications.SubscriptionEventsAll, Queues: []int{24}}
+	store := newMockSubscriptionStore(existing)
+	sut := NewSubscribeHandler(logger.NewLogger(&logger.Config{Level: "error"}), store)
+
+	// Act
+	sut.subscribe(context.Background(), privateChat, "en", commandArgs("/subscribe"))
+
+	// Assert
+	expected := &notifications.TelegramSubscription{ChatID: 42, Language: "en", Events: notifications.SubscriptionEventsAll, Queues: []int{24}}
+	if diff := cmp.Diff(expected, store.subs[42]); diff != "" {
+		t.Errorf("Subscription mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSubscribeHandler_Subscribe_WhenArgumentIsInvalid_EscapesItAndDoesNotSave(t *testing.T) {
+	// Arrange
+	store := newMockSubscriptionStore()
+	sut := NewSubscribeHandler(logger.NewLogger(&logger.Config{Level: "error"}), store)
+
+	// Act
+	reply := sut.subscribe(context.Background(), privateChat, "en", commandArgs("/subscribe <b>"))
+
+	// Assert
+	expectedPrefix := "⚠️ Can't understand <code>&lt;b&gt;</code>\n\n"
+	if !strings.HasPrefix(reply, expectedPrefix) {
+		t.Errorf("Expected reply to start with %q, got %q", expectedPrefix, reply)
+	}
+	if len(store.subs) != 0 {
+		t.Errorf("Expected nothing to be saved, got %v", store.subs)
+	}
+}
+
+func TestUnsubscribeHandler_Unsubscribe_Always_RemovesSubscriptionIfExists(t *testing.T) {
+	testCases := []struct {
+		name          string
+		store         *mockSubscriptionStore
+		expectedReply string
+	}{
+		{
+			"Subscribed",
+			newMockSubscriptionStore(&notifications.TelegramSubscription{ChatID: 42, Events: notifications.SubscriptionEventsOpening}),
+			"🔕 You're unsubscribed. Use /subscribe to subscribe again.",
+		},
+		{"Not subscribed", newMockSubscriptionStore(), "You're not subscribed. Use /subscribe to subscribe."},
+		{"Store fails", &mockSubscriptionStore{shouldFail: true}, "⚠️ Something went wrong, please try again later."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			sut := NewUnsubscribeHandler(logger.NewLogger(&logger.Config{Level: "error"}), tc.store)
+
+			// Act
+			reply := sut.unsubscribe(context.Background(), privateChat, "en")
+
+			// Assert
+			if reply != tc.expectedReply {
+				t.Errorf("Expected %q, got %q", tc.expectedReply, reply)
+			}
+			if tc.store.subs[42] != nil {
+				t.Errorf("Expected no subscription to remain, got %+v", tc.store.subs[42])
+			}
+		})
+	}
+}
+
+func TestSubscribeHandlers_WhenChatIsNotPrivate_RejectCommandAndDoNotChangeSubscription(t *testing.T) {
+	existing := &notifications.TelegramSubscription{ChatID: -100, Events: notifications.SubscriptionEventsOpening}
+	expectedReply := "🔒 Subscriptions work only in a private chat with the bot."
+
+	for _, chatType := range []models.ChatType{models.ChatTypeGroup, models.ChatTypeSupergroup, models.ChatTypeChannel} {
+		t.Run(string(chatType), func(t *testing.T) {
+			// Arrange
+			store := newMockSubscriptionStore(existing)
+			log := logger.NewLogger(&logger.Config{Level: "error"})
+			chat := models.Chat{ID: -100, Type: chatType}
+
+			// Act
+			subscribeReply := NewSubscribeHandler(log, store).subscribe(context.Background(), chat, "en", commandArgs("/subscribe all"))
+			unsubscribeReply := NewUnsubscribeHandler(log, store).unsubscribe(context.Background(), chat, "en")
+
+			// Assert
+			if subscribeReply != expectedReply || unsubscribeReply != expectedReply {
+				t.Errorf("Expected both replies to be %q, got %q and %q", expectedReply, subscribeReply, unsubscribeReply)
+			}
+			if diff := cmp.Diff(existing, store.subs[-100]); diff != "" {
+				t.Errorf("Expected the subscription not to change (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
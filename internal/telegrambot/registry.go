@@ -2,8 +2,12 @@ package telegrambot
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"github.com/UladzK/duw-queue-monitor/internal/logger"
 	"github.com/UladzK/duw-queue-monitor/internal/notifications"
+	"github.com/UladzK/duw-queue-monitor/internal/queuehistory"
+	"github.com/UladzK/duw-queue-monitor/internal/queuestatus"
 	"github.com/UladzK/duw-queue-monitor/internal/telegrambot/handlers"
 
 	"github.com/go-telegram/bot"
@@ -43,9 +47,15 @@ type HandlerRegistry struct {
 	adminChatID      string
 }
 
-func NewHandlerRegistry(log *logger.Logger, telegramNotifier *notifications.TelegramNotifier, adminChatID string) *HandlerRegistry {
+func NewHandlerRegistry(log *logger.Logger, telegramNotifier *notifications.TelegramNotifier, adminChatID string, statusStore queuestatus.Store, subscriptionStore notifications.TelegramSubscriptionStore, ticketStore notifications.TrackedTicketStore, history queuehistory.Store, queueID int) *HandlerRegistry {
 	handlersMap := map[string]Handler{
-		"feedback": handlers.NewFeedbackHandler(log, telegramNotifier, adminChatID),
+		"feedback":    handlers.NewFeedbackHandler(log, telegramNotifier, adminChatID),
+		"status":      handlers.NewStatusHandler(log, statusStore),
+		"subscribe":   handlers.NewSubscribeHandler(log, subscriptionStore),
+		"unsubscribe": handlers.NewUnsubscribeHandler(log, subscriptionStore),
+		"myticket":    handlers.NewMyTicketHandler(log, ticketStore),
+		"stats":       handlers.NewStatsHandler(log, history, queueID),
+		"chart":       handlers.NewChartHandler(log, history, queueID),
 	}
 
 	return &HandlerRegistry{
@@ -69,7 +79,8 @@ func (hr *HandlerRegistry) RegisterAllHandlers(b *bot.Bot) {
 
 func (hr *HandlerRegistry) GetAvailableCommands() []models.BotCommand {
 	commands := make([]models.BotCommand, 0, len(hr.handlersMap))
-	for commandName := range hr.handlersMap {
+	// sorted, so the menu doesn't change its order between calls
+	for _, commandName := range slices.Sorted(maps.Keys(hr.handlersMap)) {
 		commands = append(commands, models.BotCommand{
 			Command:     commandName,
 			Description: commandName,
@@ -27,7 +27,7 @@ func createTestHandlerRegistry() *HandlerRegistry {
 
 	telegramNotifier := notifications.NewTelegramNotifier(cfg, logger, &http.Client{})
 
-	return NewHandlerRegistry(logger, telegramNotifier, "admin123")
+	return NewHandlerRegistry(logger, telegramNotifier, "admin123", nil, nil, nil, nil, 24)
 }
 
 func TestHandlerRegistry_RegisterAllHandlers_FullFunctionality(t *testing.T) {
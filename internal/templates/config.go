@@ -0,0 +1,8 @@
+package templates
+
+type Config struct {
+	// directory with "<name>.tmpl" or "<name>.<language>.tmpl" files overriding the built-in templates; built-in only if empty
+	Dir      string `env:"TEMPLATES_DIR"`
+	// time zone of the office: the local times in all the messages, and the days of the queue history, are in it
+	TimeZone string `env:"OFFICE_TIMEZONE" envDefault:"Europe/Warsaw"`
+}
@@ -0,0 +1,200 @@
+package templates
+
+import "time"
+
+// String values of the template data are inserted into the messages as is, so the caller must escape them for Telegram HTML.
+
+// QueueData describes the monitored queue.
+type QueueData struct {
+	ID               int
+	Name             string
+	Active           bool
+	Enabled          bool
+	TicketValue      string // last called ticket, may be empty
+	TicketsLeft      int
+	TicketsAtOpening int       // tickets left when the queue opened, zero if unknown
+	OpenedAt         time.Time // local time when the queue opened, zero if unknown
+}
+
+// NotificationData is passed to the templates of queue notifications.
+type NotificationData struct {
+	Event     string // e.g. "queue_opened"
+	State     string // state of the monitor after the event, e.g. "ActiveEnabled"
+	Queue     QueueData
+	BurnRate  float64 // tickets taken per minute since the queue opened, zero if unknown
+	LocalTime time.Time
+}
+
+// CommandData is a bot command listed in the menu.
+type CommandData struct {
+	Command     string
+	Description string
+}
+
+// MenuData is passed to the template of the bot menu.
+type MenuData struct {
+	Commands    []CommandData
+	CommandList string // the commands formatted as "/command - description" lines
+}
+
+// StatusData is passed to the template of the /status command of the bot.
+type StatusData struct {
+	Known     bool // false if the queue status was never published or can't be read
+	Queue     QueueData
+	CheckedAt time.Time // local time of the last check
+	UpdatedAt time.Time // local time when the status last changed
+}
+
+// SubscriptionData is passed to the templates of the /subscribe command of the bot.
+type SubscriptionData struct {
+	Events     string // "opening", "low" or "all"
+	QueueList  string // the subscribed queue IDs formatted as "24, 25", empty for all queues
+	QuietHours string // e.g. "22:00-07:00", empty if not set
+	Invalid    string // the argument of the command which can't be understood
+}
+
+// TicketData is passed to the templates of the /myticket command of the bot and of the messages about the tracked ticket.
+type TicketData struct {
+	Ticket       string // the ticket of the user, e.g. "A123"
+	CalledTicket string // the last called ticket, may be empty
+	Positions    int    // how many tickets are left to call before the ticket of the user
+	EtaMinutes   int    // estimated minutes until the ticket is called, zero if unknown
+}
+
+// DailySummaryData is passed to the template of the summary posted to the channel at the end of the day. The times are local.
+type DailySummaryData struct {
+	QueueName        string
+	Date             time.Time
+	Opened           bool      // false if no tickets could be taken during the day
+	OpenedAt         time.Time // when tickets could be taken for the first time
+	TicketsAtOpening int
+	SoldOutAt        time.Time           // when the tickets ran out for the first time, zero if they didn't
+	EnabledMinutes   int                 // how long tickets could be taken, over all openings
+	Reopenings       int                 // how many times tickets could be taken again after they ran out
+	Average          *SummaryAverageData // the same weekday of the previous weeks, nil if the queue didn't open on them
+}
+
+// SummaryAverageData is the average of the days the queue opened on the same weekday in the previous weeks.
+type SummaryAverageData struct {
+	Days             int       // number of the averaged days
+	OpenedAt         time.Time // the average time of the opening, on the summarized date
+	TicketsAtOpening int
+	EnabledMinutes   int
+}
+
+// StatsData is passed to the template of the /stats command of the bot.
+type StatsData struct {
+	Period               int // days in the period
+	Days                 int // days the queue was observed
+	OpenedDays           int
+	OpenedPercent        int // share of the observed days the queue opened
+	SoldOutDays          int
+	MedianSoldOutMinutes int // how long the tickets of an opening usually last
+	Weekdays             []WeekdayStatsData
+}
+
+// WeekdayStatsData describes the queue on a day of the week, in the /stats command of the bot.
+type WeekdayStatsData struct {
+	Weekday          string // lowercase English name, e.g. "monday"
+	Days             int
+	OpenedDays       int
+	OpeningsPercent  int       // share of all the openings of the period which happened on the weekday
+	TypicalOpeningAt time.Time // the median time of the opening, today, zero if the queue never opened on the weekday
+}
+
+// ChartData is passed to the templates of the /chart command of the bot.
+type ChartData struct {
+	QueueName    string
+	Date         time.Time // the charted day
+	ComparedDays int       // how many previous days are drawn behind the charted one
+	Invalid      string    // the argument of the command which can't be understood
+}
+
+// FeedbackData is passed to the template of the feedback forwarded to the admin chat.
+type FeedbackData struct {
+	Feedback string
+}
+
+// samples are the data used to validate the templates at startup, by template name.
+// Every built-in template must have a sample: templates without one can't be overridden.
+func samples(now time.Time) map[string]any {
+	notification := func(event, state string, active, enabled bool) NotificationData {
+		return NotificationData{
+			Event: event,
+			State: state,
+			Queue: QueueData{
+				ID:               24,
+				Name:             "Odbiór karty pobytu",
+				Active:           active,
+				Enabled:          enabled,
+				TicketValue:      "K123",
+				TicketsLeft:      42,
+				TicketsAtOpening: 120,
+				OpenedAt:         now.Add(-time.Hour),
+			},
+			BurnRate:  1.3,
+			LocalTime: now,
+		}
+	}
+
+	return map[string]any{
+		"queue_available":   notification("queue_opened", "ActiveEnabled", true, true),
+		"queue_opened":      notification("queue_opened", "ActiveEnabled", true, true),
+		"tickets_changed":   notification("tickets_changed", "ActiveEnabled", true, true),
+		"tickets_low":       notification("tickets_low", "ActiveEnabled", true, true),
+		"queue_unavailable": notification("queue_unavailable", "ActiveDisabled", true, false),
+		"queue_inactive":    notification("queue_inactive", "Inactive", false, false),
+		"bot_menu":          MenuData{Commands: []CommandData{{Command: "feedback", Description: "feedback"}}, CommandList: "/feedback - feedback"},
+		"bot_status": StatusData{
+			Known:     true,
+			Queue:     notification("tickets_changed", "ActiveEnabled", true, true).Queue,
+			CheckedAt: now,
+			UpdatedAt: now.Add(-time.Minute),
+		},
+		"status_refresh":            nil,
+		"subscription":              SubscriptionData{Events: "low", QueueList: "24, 25", QuietHours: "22:00-07:00"},
+		"subscription_invalid":      SubscriptionData{Invalid: "sometimes"},
+		"unsubscribed":              nil,
+		"bot_error":                 nil,
+		"ticket_tracked":            TicketData{Ticket: "A123"},
+		"ticket_invalid":            TicketData{Ticket: "123A"},
+		"ticket_none":               nil,
+		"ticket_stopped":            nil,
+		"ticket_approaching":        TicketData{Ticket: "A123", CalledTicket: "A115", Positions: 8, EtaMinutes: 12},
+		"ticket_reached":            TicketData{Ticket: "A123", CalledTicket: "A123"},
+		"not_subscribed":            nil,
+		"subscription_private_only": nil,
+		"daily_summary": DailySummaryData{
+			QueueName:        "Odbiór karty pobytu",
+			Date:             now,
+			Opened:           true,
+			OpenedAt:         now.Add(-4 * time.Hour),
+			TicketsAtOpening: 120,
+			SoldOutAt:        now.Add(-2 * time.Hour),
+			EnabledMinutes:   95,
+			Reopenings:       1,
+			Average:          &SummaryAverageData{Days: 4, OpenedAt: now.Add(-4*time.Hour - 10*time.Minute), TicketsAtOpening: 110, EnabledMinutes: 80},
+		},
+		"bot_stats": StatsData{
+			Period:               30,
+			Days:                 22,
+			OpenedDays:           18,
+			OpenedPercent:        82,
+			SoldOutDays:          15,
+			MedianSoldOutMinutes: 47,
+			Weekdays: []WeekdayStatsData{
+				{Weekday: "monday", Days: 4, OpenedDays: 4, OpeningsPercent: 22, TypicalOpeningAt: now},
+				{Weekday: "saturday", Days: 2},
+			},
+		},
+		"stats_period":         30,
+		"bot_chart":            ChartData{QueueName: "Odbiór karty pobytu", Date: now, ComparedDays: 3},
+		"chart_no_data":        ChartData{Date: now},
+		"chart_invalid":        ChartData{Invalid: "31.02"},
+		"feedback_info":        nil,
+		"feedback_prompt":      nil,
+		"feedback_placeholder": nil,
+		"feedback_thank_you":   nil,
+		"feedback_admin":       FeedbackData{Feedback: "Great bot!"},
+	}
+}
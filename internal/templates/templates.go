@@ -0,0 +1,214 @@
+// Package templates renders the notification and bot texts from text/template templates.
+// The built-in templates use the translations of the i18n catalog; operators can override any of them with files from a directory,
+// for all languages ("<name>.tmpl") or for a single one ("<name>.<language>.tmpl").
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/i18n"
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+const templateFileExt = ".tmpl"
+
+//go:embed defaults/*.tmpl
+var defaults embed.FS
+
+// Default renders the built-in templates in Europe/Warsaw time, or in UTC if the time zone database is not available.
+// It's replaced by the templates loaded at startup, see Load.
+var Default = mustLoadDefaults()
+
+// Set holds the templates of every language.
+type Set struct {
+	log       *logger.Logger
+	location  *time.Location
+	builtin   map[string]*template.Template // built-in templates of each language, used if an overridden template fails
+	templates map[string]*template.Template // built-in templates with the overrides, of each language
+}
+
+// Load parses the built-in templates, overrides them with the files of the configured directory and validates all of them
+// by rendering them with sample data, so that a broken template fails the startup instead of a notification.
+func Load(cfg *Config, log *logger.Logger) (*Set, error) {
+	location, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %s: %w", cfg.TimeZone, err)
+	}
+	return load(cfg.Dir, location, log)
+}
+
+func load(dir string, location *time.Location, log *logger.Logger) (*Set, error) {
+	builtinFiles, err := readTemplateFiles(defaults, "defaults")
+	if err != nil {
+		return nil, err
+	}
+	var overrides map[string]map[string]string
+	if dir != "" {
+		if overrides, err = readTemplateFiles(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Set{
+		log:       log,
+		location:  location,
+		builtin:   make(map[string]*template.Template),
+		templates: make(map[string]*template.Template),
+	}
+	for _, lang := range i18n.Default.Languages() {
+		builtin := template.New(lang).Funcs(funcs(lang)).Option("missingkey=error")
+		if err := parseTemplates(builtin, builtinFiles, lang); err != nil {
+			return nil, fmt.Errorf("failed to parse built-in templates: %w", err)
+		}
+
+		templates, err := builtin.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy built-in templates: %w", err)
+		}
+		if err := parseTemplates(templates, overrides, lang); err != nil {
+			return nil, err
+		}
+
+		s.builtin[lang] = builtin
+		s.templates[lang] = templates
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// mustLoadDefaults panics only if the embedded templates are broken: a missing time zone database must not crash the
+// package initialization, the configured time zone is checked by Load at startup.
+func mustLoadDefaults() *Set {
+	location, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		location = time.UTC
+	}
+	s, err := load("", location, logger.NewLogger(&logger.Config{Level: "error"}))
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in templates: %v", err))
+	}
+	return s
+}
+
+// readTemplateFiles reads the template files of the directory: the text of every template name and language,
+// where the empty language stands for all languages.
+func readTemplateFiles(fsys fs.FS, dir string) (map[string]map[string]string, error) {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*"+templateFileExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list template files: %w", err)
+	}
+
+	known := samples(time.Now())
+	files := make(map[string]map[string]string)
+	for _, file := range paths {
+		name, lang, _ := strings.Cut(strings.TrimSuffix(path.Base(file), templateFileExt), ".")
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown template %s in file %s", name, file)
+		}
+		if lang != "" && i18n.Default.Language(lang) != lang {
+			return nil, fmt.Errorf("unsupported language %s of template file %s", lang, file)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file %s: %w", file, err)
+		}
+		if files[name] == nil {
+			files[name] = make(map[string]string)
+		}
+		// the newline which ends the file is not a part of the text
+		files[name][lang] = strings.TrimSuffix(string(data), "\n")
+	}
+	return files, nil
+}
+
+// parseTemplates adds the templates of the language to the set, preferring the files of the language to the files for all languages.
+func parseTemplates(templates *template.Template, files map[string]map[string]string, lang string) error {
+	for name, texts := range files {
+		text, ok := texts[lang]
+		if !ok {
+			if text, ok = texts[""]; !ok {
+				continue
+			}
+		}
+		if _, err := templates.New(name).Parse(text); err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Set) validate() error {
+	for name, data := range samples(time.Now().In(s.location)) {
+		for lang, templates := range s.templates {
+			text, err := execute(templates, name, data)
+			if err != nil {
+				return fmt.Errorf("invalid template %s of %s: %w", name, lang, err)
+			}
+			if strings.TrimSpace(text) == "" {
+				return fmt.Errorf("invalid template %s of %s: the text is empty", name, lang)
+			}
+		}
+	}
+	return nil
+}
+
+// Execute renders the template in the language, or in the fallback language if the language is not supported.
+// If an overridden template fails, the error is logged and the built-in template is rendered instead.
+func (s *Set) Execute(name, lang string, data any) string {
+	lang = i18n.Default.Language(lang)
+	text, err := execute(s.templates[lang], name, data)
+	if err == nil {
+		return text
+	}
+
+	s.log.Error("Failed to render template, using the built-in one", err, "template", name, "language", lang)
+	text, err = execute(s.builtin[lang], name, data)
+	if err != nil {
+		s.log.Error("Failed to render built-in template", err, "template", name, "language", lang)
+	}
+	return text
+}
+
+// LocalTime converts the time to the time zone of the templates.
+func (s *Set) LocalTime(t time.Time) time.Time {
+	return t.In(s.location)
+}
+
+// Location returns the time zone of the templates, which is the local time zone of the messages.
+func (s *Set) Location() *time.Location {
+	return s.location
+}
+
+func execute(templates *template.Template, name string, data any) (string, error) {
+	var sb strings.Builder
+	if err := templates.ExecuteTemplate(&sb, name, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// funcs are the functions available in the templates of the language.
+func funcs(lang string) template.FuncMap {
+	return template.FuncMap{
+		// the translated text of the i18n catalog, e.g. {{t "queue.available" .Queue.Name}}
+		"t": func(key string, args ...any) string {
+			return fmt.Sprintf(i18n.Default.Message(lang, key), args...)
+		},
+		// the translated plural form for the count, e.g. {{plural "queue.tickets_left" .Queue.TicketsLeft .Queue.TicketsLeft}}
+		"plural": func(key string, n int, args ...any) string {
+			return fmt.Sprintf(i18n.Default.PluralMessage(lang, key, n), args...)
+		},
+		"lang": func() string { return lang },
+	}
+}
@@ -0,0 +1,130 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UladzK/duw-queue-monitor/internal/logger"
+)
+
+func loadTemplates(t *testing.T, files map[string]string) (*Set, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatalf("Failed to write template file: %v", err)
+		}
+	}
+	return Load(&Config{Dir: dir, TimeZone: "Europe/Warsaw"}, logger.NewLogger(&logger.Config{Level: "error"}))
+}
+
+func testNotificationData() NotificationData {
+	return NotificationData{
+		Event:     "queue_opened",
+		State:     "ActiveEnabled",
+		Queue:     QueueData{ID: 24, Name: "Odbiór karty", Active: true, Enabled: true, TicketValue: "K80", TicketsLeft: 3},
+		BurnRate:  2.5,
+		LocalTime: time.Date(2025, 3, 3, 9, 15, 0, 0, time.UTC),
+	}
+}
+
+func TestExecute_WithBuiltInTemplates_RendersTranslatedTexts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lang     string
+		expected string
+	}{
+		{"queue_opened", "pl", "🔔 Kolejka <b>Odbiór karty</b> jest teraz dostępna!\n🎟️ Ostatni przywołany bilet: <b>K80</b>\n🧾 Pozostały <b>3</b> bilety"},
+		{"tickets_changed", "en", "🔔 Queue <b>Odbiór karty</b> is now available!\n🎟️ Last called ticket: <b>K80</b>\n🧾 <b>3</b> tickets left"},
+		{"queue_inactive", "uk", "🌙 Черга <b>Odbiór karty</b> неактивна — ймовірно, робочий день DUW закінчився."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := Default.Execute(tc.name, tc.lang, testNotificationData())
+
+			// Assert
+			if actual != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExecute_WithOverriddenTemplates_PrefersLanguageSpecificFile(t *testing.T) {
+	// Arrange
+	sut, err := loadTemplates(t, map[string]string{
+		"queue_opened.tmpl":    "🚀 {{.Queue.Name}}: {{plural \"queue.tickets_left\" .Queue.TicketsLeft .Queue.TicketsLeft}} ({{printf \"%.1f\" .BurnRate}}/min, {{.LocalTime.Format \"15:04\"}}, {{.State}})\n",
+		"queue_opened.en.tmpl": "🚀 {{.Queue.Name}} is open!\n",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Act
+	polish := sut.Execute("queue_opened", "pl", testNotificationData())
+	english := sut.Execute("queue_opened", "en", testNotificationData())
+	inactive := sut.Execute("queue_inactive", "pl", testNotificationData())
+
+	// Assert
+	if expected := "🚀 Odbiór karty: 🧾 Pozostały <b>3</b> bilety (2.5/min, 09:15, ActiveEnabled)"; polish != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, polish)
+	}
+	if expected := "🚀 Odbiór karty is open!"; english != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, english)
+	}
+	if !strings.HasPrefix(inactive, "🌙 Kolejka") {
+		t.Errorf("Expected the built-in template for a template which is not overridden, got: %s", inactive)
+	}
+}
+
+func TestExecute_WhenOverriddenTemplateFails_RendersBuiltInTemplate(t *testing.T) {
+	// Arrange
+	sut, err := loadTemplates(t, map[string]string{
+		"queue_opened.tmpl": "{{if gt .Queue.TicketsLeft 100}}{{index .Queue.Name 1000}}{{end}}{{.Queue.Name}}",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	data := testNotificationData()
+	data.Queue.TicketsLeft = 200
+
+	// Act
+	actual := sut.Execute("queue_opened", "pl", data)
+
+	// Assert
+	if !strings.HasPrefix(actual, "🔔 Kolejka <b>Odbiór karty</b>") {
+		t.Errorf("Expected the built-in template, got: %s", actual)
+	}
+}
+
+func TestLoad_WhenTemplatesAreInvalid_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         map[string]string
+		expectedError string
+	}{
+		{"unknown template", map[string]string{"queue_closed.tmpl": "closed"}, "unknown template queue_closed"},
+		{"unsupported language", map[string]string{"queue_opened.de.tmpl": "offen"}, "unsupported language de"},
+		{"syntax error", map[string]string{"queue_opened.tmpl": "{{.Queue.Name"}, "failed to parse template queue_opened"},
+		{"unknown field", map[string]string{"queue_opened.tmpl": "{{.Queue.Title}}"}, "invalid template queue_opened"},
+		{"wrong data", map[string]string{"feedback_admin.tmpl": "{{.Queue.Name}}"}, "invalid template feedback_admin"},
+		{"empty text", map[string]string{"queue_inactive.uk.tmpl": "{{if false}}x{{end}}\n"}, "the text is empty"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := loadTemplates(t, tc.files)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}